@@ -18,6 +18,7 @@ import (
 	fmt "fmt"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
 	"time"
 )
 
@@ -26,14 +27,52 @@ const (
 	JobPhaseRunning   = "Running"
 	JobPhaseCompleted = "Completed"
 	JobPhaseFailed    = "Failed"
+	JobPhaseCancelled = "Cancelled"
 )
 
 const (
 	JobConditionTypeEnded = "Ended"
 )
 
+const (
+	// BackupConditionTypeVerified is the result of the latest verification of the backup data
+	BackupConditionTypeVerified = "Verified"
+)
+
+// labels and annotations of the backup catalog, the labels are set on every Backup so that the
+// backups can be queried by label selectors, e.g. kubectl get backup -l backup.matrixorigin.io/source-cluster=mo
+const (
+	// BackupSourceClusterLabelKey is the name of the MatrixOneCluster or CNSet that produced the backup
+	BackupSourceClusterLabelKey = "backup.matrixorigin.io/source-cluster"
+	// BackupSourceNamespaceLabelKey is the namespace of the source that produced the backup
+	BackupSourceNamespaceLabelKey = "backup.matrixorigin.io/source-namespace"
+	// BackupMOVersionLabelKey is the MO version of the source when the backup was taken
+	BackupMOVersionLabelKey = "backup.matrixorigin.io/mo-version"
+
+	// BackupRestoreNamespacesAnnoKey restricts the namespaces that can restore from the Backup, the value
+	// is a comma separated namespace list and "*" allows all namespaces. A Backup without this annotation
+	// can be restored to any namespace
+	BackupRestoreNamespacesAnnoKey = "backup.matrixorigin.io/restore-namespaces"
+
+	// BackupSourceRefField is the field index of the sourceRef of Backups in the operator cache,
+	// list the backups of a source by client.MatchingFields{BackupSourceRefField: sourceRef}
+	BackupSourceRefField = "meta.sourceRef"
+)
+
 const (
 	defaultTTL = 1 * time.Hour
+	// maxTTL is the max ttl of a backup or restore job after completed or failed
+	maxTTL = 30 * 24 * time.Hour
+)
+
+// BackupMode is the mode of a backup
+type BackupMode string
+
+const (
+	// BackupModeFull takes a full backup of the source
+	BackupModeFull BackupMode = "Full"
+	// BackupModeIncremental only backs up the data changed since the parent backup
+	BackupModeIncremental BackupMode = "Incremental"
 )
 
 // BackupJobSpec specifies the backup job
@@ -44,8 +83,41 @@ type BackupJobSpec struct {
 	// source the backup source
 	Source BackupSource `json:"source"`
 
+	// target is the location to store the backup, the data of each BackupJob is written to
+	// the <namespace>/<name>-<uid> directory under the target
 	Target SharedStorageProvider `json:"target"`
 
+	// mode is the backup mode, defaults to Full
+	// +kubebuilder:validation:Enum=Full;Incremental
+	// +optional
+	Mode BackupMode `json:"mode,omitempty"`
+
+	// optional, parentBackup is the name of the Backup that an incremental backup is based on.
+	// If not set, the latest backup of the source is used as the parent, and a full backup is
+	// taken when the source has no backup yet
+	ParentBackup string `json:"parentBackup,omitempty"`
+
+	// optional, verify the backup data by a BackupVerification after the backup is completed
+	// +optional
+	Verify bool `json:"verify,omitempty"`
+
+	// optional, restoreNamespaces restricts the namespaces that can restore from the backup, the backup
+	// can be restored to any namespace if not set. Recorded as the restore-namespaces annotation of the Backup
+	// +optional
+	RestoreNamespaces []string `json:"restoreNamespaces,omitempty"`
+
+	// optional, suspend cancels the job if it has not ended yet. The running backup is stopped
+	// and the job ends in the Cancelled phase, the data of the backup that has completed before
+	// the cancellation is deleted. A cancelled job cannot be resumed
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// optional, backoffLimit is the number of retries before the job is marked as failed, the
+	// underlying job is re-created when it fails. Defaults to 0, i.e. no retry
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+
 	Overlay *Overlay `json:"overlay,omitempty"`
 }
 
@@ -77,6 +149,14 @@ type BackupJobStatus struct {
 	Phase string `json:"phase,omitempty"`
 
 	Backup string `json:"backup,omitempty"`
+
+	// parentBackup is the Backup that the incremental backup is based on
+	// +optional
+	ParentBackup string `json:"parentBackup,omitempty"`
+
+	// retries is the number of times that the underlying job has been re-created
+	// +optional
+	Retries int32 `json:"retries,omitempty"`
 }
 
 // A BackupJob is a resource that represents an MO backup job
@@ -97,6 +177,10 @@ type BackupJob struct {
 	Status BackupJobStatus `json:"status,omitempty"`
 }
 
+func (r *BackupJob) IsIncremental() bool {
+	return r.Spec.Mode == BackupModeIncremental
+}
+
 func (r *BackupJob) GetTTL() time.Duration {
 	if r.Spec.TTL != nil {
 		return r.Spec.TTL.Duration
@@ -106,7 +190,8 @@ func (r *BackupJob) GetTTL() time.Duration {
 
 // BackupMeta specifies the backup
 type BackupMeta struct {
-	// location is the data location of the backup
+	// location is the data location of the backup, i.e. the <namespace>/<name>-<uid> directory of the BackupJob
+	// under its target. Backups recorded by earlier versions of the operator point to the target itself
 	Location SharedStorageProvider `json:"location"`
 
 	// id uniquely identifies the backup
@@ -115,56 +200,208 @@ type BackupMeta struct {
 	// size is the backup data size
 	Size *resource.Quantity `json:"size,omitempty"`
 
-	// atTime is the backup start time
+	// atTime is the consistent point in time of the backup data
 	AtTime metav1.Time `json:"atTime"`
 
+	// backupTS is the consistent timestamp of the backup data reported by mo_br
+	// +optional
+	BackupTS string `json:"backupTS,omitempty"`
+
+	// startTime is the time the backup is started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
 	// completeTime the backup complete time
 	CompleteTime metav1.Time `json:"completeTime"`
 
+	// duration is the time taken by the backup
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// throughput is the average bytes backed up per second
+	// +optional
+	Throughput *resource.Quantity `json:"throughput,omitempty"`
+
 	// clusterRef is the reference to the cluster that produce this backup
 	SourceRef string `json:"sourceRef"`
 
+	// moVersion is the MO version of the source when the backup was taken
+	// +optional
+	MOVersion string `json:"moVersion,omitempty"`
+
+	// mode is the mode of the backup
+	// +optional
+	Mode BackupMode `json:"mode,omitempty"`
+
+	// chain is the Backups that an incremental backup depends on, ordered from the
+	// full base backup to the direct parent. Empty for a full backup
+	// +optional
+	Chain []string `json:"chain,omitempty"`
+
 	Raw string `json:"raw"`
 }
 
+// GetParent returns the direct parent of an incremental backup
+func (m *BackupMeta) GetParent() string {
+	if len(m.Chain) == 0 {
+		return ""
+	}
+	return m.Chain[len(m.Chain)-1]
+}
+
+// DependsOn returns whether the backup depends on the given Backup
+func (m *BackupMeta) DependsOn(name string) bool {
+	for _, n := range m.Chain {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RestorableTo returns whether the backup can be restored to the namespace
+func (r *Backup) RestorableTo(ns string) bool {
+	allowed, ok := r.Annotations[BackupRestoreNamespacesAnnoKey]
+	if !ok {
+		return true
+	}
+	for _, n := range strings.Split(allowed, ",") {
+		n = strings.TrimSpace(n)
+		if n == "*" || n == ns {
+			return true
+		}
+	}
+	return false
+}
+
+// BackupRetentionPolicy specifies which backups should be retained, a backup is retained
+// as long as any of the rules keeps it, and is expired otherwise.
+// The backups that a retained incremental backup depends on are always retained.
+// An empty policy retains all backups.
+type BackupRetentionPolicy struct {
+	// keepLast keeps the last N backups
+	// +optional
+	KeepLast *int32 `json:"keepLast,omitempty"`
+
+	// keepFor keeps the backups taken within the duration
+	// +optional
+	KeepFor *metav1.Duration `json:"keepFor,omitempty"`
+
+	// keepDaily keeps the latest backup of each day for the last N days that have backups
+	// +optional
+	KeepDaily *int32 `json:"keepDaily,omitempty"`
+
+	// keepWeekly keeps the latest backup of each week for the last N weeks that have backups
+	// +optional
+	KeepWeekly *int32 `json:"keepWeekly,omitempty"`
+
+	// keepMonthly keeps the latest backup of each month for the last N months that have backups
+	// +optional
+	KeepMonthly *int32 `json:"keepMonthly,omitempty"`
+}
+
+func (p *BackupRetentionPolicy) IsEmpty() bool {
+	return p.KeepLast == nil && p.KeepFor == nil && p.KeepDaily == nil && p.KeepWeekly == nil && p.KeepMonthly == nil
+}
+
 // A Backup is a resource that represents an MO physical backup
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope="Cluster"
 // +kubebuilder:printcolumn:name="ID",type="string",JSONPath=".meta.id"
 // +kubebuilder:printcolumn:name="At",type="string",format="date-time",JSONPath=".meta.atTime"
 // +kubebuilder:printcolumn:name="Source",type="string",JSONPath=".meta.sourceRef"
+// +kubebuilder:printcolumn:name="Mode",type="string",JSONPath=".meta.mode"
+// +kubebuilder:printcolumn:name="Size",type="string",JSONPath=".meta.size"
+// +kubebuilder:printcolumn:name="Verified",type="string",JSONPath=".status.conditions[?(@.type==\"Verified\")].status"
+// +kubebuilder:subresource:status
 type Backup struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
 	// Meta is the backupMeta
 	Meta BackupMeta `json:"meta"`
+
+	// Status is the backupStatus
+	Status BackupStatus `json:"status,omitempty"`
+}
+
+type BackupStatus struct {
+	ConditionalStatus `json:",inline"`
+
+	// deleteRetries is the number of times that the job to delete the backup data has been re-created
+	// +optional
+	DeleteRetries int32 `json:"deleteRetries,omitempty"`
+}
+
+func (r *Backup) SetCondition(condition metav1.Condition) {
+	r.Status.SetCondition(condition)
+}
+
+func (r *Backup) GetConditions() []metav1.Condition {
+	return r.Status.GetConditions()
 }
 
 type RestoreJobSpec struct {
 	// ttl defines the time to live of the backup job after completed or failed
 	TTL *metav1.Duration `json:"ttl,omitempty"`
 
-	// backupName specifies the backup to restore, must be set UNLESS externalSource is set
+	// backupName specifies the backup to restore, must be set UNLESS externalSource or latestBackupBefore is set
 	BackupName string `json:"backupName,omitempty"`
 
 	// optional, restore from an external source, mutual exclusive with backupName
 	ExternalSource *SharedStorageProvider `json:"externalSource,omitempty"`
 
+	// optional, latestBackupBefore restores the latest full or incremental backup of sourceRef that is
+	// consistent at or before the given time, along with the backups it depends on. Note that this is not
+	// a point-in-time restore: the data is restored to the time of the chosen backup, which is reported
+	// in status.restoredTime, rather than the given time. Mutual exclusive with backupName and externalSource
+	LatestBackupBefore *metav1.Time `json:"latestBackupBefore,omitempty"`
+
+	// sourceRef is the source whose backups are looked up by latestBackupBefore, in
+	// <kind>/<namespace>/<name> format, e.g. matrixonecluster/default/mo. Required when latestBackupBefore is set
+	// +optional
+	SourceRef string `json:"sourceRef,omitempty"`
+
 	// target specifies the restore location
 	Target SharedStorageProvider `json:"target"`
+
+	// optional, suspend cancels the job if it has not ended yet. The running command is stopped
+	// and the job ends in the Cancelled phase, a cancelled job cannot be resumed
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// optional, backoffLimit is the number of retries before the job is marked as failed, the
+	// underlying job is re-created when it fails. Defaults to 0, i.e. no retry
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+
+	Overlay *Overlay `json:"overlay,omitempty"`
 }
 
 type RestoreJobStatus struct {
 	ConditionalStatus `json:",inline"`
 
 	Phase string `json:"phase"`
+
+	// backup is the backup that is actually restored
+	// +optional
+	Backup string `json:"backup,omitempty"`
+
+	// restoredTime is the consistent point in time that the data is restored to
+	// +optional
+	RestoredTime *metav1.Time `json:"restoredTime,omitempty"`
+
+	// retries is the number of times that the underlying job has been re-created
+	// +optional
+	Retries int32 `json:"retries,omitempty"`
 }
 
 // A RestoreJob is a resource that represents an MO restore job
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope="Namespaced"
 // +kubebuilder:printcolumn:name="phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Backup",type="string",JSONPath=".status.backup"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:subresource:status
 type RestoreJob struct {
@@ -177,6 +414,7 @@ type RestoreJob struct {
 	// Spec is the restoreJobStatus
 	Status RestoreJobStatus `json:"status,omitempty"`
 
+	// Deprecated: use spec.overlay instead
 	Overlay *Overlay `json:"overlay,omitempty"`
 }
 
@@ -188,6 +426,9 @@ func (r *RestoreJob) GetTTL() time.Duration {
 }
 
 func (r *RestoreJob) GetOverlay() *Overlay {
+	if r.Spec.Overlay != nil {
+		return r.Spec.Overlay
+	}
 	return r.Overlay
 }
 
@@ -227,6 +468,189 @@ func (r *BackupJob) SetPhase(phase string) {
 	r.Status.Phase = phase
 }
 
+type BackupVerificationSpec struct {
+	// ttl defines the time to live of the verification after completed or failed
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// backupName specifies the backup to verify, the credential of the backup location
+	// must be available in the namespace of the verification
+	BackupName string `json:"backupName"`
+
+	Overlay *Overlay `json:"overlay,omitempty"`
+}
+
+type BackupVerificationStatus struct {
+	ConditionalStatus `json:",inline"`
+
+	Phase string `json:"phase,omitempty"`
+}
+
+// A BackupVerification is a resource that verifies the integrity of the data of a Backup,
+// the result is recorded as the Verified condition of the Backup
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope="Namespaced"
+// +kubebuilder:printcolumn:name="Backup",type="string",JSONPath=".spec.backupName"
+// +kubebuilder:printcolumn:name="phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+type BackupVerification struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the backupVerificationSpec
+	Spec BackupVerificationSpec `json:"spec"`
+
+	// Status is the backupVerificationStatus
+	Status BackupVerificationStatus `json:"status,omitempty"`
+}
+
+func (r *BackupVerification) GetTTL() time.Duration {
+	if r.Spec.TTL != nil {
+		return r.Spec.TTL.Duration
+	}
+	return defaultTTL
+}
+
+func (r *BackupVerification) GetOverlay() *Overlay {
+	return r.Spec.Overlay
+}
+
+func (r *BackupVerification) SetCondition(condition metav1.Condition) {
+	r.Status.SetCondition(condition)
+}
+
+func (r *BackupVerification) GetConditions() []metav1.Condition {
+	return r.Status.GetConditions()
+}
+
+func (r *BackupVerification) GetPhase() string {
+	return r.Status.Phase
+}
+
+func (r *BackupVerification) SetPhase(phase string) {
+	r.Status.Phase = phase
+}
+
+type ConcurrencyPolicy string
+
+const (
+	// ConcurrencyPolicyAllow allows BackupJobs of a schedule to run concurrently
+	ConcurrencyPolicyAllow ConcurrencyPolicy = "Allow"
+	// ConcurrencyPolicyForbid skips the next run if the previous BackupJob hasn't finished yet
+	ConcurrencyPolicyForbid ConcurrencyPolicy = "Forbid"
+	// ConcurrencyPolicyReplace cancels the running BackupJob and replaces it with a new one
+	ConcurrencyPolicyReplace ConcurrencyPolicy = "Replace"
+)
+
+const (
+	defaultSuccessfulJobsHistoryLimit = 3
+	defaultFailedJobsHistoryLimit     = 1
+)
+
+// BackupScheduleSpec specifies the backup schedule
+type BackupScheduleSpec struct {
+	// schedule is the cron expression of the schedule, e.g. "0 2 * * *"
+	// +required
+	Schedule string `json:"schedule"`
+
+	// concurrencyPolicy specifies how to treat concurrent executions of a BackupJob, default to Forbid
+	// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+	// +optional
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
+
+	// suspend tells the controller to suspend subsequent executions, it does not apply
+	// to already started executions
+	// +optional
+	Suspend *bool `json:"suspend,omitempty"`
+
+	// successfulJobsHistoryLimit is the number of completed BackupJobs to retain, default to 3
+	// +optional
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+
+	// failedJobsHistoryLimit is the number of failed BackupJobs to retain, default to 1
+	// +optional
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+
+	// retention is the retention policy of the backups created by this schedule,
+	// all backups are retained if not set
+	// +optional
+	Retention *BackupRetentionPolicy `json:"retention,omitempty"`
+
+	// backupTemplate is the template of the BackupJob created by this schedule,
+	// the ttl of the template is ignored since the history limits take over the garbage collection
+	// +required
+	BackupTemplate BackupJobSpec `json:"backupTemplate"`
+}
+
+type BackupScheduleStatus struct {
+	ConditionalStatus `json:",inline"`
+
+	// lastScheduleTime is the last time a BackupJob was successfully scheduled
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// lastSuccessfulTime is the last time a BackupJob of this schedule completed successfully
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+
+	// nextScheduleTime is the next time a BackupJob will be scheduled
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+
+	// active is the list of the names of the running BackupJobs
+	Active []string `json:"active,omitempty"`
+}
+
+// A BackupSchedule is a resource that creates BackupJobs on a cron schedule
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope="Namespaced"
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule"
+// +kubebuilder:printcolumn:name="Suspend",type="boolean",JSONPath=".spec.suspend"
+// +kubebuilder:printcolumn:name="Last",type="date",JSONPath=".status.lastScheduleTime"
+// +kubebuilder:printcolumn:name="Next",type="string",format="date-time",JSONPath=".status.nextScheduleTime"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+type BackupSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the backupScheduleSpec
+	Spec BackupScheduleSpec `json:"spec"`
+
+	// Status is the backupScheduleStatus
+	Status BackupScheduleStatus `json:"status,omitempty"`
+}
+
+func (r *BackupSchedule) IsSuspended() bool {
+	return r.Spec.Suspend != nil && *r.Spec.Suspend
+}
+
+func (r *BackupSchedule) GetConcurrencyPolicy() ConcurrencyPolicy {
+	if r.Spec.ConcurrencyPolicy == "" {
+		return ConcurrencyPolicyForbid
+	}
+	return r.Spec.ConcurrencyPolicy
+}
+
+func (r *BackupSchedule) GetSuccessfulJobsHistoryLimit() int32 {
+	if r.Spec.SuccessfulJobsHistoryLimit != nil {
+		return *r.Spec.SuccessfulJobsHistoryLimit
+	}
+	return defaultSuccessfulJobsHistoryLimit
+}
+
+func (r *BackupSchedule) GetFailedJobsHistoryLimit() int32 {
+	if r.Spec.FailedJobsHistoryLimit != nil {
+		return *r.Spec.FailedJobsHistoryLimit
+	}
+	return defaultFailedJobsHistoryLimit
+}
+
+func (r *BackupSchedule) SetCondition(condition metav1.Condition) {
+	r.Status.SetCondition(condition)
+}
+
+func (r *BackupSchedule) GetConditions() []metav1.Condition {
+	return r.Status.GetConditions()
+}
+
 // BackupJobList contains a list of BackupJob
 // +kubebuilder:object:root=true
 type BackupJobList struct {
@@ -251,8 +675,26 @@ type RestoreJobList struct {
 	Items           []RestoreJob `json:"items"`
 }
 
+// BackupScheduleList contains a list of BackupSchedule
+// +kubebuilder:object:root=true
+type BackupScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BackupSchedule `json:"items"`
+}
+
+// BackupVerificationList contains a list of BackupVerification
+// +kubebuilder:object:root=true
+type BackupVerificationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BackupVerification `json:"items"`
+}
+
 func init() {
 	SchemeBuilder.Register(&BackupJob{}, &BackupJobList{})
+	SchemeBuilder.Register(&BackupSchedule{}, &BackupScheduleList{})
 	SchemeBuilder.Register(&Backup{}, &BackupList{})
 	SchemeBuilder.Register(&RestoreJob{}, &RestoreJobList{})
+	SchemeBuilder.Register(&BackupVerification{}, &BackupVerificationList{})
 }
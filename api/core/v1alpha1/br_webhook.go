@@ -0,0 +1,374 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func (r *BackupJob) setupWebhookWithManager(mgr ctrl.Manager) error {
+	kClient = mgr.GetClient()
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-core-matrixorigin-io-v1alpha1-backupjob,mutating=true,failurePolicy=fail,sideEffects=None,groups=core.matrixorigin.io,resources=backupjobs,verbs=create;update,versions=v1alpha1,name=mbackupjob.kb.io,admissionReviewVersions={v1,v1beta1}
+
+var _ webhook.Defaulter = &BackupJob{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *BackupJob) Default() {
+	if r.Spec.TTL == nil {
+		r.Spec.TTL = &metav1.Duration{Duration: defaultTTL}
+	}
+	if r.Spec.Mode == "" {
+		r.Spec.Mode = BackupModeFull
+	}
+}
+
+// +kubebuilder:webhook:path=/validate-core-matrixorigin-io-v1alpha1-backupjob,mutating=false,failurePolicy=fail,sideEffects=None,groups=core.matrixorigin.io,resources=backupjobs,verbs=create;update,versions=v1alpha1,name=vbackupjob.kb.io,admissionReviewVersions={v1,v1beta1}
+
+var _ webhook.Validator = &BackupJob{}
+
+func (r *BackupJob) ValidateCreate() (admission.Warnings, error) {
+	errs := r.Spec.validate(field.NewPath("spec"))
+	if r.Spec.ParentBackup != "" {
+		_, bErrs := getBackup(r.Spec.ParentBackup, field.NewPath("spec").Child("parentBackup"))
+		errs = append(errs, bErrs...)
+	}
+	return nil, invalidOrNil(errs, r)
+}
+
+func (r *BackupJob) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	return nil, invalidOrNil(r.Spec.validate(field.NewPath("spec")), r)
+}
+
+func (r *BackupJob) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *BackupJobSpec) validate(parent *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateBRTTL(r.TTL, parent.Child("ttl"))...)
+	errs = append(errs, validateBackoffLimit(r.BackoffLimit, parent.Child("backoffLimit"))...)
+	errs = append(errs, validateBackupSource(&r.Source, parent.Child("source"))...)
+	errs = append(errs, validateBRStorage(&r.Target, parent.Child("target"))...)
+	for i, ns := range r.RestoreNamespaces {
+		if ns == "*" {
+			continue
+		}
+		for _, msg := range validation.IsDNS1123Label(ns) {
+			errs = append(errs, field.Invalid(parent.Child("restoreNamespaces").Index(i), ns, msg))
+		}
+	}
+	if r.ParentBackup != "" && r.Mode != BackupModeIncremental {
+		errs = append(errs, field.Forbidden(parent.Child("parentBackup"), "parentBackup can only be set in Incremental mode"))
+	}
+	return errs
+}
+
+func (r *BackupSchedule) setupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-core-matrixorigin-io-v1alpha1-backupschedule,mutating=false,failurePolicy=fail,sideEffects=None,groups=core.matrixorigin.io,resources=backupschedules,verbs=create;update,versions=v1alpha1,name=vbackupschedule.kb.io,admissionReviewVersions={v1,v1beta1}
+
+var _ webhook.Validator = &BackupSchedule{}
+
+func (r *BackupSchedule) ValidateCreate() (admission.Warnings, error) {
+	return nil, invalidOrNil(r.Spec.validate(field.NewPath("spec")), r)
+}
+
+func (r *BackupSchedule) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	return nil, invalidOrNil(r.Spec.validate(field.NewPath("spec")), r)
+}
+
+func (r *BackupSchedule) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *BackupScheduleSpec) validate(parent *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	// keep in line with the parser of the backup schedule controller
+	if _, err := cron.ParseStandard(r.Schedule); err != nil {
+		errs = append(errs, field.Invalid(parent.Child("schedule"), r.Schedule, err.Error()))
+	}
+	switch r.ConcurrencyPolicy {
+	case "", ConcurrencyPolicyAllow, ConcurrencyPolicyForbid, ConcurrencyPolicyReplace:
+	default:
+		errs = append(errs, field.NotSupported(parent.Child("concurrencyPolicy"), r.ConcurrencyPolicy,
+			[]string{string(ConcurrencyPolicyAllow), string(ConcurrencyPolicyForbid), string(ConcurrencyPolicyReplace)}))
+	}
+	limits := []struct {
+		name  string
+		value *int32
+	}{
+		{"successfulJobsHistoryLimit", r.SuccessfulJobsHistoryLimit},
+		{"failedJobsHistoryLimit", r.FailedJobsHistoryLimit},
+	}
+	for _, l := range limits {
+		if l.value != nil && *l.value < 0 {
+			errs = append(errs, field.Invalid(parent.Child(l.name), *l.value, l.name+" must not be negative"))
+		}
+	}
+	errs = append(errs, validateRetention(r.Retention, parent.Child("retention"))...)
+	errs = append(errs, r.BackupTemplate.validate(parent.Child("backupTemplate"))...)
+	return errs
+}
+
+func validateRetention(p *BackupRetentionPolicy, parent *field.Path) field.ErrorList {
+	if p == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	if p.KeepFor != nil && p.KeepFor.Duration < 0 {
+		errs = append(errs, field.Invalid(parent.Child("keepFor"), p.KeepFor.Duration.String(), "keepFor must not be negative"))
+	}
+	keeps := []struct {
+		name  string
+		value *int32
+	}{
+		{"keepLast", p.KeepLast},
+		{"keepDaily", p.KeepDaily},
+		{"keepWeekly", p.KeepWeekly},
+		{"keepMonthly", p.KeepMonthly},
+	}
+	for _, k := range keeps {
+		if k.value != nil && *k.value < 0 {
+			errs = append(errs, field.Invalid(parent.Child(k.name), *k.value, k.name+" must not be negative"))
+		}
+	}
+	return errs
+}
+
+func (r *RestoreJob) setupWebhookWithManager(mgr ctrl.Manager) error {
+	kClient = mgr.GetClient()
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-core-matrixorigin-io-v1alpha1-restorejob,mutating=true,failurePolicy=fail,sideEffects=None,groups=core.matrixorigin.io,resources=restorejobs,verbs=create;update,versions=v1alpha1,name=mrestorejob.kb.io,admissionReviewVersions={v1,v1beta1}
+
+var _ webhook.Defaulter = &RestoreJob{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *RestoreJob) Default() {
+	if r.Spec.TTL == nil {
+		r.Spec.TTL = &metav1.Duration{Duration: defaultTTL}
+	}
+	// the overlay was a top-level field, move it under spec to be consistent with other jobs
+	if r.Overlay != nil && r.Spec.Overlay == nil {
+		r.Spec.Overlay = r.Overlay
+		r.Overlay = nil
+	}
+}
+
+// +kubebuilder:webhook:path=/validate-core-matrixorigin-io-v1alpha1-restorejob,mutating=false,failurePolicy=fail,sideEffects=None,groups=core.matrixorigin.io,resources=restorejobs,verbs=create;update,versions=v1alpha1,name=vrestorejob.kb.io,admissionReviewVersions={v1,v1beta1}
+
+var _ webhook.Validator = &RestoreJob{}
+
+func (r *RestoreJob) ValidateCreate() (admission.Warnings, error) {
+	errs := r.validate()
+	if r.Spec.BackupName != "" {
+		errs = append(errs, validateRestoreFrom(r.Spec.BackupName, r.Namespace, field.NewPath("spec").Child("backupName"))...)
+	}
+	return nil, invalidOrNil(errs, r)
+}
+
+func (r *RestoreJob) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	return nil, invalidOrNil(r.validate(), r)
+}
+
+func (r *RestoreJob) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *RestoreJob) validate() field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+	if r.Overlay != nil && r.Spec.Overlay != nil {
+		errs = append(errs, field.Forbidden(field.NewPath("overlay"), "overlay is deprecated and must not be set along with spec.overlay"))
+	}
+	errs = append(errs, validateBRTTL(r.Spec.TTL, specPath.Child("ttl"))...)
+	errs = append(errs, validateBackoffLimit(r.Spec.BackoffLimit, specPath.Child("backoffLimit"))...)
+	var sources int
+	if r.Spec.BackupName != "" {
+		sources++
+	}
+	if r.Spec.ExternalSource != nil {
+		sources++
+		errs = append(errs, field.Forbidden(specPath.Child("externalSource"), "restoring from an external source is not supported yet"))
+	}
+	if r.Spec.LatestBackupBefore != nil {
+		sources++
+		if r.Spec.SourceRef == "" {
+			errs = append(errs, field.Required(specPath.Child("sourceRef"), "sourceRef must be set when latestBackupBefore is set"))
+		} else if len(strings.Split(r.Spec.SourceRef, "/")) != 3 {
+			errs = append(errs, field.Invalid(specPath.Child("sourceRef"), r.Spec.SourceRef, "sourceRef must be in <kind>/<namespace>/<name> format"))
+		}
+	}
+	switch {
+	case sources == 0:
+		errs = append(errs, field.Required(specPath, "one of backupName, externalSource or latestBackupBefore must be set"))
+	case sources > 1:
+		errs = append(errs, field.Invalid(specPath, nil, "backupName, externalSource and latestBackupBefore are mutual exclusive"))
+	}
+	errs = append(errs, validateBRStorage(&r.Spec.Target, specPath.Child("target"))...)
+	return errs
+}
+
+func (r *Backup) setupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-core-matrixorigin-io-v1alpha1-backup,mutating=false,failurePolicy=fail,sideEffects=None,groups=core.matrixorigin.io,resources=backups,verbs=create;update,versions=v1alpha1,name=vbackup.kb.io,admissionReviewVersions={v1,v1beta1}
+
+var _ webhook.Validator = &Backup{}
+
+func (r *Backup) ValidateCreate() (admission.Warnings, error) {
+	var errs field.ErrorList
+	metaPath := field.NewPath("meta")
+	if r.Meta.ID == "" {
+		errs = append(errs, field.Required(metaPath.Child("id"), "id must be set"))
+	}
+	errs = append(errs, validateBRStorage(&r.Meta.Location, metaPath.Child("location"))...)
+	return nil, invalidOrNil(errs, r)
+}
+
+func (r *Backup) ValidateUpdate(o runtime.Object) (admission.Warnings, error) {
+	var errs field.ErrorList
+	old := o.(*Backup)
+	if !equality.Semantic.DeepEqual(old.Meta, r.Meta) {
+		errs = append(errs, field.Forbidden(field.NewPath("meta"), "meta of a backup is immutable"))
+	}
+	return nil, invalidOrNil(errs, r)
+}
+
+func (r *Backup) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateBackupSource(s *BackupSource, parent *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if s.ClusterRef == nil && s.CNSetRef == nil {
+		return append(errs, field.Required(parent, "one of clusterRef or cnSetRef must be set"))
+	}
+	if s.ClusterRef != nil && s.CNSetRef != nil {
+		return append(errs, field.Invalid(parent, nil, "clusterRef and cnSetRef are mutual exclusive"))
+	}
+	if s.CNSetRef != nil && s.SecretRef == nil {
+		errs = append(errs, field.Required(parent.Child("secretRef"), "secretRef must be set when using cnSetRef as backup source"))
+	}
+	return errs
+}
+
+func validateBRTTL(ttl *metav1.Duration, parent *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if ttl == nil {
+		return nil
+	}
+	if ttl.Duration < 0 {
+		errs = append(errs, field.Invalid(parent, ttl.Duration.String(), "ttl must not be negative"))
+	}
+	if ttl.Duration > maxTTL {
+		errs = append(errs, field.Invalid(parent, ttl.Duration.String(), fmt.Sprintf("ttl must not be greater than %s", maxTTL)))
+	}
+	return errs
+}
+
+func validateBackoffLimit(limit *int32, parent *field.Path) field.ErrorList {
+	if limit != nil && *limit < 0 {
+		return field.ErrorList{field.Invalid(parent, *limit, "backoffLimit must not be negative")}
+	}
+	return nil
+}
+
+// getBackup gets the referenced backup, the check is skipped and nil is returned if the client is not initialized
+func getBackup(name string, parent *field.Path) (*Backup, field.ErrorList) {
+	if kClient == nil {
+		return nil, nil
+	}
+	backup := &Backup{}
+	err := kClient.Get(context.TODO(), types.NamespacedName{Name: name}, backup)
+	if apierrors.IsNotFound(err) {
+		return nil, field.ErrorList{field.NotFound(parent, name)}
+	}
+	if err != nil {
+		return nil, field.ErrorList{field.InternalError(parent, err)}
+	}
+	return backup, nil
+}
+
+// validateRestoreFrom validates that the referenced backup exists and can be restored to the namespace
+func validateRestoreFrom(name string, ns string, parent *field.Path) field.ErrorList {
+	backup, errs := getBackup(name, parent)
+	if backup != nil && !backup.RestorableTo(ns) {
+		errs = append(errs, field.Forbidden(parent, fmt.Sprintf("backup %s cannot be restored to namespace %s", name, ns)))
+	}
+	return errs
+}
+
+// validateBRStorage validates the storage that is accessed by the backup and restore jobs
+func validateBRStorage(s *SharedStorageProvider, parent *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if s.S3 == nil && s.FileSystem == nil {
+		return append(errs, field.Required(parent, "one of s3 or fileSystem must be set"))
+	}
+	if s.S3 != nil && s.FileSystem != nil {
+		return append(errs, field.Invalid(parent, nil, "s3 and fileSystem are mutual exclusive"))
+	}
+	if s.S3 != nil && s.S3.Path == "" {
+		errs = append(errs, field.Required(parent.Child("s3", "path"), "path must be set"))
+	}
+	if fs := s.FileSystem; fs != nil {
+		fsPath := parent.Child("fileSystem")
+		if !filepath.IsAbs(fs.Path) {
+			errs = append(errs, field.Invalid(fsPath.Child("path"), fs.Path, "path must be an absolute path"))
+		}
+		if filepath.IsAbs(fs.SubPath) || strings.HasPrefix(filepath.Clean(fs.SubPath), "..") {
+			errs = append(errs, field.Invalid(fsPath.Child("subPath"), fs.SubPath, "subPath must be a relative path within the volume"))
+		}
+		switch {
+		case fs.PersistentVolumeClaim == nil && fs.HostPath == nil:
+			errs = append(errs, field.Required(fsPath, "one of persistentVolumeClaim or hostPath must be set to mount the fileSystem to backup and restore jobs"))
+		case fs.PersistentVolumeClaim != nil && fs.HostPath != nil:
+			errs = append(errs, field.Invalid(fsPath, nil, "persistentVolumeClaim and hostPath are mutual exclusive"))
+		case fs.PersistentVolumeClaim != nil && fs.PersistentVolumeClaim.ClaimName == "":
+			errs = append(errs, field.Required(fsPath.Child("persistentVolumeClaim", "claimName"), "claimName must be set"))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,328 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateBRStorage(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage SharedStorageProvider
+		wantErr bool
+	}{{
+		name:    "empty",
+		wantErr: true,
+	}, {
+		name: "s3",
+		storage: SharedStorageProvider{
+			S3: &S3Provider{Path: "bucket/backup"},
+		},
+	}, {
+		name: "s3 without path",
+		storage: SharedStorageProvider{
+			S3: &S3Provider{},
+		},
+		wantErr: true,
+	}, {
+		name: "both s3 and fileSystem",
+		storage: SharedStorageProvider{
+			S3:         &S3Provider{Path: "bucket/backup"},
+			FileSystem: &FileSystemProvider{Path: "/backup", HostPath: &corev1.HostPathVolumeSource{Path: "/mnt/nfs"}},
+		},
+		wantErr: true,
+	}, {
+		name: "fileSystem with pvc",
+		storage: SharedStorageProvider{
+			FileSystem: &FileSystemProvider{
+				Path:                  "/backup",
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "nfs"},
+			},
+		},
+	}, {
+		name: "fileSystem without volume",
+		storage: SharedStorageProvider{
+			FileSystem: &FileSystemProvider{Path: "/backup"},
+		},
+		wantErr: true,
+	}, {
+		name: "fileSystem with relative path",
+		storage: SharedStorageProvider{
+			FileSystem: &FileSystemProvider{Path: "backup", HostPath: &corev1.HostPathVolumeSource{Path: "/mnt/nfs"}},
+		},
+		wantErr: true,
+	}, {
+		name: "fileSystem with subPath",
+		storage: SharedStorageProvider{
+			FileSystem: &FileSystemProvider{Path: "/backup", HostPath: &corev1.HostPathVolumeSource{Path: "/mnt/nfs"}, SubPath: "mo/backup"},
+		},
+	}, {
+		name: "fileSystem with escaping subPath",
+		storage: SharedStorageProvider{
+			FileSystem: &FileSystemProvider{Path: "/backup", HostPath: &corev1.HostPathVolumeSource{Path: "/mnt/nfs"}, SubPath: "../etc"},
+		},
+		wantErr: true,
+	}, {
+		name: "fileSystem with both pvc and hostPath",
+		storage: SharedStorageProvider{
+			FileSystem: &FileSystemProvider{
+				Path:                  "/backup",
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "nfs"},
+				HostPath:              &corev1.HostPathVolumeSource{Path: "/mnt/nfs"},
+			},
+		},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			errs := validateBRStorage(&tt.storage, field.NewPath("spec", "target"))
+			if tt.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestBackupJobValidate(t *testing.T) {
+	mo := "mo"
+	secret := "secret"
+	target := SharedStorageProvider{S3: &S3Provider{Path: "bucket/backup"}}
+	tests := []struct {
+		name    string
+		spec    BackupJobSpec
+		wantErr bool
+	}{{
+		name: "cluster source",
+		spec: BackupJobSpec{Source: BackupSource{ClusterRef: &mo}, Target: target},
+	}, {
+		name: "cnset source with secret",
+		spec: BackupJobSpec{Source: BackupSource{CNSetRef: &mo, SecretRef: &secret}, Target: target},
+	}, {
+		name:    "no source",
+		spec:    BackupJobSpec{Target: target},
+		wantErr: true,
+	}, {
+		name:    "both cluster and cnset source",
+		spec:    BackupJobSpec{Source: BackupSource{ClusterRef: &mo, CNSetRef: &mo, SecretRef: &secret}, Target: target},
+		wantErr: true,
+	}, {
+		name:    "cnset source without secret",
+		spec:    BackupJobSpec{Source: BackupSource{CNSetRef: &mo}, Target: target},
+		wantErr: true,
+	}, {
+		name:    "no target",
+		spec:    BackupJobSpec{Source: BackupSource{ClusterRef: &mo}},
+		wantErr: true,
+	}, {
+		name:    "ttl too long",
+		spec:    BackupJobSpec{Source: BackupSource{ClusterRef: &mo}, Target: target, TTL: &metav1.Duration{Duration: maxTTL + time.Hour}},
+		wantErr: true,
+	}, {
+		name:    "negative ttl",
+		spec:    BackupJobSpec{Source: BackupSource{ClusterRef: &mo}, Target: target, TTL: &metav1.Duration{Duration: -time.Hour}},
+		wantErr: true,
+	}, {
+		name:    "negative backoffLimit",
+		spec:    BackupJobSpec{Source: BackupSource{ClusterRef: &mo}, Target: target, BackoffLimit: func() *int32 { l := int32(-1); return &l }()},
+		wantErr: true,
+	}, {
+		name: "restore namespaces",
+		spec: BackupJobSpec{Source: BackupSource{ClusterRef: &mo}, Target: target, RestoreNamespaces: []string{"prod", "*"}},
+	}, {
+		name:    "invalid restore namespace",
+		spec:    BackupJobSpec{Source: BackupSource{ClusterRef: &mo}, Target: target, RestoreNamespaces: []string{"Prod"}},
+		wantErr: true,
+	}, {
+		name:    "parent backup of a full backup",
+		spec:    BackupJobSpec{Source: BackupSource{ClusterRef: &mo}, Target: target, Mode: BackupModeFull, ParentBackup: "b1"},
+		wantErr: true,
+	}, {
+		name: "parent backup of an incremental backup",
+		spec: BackupJobSpec{Source: BackupSource{ClusterRef: &mo}, Target: target, Mode: BackupModeIncremental, ParentBackup: "b1"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			bj := &BackupJob{Spec: tt.spec}
+			bj.Default()
+			_, err := bj.ValidateCreate()
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestBackupScheduleValidate(t *testing.T) {
+	mo := "mo"
+	tpl := BackupJobSpec{Source: BackupSource{ClusterRef: &mo}, Target: SharedStorageProvider{S3: &S3Provider{Path: "bucket/backup"}}}
+	negative := func() *int32 { l := int32(-1); return &l }()
+	tests := []struct {
+		name    string
+		spec    BackupScheduleSpec
+		wantErr bool
+	}{{
+		name: "valid",
+		spec: BackupScheduleSpec{Schedule: "0 2 * * *", ConcurrencyPolicy: ConcurrencyPolicyReplace, BackupTemplate: tpl},
+	}, {
+		name: "descriptor schedule",
+		spec: BackupScheduleSpec{Schedule: "@daily", BackupTemplate: tpl},
+	}, {
+		name:    "empty schedule",
+		spec:    BackupScheduleSpec{BackupTemplate: tpl},
+		wantErr: true,
+	}, {
+		name:    "invalid schedule",
+		spec:    BackupScheduleSpec{Schedule: "0 25 * * *", BackupTemplate: tpl},
+		wantErr: true,
+	}, {
+		name:    "schedule with seconds",
+		spec:    BackupScheduleSpec{Schedule: "0 0 2 * * *", BackupTemplate: tpl},
+		wantErr: true,
+	}, {
+		name:    "unknown concurrencyPolicy",
+		spec:    BackupScheduleSpec{Schedule: "0 2 * * *", ConcurrencyPolicy: "Queue", BackupTemplate: tpl},
+		wantErr: true,
+	}, {
+		name:    "negative history limit",
+		spec:    BackupScheduleSpec{Schedule: "0 2 * * *", FailedJobsHistoryLimit: negative, BackupTemplate: tpl},
+		wantErr: true,
+	}, {
+		name:    "negative retention",
+		spec:    BackupScheduleSpec{Schedule: "0 2 * * *", Retention: &BackupRetentionPolicy{KeepDaily: negative}, BackupTemplate: tpl},
+		wantErr: true,
+	}, {
+		name:    "invalid backupTemplate",
+		spec:    BackupScheduleSpec{Schedule: "0 2 * * *", BackupTemplate: BackupJobSpec{Source: tpl.Source}},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			bs := &BackupSchedule{Spec: tt.spec}
+			_, err := bs.ValidateCreate()
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).To(Succeed())
+			}
+			_, err = bs.ValidateUpdate(bs.DeepCopy())
+			g.Expect(err != nil).To(Equal(tt.wantErr))
+		})
+	}
+}
+
+func TestRestoreJobValidate(t *testing.T) {
+	target := SharedStorageProvider{S3: &S3Provider{Path: "bucket/restore"}}
+	now := metav1.Now()
+	tests := []struct {
+		name    string
+		spec    RestoreJobSpec
+		wantErr bool
+	}{{
+		name: "restore from backup",
+		spec: RestoreJobSpec{BackupName: "b1", Target: target},
+	}, {
+		name: "restore the latest backup before a time",
+		spec: RestoreJobSpec{LatestBackupBefore: &now, SourceRef: "matrixonecluster/default/mo", Target: target},
+	}, {
+		name:    "no source",
+		spec:    RestoreJobSpec{Target: target},
+		wantErr: true,
+	}, {
+		name:    "both backupName and latestBackupBefore",
+		spec:    RestoreJobSpec{BackupName: "b1", LatestBackupBefore: &now, SourceRef: "matrixonecluster/default/mo", Target: target},
+		wantErr: true,
+	}, {
+		name:    "latestBackupBefore without sourceRef",
+		spec:    RestoreJobSpec{LatestBackupBefore: &now, Target: target},
+		wantErr: true,
+	}, {
+		name:    "malformed sourceRef",
+		spec:    RestoreJobSpec{LatestBackupBefore: &now, SourceRef: "mo", Target: target},
+		wantErr: true,
+	}, {
+		name:    "external source",
+		spec:    RestoreJobSpec{ExternalSource: &target, Target: target},
+		wantErr: true,
+	}, {
+		name:    "no target",
+		spec:    RestoreJobSpec{BackupName: "b1"},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			rj := &RestoreJob{Spec: tt.spec}
+			rj.Default()
+			_, err := rj.ValidateCreate()
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestRestoreJobDefault(t *testing.T) {
+	g := NewGomegaWithT(t)
+	overlay := &Overlay{PodLabels: map[string]string{"app": "restore"}}
+	rj := &RestoreJob{Overlay: overlay}
+	rj.Default()
+	g.Expect(rj.Spec.TTL.Duration).To(Equal(defaultTTL))
+	g.Expect(rj.Overlay).To(BeNil())
+	g.Expect(rj.Spec.Overlay).To(Equal(overlay))
+	g.Expect(rj.GetOverlay()).To(Equal(overlay))
+}
+
+func TestBackupValidateUpdate(t *testing.T) {
+	g := NewGomegaWithT(t)
+	old := &Backup{Meta: BackupMeta{ID: "id", Location: SharedStorageProvider{S3: &S3Provider{Path: "bucket/backup"}}}}
+	_, err := old.ValidateCreate()
+	g.Expect(err).To(Succeed())
+	updated := old.DeepCopy()
+	updated.Labels = map[string]string{"foo": "bar"}
+	_, err = updated.ValidateUpdate(old)
+	g.Expect(err).To(Succeed())
+	updated.Meta.ID = "another"
+	_, err = updated.ValidateUpdate(old)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestBackupRestorableTo(t *testing.T) {
+	g := NewGomegaWithT(t)
+	b := &Backup{}
+	g.Expect(b.RestorableTo("default")).To(BeTrue())
+	b.Annotations = map[string]string{BackupRestoreNamespacesAnnoKey: "prod, staging"}
+	g.Expect(b.RestorableTo("staging")).To(BeTrue())
+	g.Expect(b.RestorableTo("default")).To(BeFalse())
+	b.Annotations[BackupRestoreNamespacesAnnoKey] = "*"
+	g.Expect(b.RestorableTo("default")).To(BeTrue())
+	b.Annotations[BackupRestoreNamespacesAnnoKey] = ""
+	g.Expect(b.RestorableTo("default")).To(BeFalse())
+}
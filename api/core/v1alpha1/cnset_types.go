@@ -28,10 +28,23 @@ const (
 	CNRoleAP CNRole = "AP"
 )
 
+const (
+	defaultAutoscalingSyncPeriod        = 30 * time.Second
+	defaultScaleDownStabilizationWindow = 5 * time.Minute
+	defaultScaleUpCooldown              = time.Minute
+	defaultScaleDownCooldown            = 5 * time.Minute
+)
+
+const (
+	// CNSetWakeUpAnnoKey wakes up a hibernated CNSet, the annotation is removed once the CNSet is waken up
+	CNSetWakeUpAnnoKey = "matrixorigin.io/wake-up"
+)
+
 const (
 	CNStoreStateUnknown  string = "Unknown"
 	CNStoreStateDraining string = "Draining"
 	CNStoreStateUp       string = "Up"
+	CNStoreStateCordoned string = "Cordoned"
 )
 
 type CNSetSpec struct {
@@ -71,17 +84,127 @@ type CNSetSpec struct {
 	// ScalingConfig declares the CN scaling behavior
 	ScalingConfig ScalingConfig `json:"scalingConfig,omitempty"`
 
-	// MetricsSecretRef is the secret reference for the operator to access CN metrics
+	// MetricsSecretRef is the secret reference for the operator to access CN metrics.
+	// Required by autoscaling, unless the CNSet is managed by a MatrixOneCluster, which sets it
 	MetricsSecretRef *ObjectRef `json:"metricsSecretRef,omitempty"`
 
 	// UpdateStrategy is the rolling-update strategy of CN
 	UpdateStrategy RollingUpdateStrategy `json:"updateStrategy,omitempty"`
+
+	// StartupPolicy controls how the CN service is started in pods. Unlike log and DN stores, CN stores
+	// do not wait for their DNS names to be resolvable unless the StartupPolicy is set.
+	// +optional
+	StartupPolicy *StartupPolicy `json:"startupPolicy,omitempty"`
+
+	// Autoscaling scales the CNSet horizontally by the workload metrics of MatrixOne, replicas of
+	// the CNSet is managed by the autoscaler when set. Enable store draining in ScalingConfig to
+	// scale in gracefully.
+	// +optional
+	Autoscaling *CNAutoscaling `json:"autoscaling,omitempty"`
+
+	// IdlePolicy hibernates the CNSet by scaling it to zero when there is no session on the CN stores
+	// for a while, which is mainly for dev/test clusters. The replicas of the CNSet is kept as the replicas
+	// to restore when the CNSet is waken up by annotating it with CNSetWakeUpAnnoKey, or by the first connection
+	// to a ProxySet that has wakeUpCNSets enabled.
+	// +optional
+	IdlePolicy *CNIdlePolicy `json:"idlePolicy,omitempty"`
+
+	// ScaleInStores is the list of CN stores, identified by pod name or store UUID, to remove first
+	// when the CNSet scales in. Changing the list alone does not remove any store. The rest of the
+	// stores to remove are the ones with the fewest sessions.
+	// +optional
+	ScaleInStores []string `json:"scaleInStores,omitempty"`
+
+	// CordonedStores is the list of CN stores, identified by pod name or store UUID, to cordon. A cordoned
+	// store accepts no new session and is removed from the CN service, the sessions on it are kept.
+	// The store is uncordoned once it is removed from the list.
+	// +optional
+	CordonedStores []string `json:"cordonedStores,omitempty"`
+}
+
+type CNIdlePolicy struct {
+	// IdleTimeout is the duration without any session before the CNSet is hibernated
+	IdleTimeout metav1.Duration `json:"idleTimeout"`
+}
+
+// CNAutoscaling configures the horizontal autoscaling of a CNSet. The metrics are sampled from the
+// system_metrics database of MatrixOne with the credential in MetricsSecretRef, and the desired replicas
+// is the maximum of the replicas required by each target, i.e. ceil(sum of the metric / target).
+type CNAutoscaling struct {
+	// MinReplicas is the lower limit of the replicas
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas int32 `json:"minReplicas"`
+
+	// MaxReplicas is the upper limit of the replicas
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetConnections is the target number of connections per CN store
+	// +optional
+	TargetConnections *int32 `json:"targetConnections,omitempty"`
+
+	// TargetCPUPercent is the target CPU usage of the MO service per CN store, 100 means one core
+	// +optional
+	TargetCPUPercent *int32 `json:"targetCPUPercent,omitempty"`
+
+	// TargetQPS is the target number of SQL statements executed per second per CN store
+	// +optional
+	TargetQPS *int32 `json:"targetQPS,omitempty"`
+
+	// SyncPeriod is the period to sample metrics and compute the desired replicas, default to 30s
+	// +optional
+	SyncPeriod *metav1.Duration `json:"syncPeriod,omitempty"`
+
+	// ScaleUpStabilizationWindow is the window to look back for the lowest recommendation when scaling up,
+	// default to 0 which scales up immediately
+	// +optional
+	ScaleUpStabilizationWindow *metav1.Duration `json:"scaleUpStabilizationWindow,omitempty"`
+
+	// ScaleDownStabilizationWindow is the window to look back for the highest recommendation when scaling down,
+	// default to 5m
+	// +optional
+	ScaleDownStabilizationWindow *metav1.Duration `json:"scaleDownStabilizationWindow,omitempty"`
+
+	// ScaleUpCooldown is the minimum interval between the last scaling and a scale-up, default to 1m
+	// +optional
+	ScaleUpCooldown *metav1.Duration `json:"scaleUpCooldown,omitempty"`
+
+	// ScaleDownCooldown is the minimum interval between the last scaling and a scale-down, default to 5m
+	// +optional
+	ScaleDownCooldown *metav1.Duration `json:"scaleDownCooldown,omitempty"`
+}
+
+func (a *CNAutoscaling) GetSyncPeriod() time.Duration {
+	return durationOrDefault(a.SyncPeriod, defaultAutoscalingSyncPeriod)
+}
+
+func (a *CNAutoscaling) GetScaleUpStabilizationWindow() time.Duration {
+	return durationOrDefault(a.ScaleUpStabilizationWindow, 0)
+}
+
+func (a *CNAutoscaling) GetScaleDownStabilizationWindow() time.Duration {
+	return durationOrDefault(a.ScaleDownStabilizationWindow, defaultScaleDownStabilizationWindow)
+}
+
+func (a *CNAutoscaling) GetScaleUpCooldown() time.Duration {
+	return durationOrDefault(a.ScaleUpCooldown, defaultScaleUpCooldown)
+}
+
+func (a *CNAutoscaling) GetScaleDownCooldown() time.Duration {
+	return durationOrDefault(a.ScaleDownCooldown, defaultScaleDownCooldown)
+}
+
+func durationOrDefault(d *metav1.Duration, defaultValue time.Duration) time.Duration {
+	if d == nil {
+		return defaultValue
+	}
+	return d.Duration
 }
 
 type ScalingConfig struct {
 	// StoreDrainEnabled is the flag to enable store draining
 	StoreDrainEnabled *bool `json:"storeDrainEnabled,omitempty"`
-	// StoreDrainTimeout is the timeout for draining a CN store
+	// StoreDrainTimeout is the timeout for draining a CN store, the store is removed regardless of the
+	// sessions left on it after the timeout
 	StoreDrainTimeout *metav1.Duration `json:"storeDrainTimeout,omitempty"`
 }
 
@@ -117,12 +240,100 @@ type CNSetStatus struct {
 
 	Host string `json:"host,omitempty"`
 	Port int    `json:"port,omitempty"`
+
+	// Autoscaling is the status of the autoscaler, only set when autoscaling is enabled
+	// +optional
+	Autoscaling *CNAutoscalingStatus `json:"autoscaling,omitempty"`
+
+	// Hibernation is the status of the idle policy, only set when the idle policy is enabled
+	// +optional
+	Hibernation *CNHibernationStatus `json:"hibernation,omitempty"`
+}
+
+type CNHibernationStatus struct {
+	// IdleSince is the time since when no session is observed on the CN stores
+	// +optional
+	IdleSince *metav1.Time `json:"idleSince,omitempty"`
+
+	// Hibernated indicates that the CNSet is scaled to zero by the idle policy
+	// +optional
+	Hibernated bool `json:"hibernated,omitempty"`
+
+	// HibernatedTime is the time when the CNSet was hibernated
+	// +optional
+	HibernatedTime *metav1.Time `json:"hibernatedTime,omitempty"`
+}
+
+// IsHibernated returns whether the CNSet is scaled to zero by the idle policy
+func (s *CNSetStatus) IsHibernated() bool {
+	return s.Hibernation != nil && s.Hibernation.Hibernated
+}
+
+type CNAutoscalingStatus struct {
+	// LastSampleTime is the last time the metrics were sampled
+	// +optional
+	LastSampleTime *metav1.Time `json:"lastSampleTime,omitempty"`
+
+	// LastScaleTime is the last time the autoscaler scaled the CNSet
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+
+	// DesiredReplicas is the replicas desired by the autoscaler after stabilization
+	DesiredReplicas int32 `json:"desiredReplicas,omitempty"`
+
+	// Connections is the sampled number of connections of all the CN stores
+	Connections int64 `json:"connections,omitempty"`
+
+	// CPUPercent is the sampled CPU usage of all the CN stores, 100 means one core
+	CPUPercent int64 `json:"cpuPercent,omitempty"`
+
+	// QPS is the sampled number of SQL statements executed per second of all the CN stores
+	QPS int64 `json:"qps,omitempty"`
+
+	// Recommendations are the recent replicas recommended by the metrics, kept for stabilization
+	// +optional
+	Recommendations []CNAutoscalingRecommendation `json:"recommendations,omitempty"`
+
+	// Message is a human-readable description of the last autoscaling decision
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+type CNAutoscalingRecommendation struct {
+	Time     metav1.Time `json:"time"`
+	Replicas int32       `json:"replicas"`
 }
 
 type CNStore struct {
 	UUID    string `json:"uuid,omitempty"`
 	PodName string `json:"podName,omitempty"`
 	State   string `json:"state,omitempty"`
+
+	// Sessions is the number of sessions on the store, only observed when the CNSet is scaling in
+	// +optional
+	Sessions *int `json:"sessions,omitempty"`
+
+	// Draining is the draining progress of the store, only set when the store is draining
+	// +optional
+	Draining *CNStoreDrainingProgress `json:"draining,omitempty"`
+}
+
+// CNStoreDrainingProgress is the progress of draining the sessions off a CN store
+type CNStoreDrainingProgress struct {
+	// StartTime is the time when the draining started
+	StartTime metav1.Time `json:"startTime"`
+
+	// Sessions is the number of sessions left on the store, including sessions of the sys account
+	Sessions int `json:"sessions"`
+
+	// MigratingByProxy indicates that the sessions are being migrated to other CN stores by
+	// the Proxy that fronts the cluster
+	// +optional
+	MigratingByProxy bool `json:"migratingByProxy,omitempty"`
+
+	// Message is a human-readable description of the draining progress
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 type CNSetDeps struct {
@@ -91,9 +91,19 @@ func (r *CNSet) ValidateCreate() (admission.Warnings, error) {
 	errs = append(errs, validateLogSetRef(&r.Deps.LogSetRef, field.NewPath("deps"))...)
 	errs = append(errs, r.Spec.ValidateCreate()...)
 	errs = append(errs, validateMainContainer(&r.Spec.MainContainer, field.NewPath("spec"))...)
+	if r.Spec.Autoscaling != nil && r.Spec.MetricsSecretRef == nil && !managedByMatrixOneCluster(r) {
+		// the MatrixOneCluster injects the metricsSecretRef once the cluster metrics are initialized
+		errs = append(errs, field.Required(field.NewPath("spec").Child("metricsSecretRef"), "metricsSecretRef must be set to sample the metrics for autoscaling"))
+	}
 	return nil, invalidOrNil(errs, r)
 }
 
+// managedByMatrixOneCluster returns whether the object is controlled by a MatrixOneCluster
+func managedByMatrixOneCluster(o metav1.Object) bool {
+	owner := metav1.GetControllerOf(o)
+	return owner != nil && owner.Kind == "MatrixOneCluster" && owner.APIVersion == GroupVersion.String()
+}
+
 func (r *CNSet) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
 	warnings, err := r.ValidateCreate()
 	if err != nil {
@@ -131,5 +141,70 @@ func (r *CNSetSpec) ValidateCreate() field.ErrorList {
 		}
 	}
 	errs = append(errs, validateGoMemLimitPercent(r.MemoryLimitPercent, field.NewPath("spec").Child("memoryLimitPercent"))...)
+	errs = append(errs, validateStartupPolicy(r.StartupPolicy, field.NewPath("spec").Child("startupPolicy"))...)
+	errs = append(errs, validateAutoscaling(r.Autoscaling, field.NewPath("spec").Child("autoscaling"))...)
+	if r.IdlePolicy != nil && r.IdlePolicy.IdleTimeout.Duration <= 0 {
+		errs = append(errs, field.Invalid(field.NewPath("spec").Child("idlePolicy").Child("idleTimeout"), r.IdlePolicy.IdleTimeout, "idleTimeout must be positive"))
+	}
+	errs = append(errs, validateStoreRefs(r.ScaleInStores, field.NewPath("spec").Child("scaleInStores"))...)
+	errs = append(errs, validateStoreRefs(r.CordonedStores, field.NewPath("spec").Child("cordonedStores"))...)
+	return errs
+}
+
+func validateStoreRefs(stores []string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, store := range stores {
+		if store == "" {
+			errs = append(errs, field.Invalid(path.Index(i), store, "store must be a pod name or store UUID"))
+		}
+	}
+	return errs
+}
+
+func validateAutoscaling(a *CNAutoscaling, path *field.Path) field.ErrorList {
+	if a == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	if a.MinReplicas < 1 {
+		errs = append(errs, field.Invalid(path.Child("minReplicas"), a.MinReplicas, "minReplicas must be at least 1"))
+	}
+	if a.MaxReplicas < a.MinReplicas {
+		errs = append(errs, field.Invalid(path.Child("maxReplicas"), a.MaxReplicas, "maxReplicas must not be less than minReplicas"))
+	}
+	if a.TargetConnections == nil && a.TargetCPUPercent == nil && a.TargetQPS == nil {
+		errs = append(errs, field.Required(path, "at least one of targetConnections, targetCPUPercent and targetQPS must be set"))
+	}
+	targets := []struct {
+		name  string
+		value *int32
+	}{
+		{"targetConnections", a.TargetConnections},
+		{"targetCPUPercent", a.TargetCPUPercent},
+		{"targetQPS", a.TargetQPS},
+	}
+	for _, t := range targets {
+		if t.value != nil && *t.value <= 0 {
+			errs = append(errs, field.Invalid(path.Child(t.name), *t.value, t.name+" must be positive"))
+		}
+	}
+	durations := []struct {
+		name  string
+		value *metav1.Duration
+	}{
+		{"syncPeriod", a.SyncPeriod},
+		{"scaleUpStabilizationWindow", a.ScaleUpStabilizationWindow},
+		{"scaleDownStabilizationWindow", a.ScaleDownStabilizationWindow},
+		{"scaleUpCooldown", a.ScaleUpCooldown},
+		{"scaleDownCooldown", a.ScaleDownCooldown},
+	}
+	for _, d := range durations {
+		if d.value != nil && d.value.Duration < 0 {
+			errs = append(errs, field.Invalid(path.Child(d.name), d.value, d.name+" must not be negative"))
+		}
+	}
+	if a.SyncPeriod != nil && a.SyncPeriod.Duration == 0 {
+		errs = append(errs, field.Invalid(path.Child("syncPeriod"), a.SyncPeriod, "syncPeriod must be positive"))
+	}
 	return errs
 }
@@ -16,10 +16,15 @@ package v1alpha1
 
 import (
 	"context"
+	"testing"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
 )
 
 var _ = Describe("CNSet Webhook", func() {
@@ -129,3 +134,115 @@ var _ = Describe("CNSet Webhook", func() {
 		Expect(k8sClient.Create(context.TODO(), validLabel)).To(Succeed())
 	})
 })
+
+func TestValidateAutoscaling(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       *CNAutoscaling
+		wantErr bool
+	}{{
+		name: "disabled",
+	}, {
+		name: "valid",
+		a:    &CNAutoscaling{MinReplicas: 1, MaxReplicas: 3, TargetQPS: pointer.Int32(1000)},
+	}, {
+		name:    "no target",
+		a:       &CNAutoscaling{MinReplicas: 1, MaxReplicas: 3},
+		wantErr: true,
+	}, {
+		name:    "max less than min",
+		a:       &CNAutoscaling{MinReplicas: 3, MaxReplicas: 2, TargetConnections: pointer.Int32(100)},
+		wantErr: true,
+	}, {
+		name:    "zero min replicas",
+		a:       &CNAutoscaling{MaxReplicas: 2, TargetConnections: pointer.Int32(100)},
+		wantErr: true,
+	}, {
+		name:    "non-positive target",
+		a:       &CNAutoscaling{MinReplicas: 1, MaxReplicas: 2, TargetCPUPercent: pointer.Int32(0)},
+		wantErr: true,
+	}, {
+		name: "zero sync period",
+		a: &CNAutoscaling{MinReplicas: 1, MaxReplicas: 2, TargetConnections: pointer.Int32(100),
+			SyncPeriod: &metav1.Duration{}},
+		wantErr: true,
+	}, {
+		name: "negative cooldown",
+		a: &CNAutoscaling{MinReplicas: 1, MaxReplicas: 2, TargetConnections: pointer.Int32(100),
+			ScaleDownCooldown: &metav1.Duration{Duration: -time.Second}},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			errs := validateAutoscaling(tt.a, field.NewPath("spec", "autoscaling"))
+			if tt.wantErr {
+				g.Expect(errs).NotTo(BeEmpty())
+			} else {
+				g.Expect(errs).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestCNSetValidateMetricsSecretRef(t *testing.T) {
+	autoscaling := &CNAutoscaling{MinReplicas: 1, MaxReplicas: 3, TargetConnections: pointer.Int32(100)}
+	tests := []struct {
+		name      string
+		ref       *ObjectRef
+		ownerKind string
+		wantErr   bool
+	}{{
+		name: "with metricsSecretRef",
+		ref:  &ObjectRef{Name: "metrics"},
+	}, {
+		name:    "without metricsSecretRef",
+		wantErr: true,
+	}, {
+		name:      "managed by MatrixOneCluster",
+		ownerKind: "MatrixOneCluster",
+	}, {
+		name:      "controlled by other kind",
+		ownerKind: "ProxySet",
+		wantErr:   true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			cn := &CNSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "cn", Namespace: "default"},
+				Spec: CNSetSpec{
+					PodSet:           PodSet{Replicas: 1, MainContainer: MainContainer{Image: "test"}},
+					Autoscaling:      autoscaling,
+					MetricsSecretRef: tt.ref,
+				},
+				Deps: CNSetDeps{LogSetRef: LogSetRef{ExternalLogSet: &ExternalLogSet{}}},
+			}
+			if tt.ownerKind != "" {
+				cn.OwnerReferences = []metav1.OwnerReference{{
+					APIVersion: GroupVersion.String(),
+					Kind:       tt.ownerKind,
+					Name:       "mo",
+					UID:        "uid",
+					Controller: pointer.Bool(true),
+				}}
+			}
+			_, err := cn.ValidateCreate()
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestValidateStoreRefs(t *testing.T) {
+	g := NewGomegaWithT(t)
+	path := field.NewPath("spec").Child("cordonedStores")
+	g.Expect(validateStoreRefs(nil, path)).To(BeEmpty())
+	g.Expect(validateStoreRefs([]string{"cn-0", "uuid-1"}, path)).To(BeEmpty())
+	errs := validateStoreRefs([]string{"cn-0", ""}, path)
+	g.Expect(errs).To(HaveLen(1))
+	g.Expect(errs[0].Field).To(Equal("spec.cordonedStores[1]"))
+}
@@ -234,8 +234,13 @@ func setDefaultServiceArgs(object interface{}) {
 }
 
 func GetCNPodUUID(pod *corev1.Pod) string {
-	addr := fmt.Sprintf("%s.%s.%s.svc\n", pod.Name, pod.Spec.Subdomain, pod.Namespace)
-	sum := sha256.Sum256([]byte(addr))
+	return AddressUUID(fmt.Sprintf("%s.%s.%s.svc", pod.Name, pod.Spec.Subdomain, pod.Namespace))
+}
+
+// AddressUUID derives the UUID of a MO service from its address, which is the same as
+// `echo ${ADDR} | sha256sum | od -x | head -1 | awk '{OFS="-"; print $2$3,$4,$5,$6,$7$8$9}'`
+func AddressUUID(addr string) string {
+	sum := sha256.Sum256([]byte(addr + "\n"))
 	hexStr := []byte(hex.EncodeToString(sum[:]))
 	s := make([]uint16, 8)
 	// simulate the behavior of od -x
@@ -246,6 +251,16 @@ func GetCNPodUUID(pod *corev1.Pod) string {
 		"%x%x-%x-%x-%x-%x%x%x", s[0], s[1], s[2], s[3], s[4], s[5], s[6], s[7])
 }
 
+// LogStoreUUID returns the UUID of the log store of the given pod ordinal
+func LogStoreUUID(ordinal int) string {
+	return fmt.Sprintf("00000000-0000-0000-0000-0%011x", ordinal)
+}
+
+// DNStoreUUID returns the UUID of the DN store of the given pod ordinal
+func DNStoreUUID(ordinal int) string {
+	return fmt.Sprintf("00000000-0000-0000-0000-1%011x", ordinal)
+}
+
 func LogSetKey(mo *MatrixOneCluster) metav1.ObjectMeta {
 	return metav1.ObjectMeta{
 		Name:      mo.Name,
@@ -15,6 +15,8 @@
 package v1alpha1
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -40,6 +42,9 @@ const (
 	ContainerMain = "main"
 
 	EnvGoMemLimit = "GOMEMLIMIT"
+
+	defaultDNSWaitTimeout = 30 * time.Second
+	defaultDNSWaitPeriod  = time.Second
 )
 
 type ConditionalStatus struct {
@@ -88,6 +93,41 @@ type PodSet struct {
 	MemoryLimitPercent *int `json:"memoryLimitPercent,omitempty"`
 }
 
+// StartupPolicy controls how the MO service is started in a pod
+type StartupPolicy struct {
+	// DNSWaitTimeout is the timeout of waiting for the DNS name of the pod to be resolvable
+	// before starting the MO service, default to 30s
+	// +optional
+	DNSWaitTimeout *metav1.Duration `json:"dnsWaitTimeout,omitempty"`
+
+	// DNSWaitPeriod is the period of resolving the DNS name of the pod, default to 1s
+	// +optional
+	DNSWaitPeriod *metav1.Duration `json:"dnsWaitPeriod,omitempty"`
+
+	// SkipDNSWait starts the MO service without waiting for the DNS name of the pod to be resolvable
+	// +optional
+	SkipDNSWait bool `json:"skipDNSWait,omitempty"`
+}
+
+func (p *StartupPolicy) GetDNSWaitTimeout() time.Duration {
+	if p == nil || p.DNSWaitTimeout == nil {
+		return defaultDNSWaitTimeout
+	}
+	return p.DNSWaitTimeout.Duration
+}
+
+func (p *StartupPolicy) GetDNSWaitPeriod() time.Duration {
+	if p == nil || p.DNSWaitPeriod == nil {
+		return defaultDNSWaitPeriod
+	}
+	return p.DNSWaitPeriod.Duration
+}
+
+// WaitDNS returns whether the DNS name of the pod should be waited before starting the MO service
+func (p *StartupPolicy) WaitDNS() bool {
+	return p == nil || !p.SkipDNSWait
+}
+
 // MainContainer is the description of the main container of a Pod
 type MainContainer struct {
 	// Image is the docker image of the main container
@@ -271,6 +311,21 @@ type FileSystemProvider struct {
 	// Path the path that the shared fileSystem mounted to
 	// +required
 	Path string `json:"path"`
+
+	// PersistentVolumeClaim is mounted to the path in backup and restore jobs to provide the shared fileSystem,
+	// mutual-exclusive with hostPath. It is ignored by other components, which assume the fileSystem is mounted already
+	// +optional
+	PersistentVolumeClaim *corev1.PersistentVolumeClaimVolumeSource `json:"persistentVolumeClaim,omitempty"`
+
+	// HostPath is mounted to the path in backup and restore jobs to provide the shared fileSystem,
+	// mutual-exclusive with persistentVolumeClaim. It is ignored by other components, which assume the fileSystem is mounted already
+	// +optional
+	HostPath *corev1.HostPathVolumeSource `json:"hostPath,omitempty"`
+
+	// SubPath is the sub-path of the persistentVolumeClaim or hostPath that is mounted to the path,
+	// the volume root is mounted if empty. It is set by the operator when recording the location of a Backup
+	// +optional
+	SubPath string `json:"subPath,omitempty"`
 }
 
 type S3Provider struct {
@@ -0,0 +1,53 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestStartupPolicy(t *testing.T) {
+	g := NewGomegaWithT(t)
+	var nilPolicy *StartupPolicy
+	g.Expect(nilPolicy.WaitDNS()).To(BeTrue())
+	g.Expect(nilPolicy.GetDNSWaitTimeout()).To(Equal(30 * time.Second))
+	g.Expect(nilPolicy.GetDNSWaitPeriod()).To(Equal(time.Second))
+	g.Expect(validateStartupPolicy(nilPolicy, field.NewPath("spec", "startupPolicy"))).To(BeEmpty())
+
+	p := &StartupPolicy{
+		DNSWaitTimeout: &metav1.Duration{Duration: time.Minute},
+		DNSWaitPeriod:  &metav1.Duration{Duration: 5 * time.Second},
+	}
+	g.Expect(p.WaitDNS()).To(BeTrue())
+	g.Expect(p.GetDNSWaitTimeout()).To(Equal(time.Minute))
+	g.Expect(p.GetDNSWaitPeriod()).To(Equal(5 * time.Second))
+	g.Expect(validateStartupPolicy(p, field.NewPath("spec", "startupPolicy"))).To(BeEmpty())
+
+	g.Expect((&StartupPolicy{SkipDNSWait: true}).WaitDNS()).To(BeFalse())
+
+	invalid := []*StartupPolicy{
+		{DNSWaitTimeout: &metav1.Duration{Duration: 0}},
+		{DNSWaitPeriod: &metav1.Duration{Duration: -time.Second}},
+		{DNSWaitTimeout: &metav1.Duration{Duration: time.Second}, DNSWaitPeriod: &metav1.Duration{Duration: 2 * time.Second}},
+	}
+	for _, p := range invalid {
+		g.Expect(validateStartupPolicy(p, field.NewPath("spec", "startupPolicy"))).NotTo(BeEmpty())
+	}
+}
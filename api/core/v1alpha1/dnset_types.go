@@ -28,6 +28,11 @@ type DNSetSpec struct {
 	CacheVolume *Volume `json:"cacheVolume,omitempty"`
 
 	SharedStorageCache SharedStorageCache `json:"sharedStorageCache,omitempty"`
+
+	// StartupPolicy controls how the DN service is started in pods. DN stores wait for their DNS names
+	// to be resolvable for 30s by default before starting.
+	// +optional
+	StartupPolicy *StartupPolicy `json:"startupPolicy,omitempty"`
 }
 
 type DNSetStatus struct {
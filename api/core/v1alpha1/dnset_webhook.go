@@ -82,5 +82,6 @@ func (r *DNSetSpec) ValidateCreate() field.ErrorList {
 		errs = append(errs, validateVolume(r.CacheVolume, field.NewPath("spec").Child("cacheVolume"))...)
 	}
 	errs = append(errs, validateGoMemLimitPercent(r.MemoryLimitPercent, field.NewPath("spec").Child("memoryLimitPercent"))...)
+	errs = append(errs, validateStartupPolicy(r.StartupPolicy, field.NewPath("spec").Child("startupPolicy"))...)
 	return errs
 }
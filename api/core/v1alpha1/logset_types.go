@@ -31,6 +31,10 @@ const (
 	FailedPodStrategyDelete FailedPodStrategy = "Delete"
 )
 
+// LogSetSpec is the desired state of a LogSet. When HAKeeper is reachable, pod template changes are rolled
+// out one log store at a time, stores hosting fewer log shard leaders first, and only while every log shard
+// keeps its quorum. Leadership is not transferred before a store restarts since HAKeeper does not expose
+// leadership transfer, the log shard leaders on the store are re-elected by raft after it stops.
 type LogSetSpec struct {
 	PodSet `json:",inline"`
 
@@ -59,9 +63,16 @@ type LogSetSpec struct {
 	// - Delete: delete orphaned PVCs
 	// - Retain: keep orphaned PVCs, if the corresponding Pod get created again (e.g. scale-in and scale-out, recreate the cluster),
 	// the Pod will reuse the retained PVC which contains previous data. Retained PVCs require manual cleanup if they are no longer needed.
+	// When HAKeeper is reachable, the replicas of a removed log store are re-homed to other stores, so the logset is not
+	// scaled out onto the retained PVC of a removed log store until the PVC is deleted.
 	// The default policy is Delete.
 	// +optional
 	PVCRetentionPolicy *PVCRetentionPolicy `json:"pvcRetentionPolicy,omitempty"`
+
+	// StartupPolicy controls how the logservice is started in pods. Log stores wait for their DNS names
+	// to be resolvable for 30s by default before starting.
+	// +optional
+	StartupPolicy *StartupPolicy `json:"startupPolicy,omitempty"`
 }
 
 func (l *LogSetSpec) GetFailedPodStrategy() FailedPodStrategy {
@@ -115,7 +126,8 @@ type InitialConfig struct {
 	// HAKeeperReplicas *int `json:"haKeeperReplicas,omitempty"`
 
 	// LogShardReplicas is the replica numbers of each log shard,
-	// cannot be tuned after cluster creation currently.
+	// cannot be tuned after cluster creation since HAKeeper only records it at bootstrap
+	// and provides no way to add or remove log shard replicas.
 	// default to 3 if LogSet replicas >= 3, to 1 otherwise
 	// +required
 	LogShardReplicas *int `json:"logShardReplicas,omitempty"`
@@ -125,16 +137,227 @@ type InitialConfig struct {
 	RestoreFrom *string `json:"restoreFrom,omitempty"`
 }
 
+const (
+	// LogSetConditionTypeRollingUpdatePaused is True when the rolling-update of the logset is paused
+	// because log shards are unhealthy after the last log store was updated
+	LogSetConditionTypeRollingUpdatePaused = "RollingUpdatePaused"
+
+	// LogSetConditionTypeBootstrapDecisionValid is False when the recorded bootstrap decision of the logset
+	// is missing or conflicts with the bootstrap config that the log stores start with
+	LogSetConditionTypeBootstrapDecisionValid = "BootstrapDecisionValid"
+
+	// LogSetReBootstrapAnnoKey requests to discard the bootstrap decision of the logset and bootstrap HAKeeper
+	// again, the value of the annotation is recorded as the reason of the re-bootstrap. The data volumes are not
+	// touched, so this is only intended for disaster scenarios where the data of the logset is lost or is going
+	// to be wiped. The annotation is removed once the new decision is made.
+	LogSetReBootstrapAnnoKey = "logset.matrixorigin.io/re-bootstrap"
+
+	// LogSetRecoveryAnnoKey approves to prepare the majority-failure recovery of a logset when it is set to
+	// LogSetRecoveryApproved, the data volumes of the surviving log stores are cloned and the annotation is
+	// removed once the clones are bound. Restoring the HAKeeper quorum is not automated since MO only applies
+	// the bootstrap members to log stores without data and provides no way to force new HAKeeper membership
+	// on the existing data of the survivors.
+	LogSetRecoveryAnnoKey  = "logset.matrixorigin.io/recovery"
+	LogSetRecoveryApproved = "approved"
+)
+
+// LogSetRecoveryPhase is a step of the majority-failure recovery of a logset
+type LogSetRecoveryPhase string
+
+const (
+	// LogSetRecoverySnapshotting clones the data volumes of the surviving log stores
+	LogSetRecoverySnapshotting LogSetRecoveryPhase = "Snapshotting"
+	// LogSetRecoverySnapshotted means the data of the surviving log stores is cloned, the HAKeeper quorum
+	// is yet to be restored manually
+	LogSetRecoverySnapshotted LogSetRecoveryPhase = "Snapshotted"
+)
+
 // TODO: figure out what status should be exposed
 type LogSetStatus struct {
 	ConditionalStatus `json:",inline"`
 	FailoverStatus    `json:",inline"`
 
 	Discovery *LogSetDiscovery `json:"discovery,omitempty"`
-	// TODO(aylei): collect LogShards, DNShards and HAKeeper status from HAKeeper
-	// HAKeeper          *HAKeeperStatus  `json:"haKeeper,omitempty"`
-	// LogShards
-	// DNShards
+
+	// ScaleIn records the progress of an ongoing scale-in, nil if there is no scale-in in progress
+	// +optional
+	ScaleIn *LogSetScaleInStatus `json:"scaleIn,omitempty"`
+
+	// Stores is the HAKeeper view of the log stores, refreshed when HAKeeper is reachable
+	// +optional
+	Stores []LogStoreStatus `json:"stores,omitempty"`
+
+	// Recovery records the progress of preparing the last majority-failure recovery
+	// +optional
+	Recovery *LogSetRecoveryStatus `json:"recovery,omitempty"`
+
+	// HAKeeperState is the state of HAKeeper, refreshed when HAKeeper is reachable
+	// +optional
+	HAKeeperState string `json:"haKeeperState,omitempty"`
+
+	// LogShards is the topology of log shards reported by HAKeeper
+	// +optional
+	LogShards []LogShardStatus `json:"logShards,omitempty"`
+
+	// DNShards are the DN shard replicas reported by HAKeeper
+	// +optional
+	DNShards []DNShardStatus `json:"dnShards,omitempty"`
+
+	// ReBootstraps is the audit trail of the re-bootstraps requested by LogSetReBootstrapAnnoKey
+	// +optional
+	ReBootstraps []LogSetReBootstrapRecord `json:"reBootstraps,omitempty"`
+}
+
+// LogSetReBootstrapRecord records a re-bootstrap of the logset
+type LogSetReBootstrapRecord struct {
+	// Reason is the value of the re-bootstrap annotation
+	Reason string `json:"reason"`
+
+	// Previous is the discarded bootstrap decision, empty if there was no decision
+	// +optional
+	Previous []string `json:"previous,omitempty"`
+
+	// Members are the HAKeeper members of the new bootstrap decision
+	Members []string `json:"members"`
+
+	Time metav1.Time `json:"time"`
+}
+
+// LogShardStatus is the HAKeeper view of a log shard
+type LogShardStatus struct {
+	ShardID int64 `json:"shardID"`
+
+	// NumberOfReplicas is the expected number of replicas of the shard
+	// +optional
+	NumberOfReplicas int64 `json:"numberOfReplicas,omitempty"`
+
+	// Replicas are the member replicas of the shard at the current epoch
+	// +optional
+	Replicas []LogShardReplicaStatus `json:"replicas,omitempty"`
+
+	// LeaderReplicaID is the replica ID of the leader, 0 if the leader is unknown
+	// +optional
+	LeaderReplicaID int64 `json:"leaderReplicaID,omitempty"`
+
+	// LeaderStore is the pod name of the log store that hosts the leader replica
+	// +optional
+	LeaderStore string `json:"leaderStore,omitempty"`
+
+	// Term is the raft term of the shard
+	// +optional
+	Term int64 `json:"term,omitempty"`
+
+	// Epoch is the membership epoch of the shard
+	// +optional
+	Epoch int64 `json:"epoch,omitempty"`
+}
+
+// LogShardReplicaStatus is a replica of a log shard
+type LogShardReplicaStatus struct {
+	ReplicaID int64 `json:"replicaID"`
+
+	// StoreUUID is the UUID of the log store that hosts the replica
+	StoreUUID string `json:"storeUUID"`
+
+	// PodName is the pod name of the log store that hosts the replica, empty if the store is not managed by the logset
+	// +optional
+	PodName string `json:"podName,omitempty"`
+}
+
+// DNShardStatus is a DN shard replica reported by HAKeeper
+type DNShardStatus struct {
+	ShardID int64 `json:"shardID"`
+
+	ReplicaID int64 `json:"replicaID"`
+
+	// StoreUUID is the UUID of the DN store that hosts the replica
+	StoreUUID string `json:"storeUUID"`
+
+	// ServiceAddress is the service address of the DN store that hosts the replica
+	// +optional
+	ServiceAddress string `json:"serviceAddress,omitempty"`
+}
+
+// LogSetScaleInStatus describes the progress of a scale-in. Log stores are removed one at a time,
+// the next store will not be removed until HAKeeper reports that all log shard replicas of the
+// removing store are gone and every log shard is fully replicated again. HAKeeper re-homes the replicas
+// only after the removed store is gone, so the log shards hosted by the removing store run one replica
+// short until then.
+type LogSetScaleInStatus struct {
+	// TargetReplicas is the replicas that the logset is scaling in to
+	TargetReplicas int32 `json:"targetReplicas"`
+
+	// RemovingStore is the pod name of the log store that is being removed
+	// +optional
+	RemovingStore string `json:"removingStore,omitempty"`
+
+	// RemovingStoreUUID is the UUID of the log store that is being removed
+	// +optional
+	RemovingStoreUUID string `json:"removingStoreUUID,omitempty"`
+
+	// PendingReplicas are the log shard replicas that are still hosted by the removing store
+	// or are yet to be re-created on other stores, in the form of <shardID>:<replicaID>
+	// +optional
+	PendingReplicas []string `json:"pendingReplicas,omitempty"`
+
+	// Message is a human-readable message about what the scale-in is waiting for
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// StartTime is the time when the scale-in started
+	StartTime metav1.Time `json:"startTime"`
+}
+
+// LogStoreStatus is the HAKeeper view of a log store
+type LogStoreStatus struct {
+	// PodName is the name of the pod that runs the log store
+	PodName string `json:"podName"`
+
+	// UUID is the UUID of the log store
+	UUID string `json:"uuid"`
+
+	// Tick is the HAKeeper tick of the last heartbeat of the log store
+	// +optional
+	Tick int64 `json:"tick,omitempty"`
+
+	// HeartbeatExpired is true if HAKeeper has not received heartbeat from the log store within the log store timeout
+	// +optional
+	HeartbeatExpired bool `json:"heartbeatExpired,omitempty"`
+
+	// Replicas are the log shard replicas on the log store, in the form of <shardID>:<replicaID>
+	// +optional
+	Replicas []string `json:"replicas,omitempty"`
+
+	// LeaderShards are the IDs of log shards whose leader replica is on the log store
+	// +optional
+	LeaderShards []int64 `json:"leaderShards,omitempty"`
+}
+
+// LogSetRecoveryStatus describes the progress of preparing a majority-failure recovery. The preparation is started
+// when the LogSetRecoveryAnnoKey annotation is approved, and the annotation is removed once the data of the
+// surviving log stores is cloned.
+type LogSetRecoveryStatus struct {
+	// Phase is the current step of the recovery
+	Phase LogSetRecoveryPhase `json:"phase"`
+
+	// Survivors are the pod names of the surviving log stores whose data is cloned
+	// +optional
+	Survivors []string `json:"survivors,omitempty"`
+
+	// Snapshots are the names of the persistent volume claims that clone the data of the survivors
+	// +optional
+	Snapshots []string `json:"snapshots,omitempty"`
+
+	// Message is a human-readable message about the current step
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// StartTime is the time when the recovery started
+	StartTime metav1.Time `json:"startTime"`
+
+	// CompletionTime is the time when the data of the survivors is cloned
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 }
 
 type LogSetDiscovery struct {
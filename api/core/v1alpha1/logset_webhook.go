@@ -146,6 +146,7 @@ func (r *LogSetSpec) validateMutateCommon() field.ErrorList {
 	errs = append(errs, r.validateInitialConfig()...)
 	errs = append(errs, r.validateSharedStorage()...)
 	errs = append(errs, validateGoMemLimitPercent(r.MemoryLimitPercent, field.NewPath("spec").Child("memoryLimitPercent"))...)
+	errs = append(errs, validateStartupPolicy(r.StartupPolicy, field.NewPath("spec").Child("startupPolicy"))...)
 	return errs
 }
 
@@ -84,6 +84,11 @@ type MatrixOneClusterSpec struct {
 	// +optional
 	// +immutable
 	RestoreFrom *string `json:"restoreFrom,omitempty"`
+
+	// BackupRetention is the retention policy of the backups taken from this cluster,
+	// backups created by a BackupSchedule follow the retention policy of the schedule instead
+	// +optional
+	BackupRetention *BackupRetentionPolicy `json:"backupRetention,omitempty"`
 }
 
 func (m *MatrixOneCluster) GetTN() *DNSetSpec {
@@ -162,6 +167,8 @@ type CNGroupStatus struct {
 	Host   string `json:"host,omitempty"`
 	Ready  bool   `json:"ready,omitempty"`
 	Synced bool   `json:"synced,omitempty"`
+	// Hibernated indicates that the CN group is scaled to zero by its idle policy
+	Hibernated bool `json:"hibernated,omitempty"`
 }
 
 func (s CNGroupsStatus) Synced() bool {
@@ -172,6 +179,17 @@ func (s CNGroupsStatus) Ready() bool {
 	return s.ReadyGroups >= s.DesiredGroups
 }
 
+// Hibernated returns true if some CN groups are hibernated and all the other CN groups are ready
+func (s CNGroupsStatus) Hibernated() bool {
+	hibernated := 0
+	for _, g := range s.Groups {
+		if g.Hibernated {
+			hibernated++
+		}
+	}
+	return hibernated > 0 && s.ReadyGroups+hibernated >= s.DesiredGroups
+}
+
 // +kubebuilder:object:root=true
 
 // A MatrixOneCluster is a resource that represents a MatrixOne Cluster
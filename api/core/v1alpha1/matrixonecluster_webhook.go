@@ -72,6 +72,9 @@ func (r *MatrixOneCluster) ValidateCreate() (admission.Warnings, error) {
 	}
 	errs = append(errs, r.validateMutateCommon()...)
 	errs = append(errs, r.Spec.LogService.ValidateCreate(LogSetKey(r))...)
+	if r.Spec.RestoreFrom != nil {
+		errs = append(errs, validateRestoreFrom(*r.Spec.RestoreFrom, r.Namespace, field.NewPath("spec").Child("restoreFrom"))...)
+	}
 	return nil, invalidOrNil(errs, r)
 }
 
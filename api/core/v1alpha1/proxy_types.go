@@ -33,6 +33,13 @@ type ProxySetSpec struct {
 	// reconciling will fail if the node port is not available.
 	// +optional
 	NodePort *int32 `json:"nodePort,omitempty"`
+
+	// WakeUpCNSets makes the proxy consult the proxy plugin served by the operator before routing a connection,
+	// which wakes up the hibernated CNSets that can serve the connection and holds the connection until they are
+	// ready. It takes effect only if the proxy plugin is enabled in the operator, and connections cannot be
+	// routed while the operator is unavailable once it is enabled.
+	// +optional
+	WakeUpCNSets bool `json:"wakeUpCNSets,omitempty"`
 }
 
 type ProxySetStatus struct {
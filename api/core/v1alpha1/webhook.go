@@ -42,6 +42,18 @@ func RegisterWebhooks(mgr ctrl.Manager) error {
 	if err := (&WebUI{}).setupWebhookWithManager(mgr); err != nil {
 		return err
 	}
+	if err := (&BackupJob{}).setupWebhookWithManager(mgr); err != nil {
+		return err
+	}
+	if err := (&BackupSchedule{}).setupWebhookWithManager(mgr); err != nil {
+		return err
+	}
+	if err := (&RestoreJob{}).setupWebhookWithManager(mgr); err != nil {
+		return err
+	}
+	if err := (&Backup{}).setupWebhookWithManager(mgr); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -93,6 +105,23 @@ func validateGoMemLimitPercent(memPercent *int, path *field.Path) field.ErrorLis
 	return errs
 }
 
+func validateStartupPolicy(p *StartupPolicy, path *field.Path) field.ErrorList {
+	if p == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	if p.DNSWaitTimeout != nil && p.DNSWaitTimeout.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("dnsWaitTimeout"), p.DNSWaitTimeout, "dnsWaitTimeout must be positive"))
+	}
+	if p.DNSWaitPeriod != nil && p.DNSWaitPeriod.Duration <= 0 {
+		errs = append(errs, field.Invalid(path.Child("dnsWaitPeriod"), p.DNSWaitPeriod, "dnsWaitPeriod must be positive"))
+	}
+	if p.GetDNSWaitPeriod() > p.GetDNSWaitTimeout() {
+		errs = append(errs, field.Invalid(path.Child("dnsWaitPeriod"), p.DNSWaitPeriod, "dnsWaitPeriod must not be larger than dnsWaitTimeout"))
+	}
+	return errs
+}
+
 func defaultDiskCacheSize(total *resource.Quantity) *resource.Quantity {
 	// shrink the total size since a small amount of space will be used for filesystem and metadata
 	shrunk := total.Value() * 9 / 10
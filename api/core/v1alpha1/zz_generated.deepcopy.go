@@ -32,6 +32,7 @@ func (in *Backup) DeepCopyInto(out *Backup) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Meta.DeepCopyInto(&out.Meta)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Backup.
@@ -121,6 +122,16 @@ func (in *BackupJobSpec) DeepCopyInto(out *BackupJobSpec) {
 	}
 	in.Source.DeepCopyInto(&out.Source)
 	in.Target.DeepCopyInto(&out.Target)
+	if in.RestoreNamespaces != nil {
+		in, out := &in.RestoreNamespaces, &out.RestoreNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
 	if in.Overlay != nil {
 		in, out := &in.Overlay, &out.Overlay
 		*out = new(Overlay)
@@ -196,7 +207,26 @@ func (in *BackupMeta) DeepCopyInto(out *BackupMeta) {
 		*out = &x
 	}
 	in.AtTime.DeepCopyInto(&out.AtTime)
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
 	in.CompleteTime.DeepCopyInto(&out.CompleteTime)
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Throughput != nil {
+		in, out := &in.Throughput, &out.Throughput
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Chain != nil {
+		in, out := &in.Chain, &out.Chain
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupMeta.
@@ -209,6 +239,174 @@ func (in *BackupMeta) DeepCopy() *BackupMeta {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRetentionPolicy) DeepCopyInto(out *BackupRetentionPolicy) {
+	*out = *in
+	if in.KeepLast != nil {
+		in, out := &in.KeepLast, &out.KeepLast
+		*out = new(int32)
+		**out = **in
+	}
+	if in.KeepFor != nil {
+		in, out := &in.KeepFor, &out.KeepFor
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.KeepDaily != nil {
+		in, out := &in.KeepDaily, &out.KeepDaily
+		*out = new(int32)
+		**out = **in
+	}
+	if in.KeepWeekly != nil {
+		in, out := &in.KeepWeekly, &out.KeepWeekly
+		*out = new(int32)
+		**out = **in
+	}
+	if in.KeepMonthly != nil {
+		in, out := &in.KeepMonthly, &out.KeepMonthly
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRetentionPolicy.
+func (in *BackupRetentionPolicy) DeepCopy() *BackupRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupSchedule) DeepCopyInto(out *BackupSchedule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupSchedule.
+func (in *BackupSchedule) DeepCopy() *BackupSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BackupSchedule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupScheduleList) DeepCopyInto(out *BackupScheduleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BackupSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupScheduleList.
+func (in *BackupScheduleList) DeepCopy() *BackupScheduleList {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupScheduleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BackupScheduleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupScheduleSpec) DeepCopyInto(out *BackupScheduleSpec) {
+	*out = *in
+	if in.Suspend != nil {
+		in, out := &in.Suspend, &out.Suspend
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SuccessfulJobsHistoryLimit != nil {
+		in, out := &in.SuccessfulJobsHistoryLimit, &out.SuccessfulJobsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedJobsHistoryLimit != nil {
+		in, out := &in.FailedJobsHistoryLimit, &out.FailedJobsHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(BackupRetentionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	in.BackupTemplate.DeepCopyInto(&out.BackupTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupScheduleSpec.
+func (in *BackupScheduleSpec) DeepCopy() *BackupScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupScheduleStatus) DeepCopyInto(out *BackupScheduleStatus) {
+	*out = *in
+	in.ConditionalStatus.DeepCopyInto(&out.ConditionalStatus)
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupScheduleStatus.
+func (in *BackupScheduleStatus) DeepCopy() *BackupScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupSource) DeepCopyInto(out *BackupSource) {
 	*out = *in
@@ -239,6 +437,122 @@ func (in *BackupSource) DeepCopy() *BackupSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupStatus) DeepCopyInto(out *BackupStatus) {
+	*out = *in
+	in.ConditionalStatus.DeepCopyInto(&out.ConditionalStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupStatus.
+func (in *BackupStatus) DeepCopy() *BackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupVerification) DeepCopyInto(out *BackupVerification) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupVerification.
+func (in *BackupVerification) DeepCopy() *BackupVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BackupVerification) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupVerificationList) DeepCopyInto(out *BackupVerificationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BackupVerification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupVerificationList.
+func (in *BackupVerificationList) DeepCopy() *BackupVerificationList {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupVerificationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BackupVerificationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupVerificationSpec) DeepCopyInto(out *BackupVerificationSpec) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Overlay != nil {
+		in, out := &in.Overlay, &out.Overlay
+		*out = new(Overlay)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupVerificationSpec.
+func (in *BackupVerificationSpec) DeepCopy() *BackupVerificationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupVerificationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupVerificationStatus) DeepCopyInto(out *BackupVerificationStatus) {
+	*out = *in
+	in.ConditionalStatus.DeepCopyInto(&out.ConditionalStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupVerificationStatus.
+func (in *BackupVerificationStatus) DeepCopy() *BackupVerificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupVerificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BucketClaim) DeepCopyInto(out *BucketClaim) {
 	*out = *in
@@ -295,42 +609,143 @@ func (in *BucketClaimList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
-	return nil
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketClaimSpec) DeepCopyInto(out *BucketClaimSpec) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3Provider)
+		(*in).DeepCopyInto(*out)
+	}
+	in.LogSetTemplate.DeepCopyInto(&out.LogSetTemplate)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketClaimSpec.
+func (in *BucketClaimSpec) DeepCopy() *BucketClaimSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketClaimSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketClaimStatus) DeepCopyInto(out *BucketClaimStatus) {
+	*out = *in
+	in.ConditionalStatus.DeepCopyInto(&out.ConditionalStatus)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketClaimStatus.
+func (in *BucketClaimStatus) DeepCopy() *BucketClaimStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketClaimStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CNAutoscaling) DeepCopyInto(out *CNAutoscaling) {
+	*out = *in
+	if in.TargetConnections != nil {
+		in, out := &in.TargetConnections, &out.TargetConnections
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUPercent != nil {
+		in, out := &in.TargetCPUPercent, &out.TargetCPUPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetQPS != nil {
+		in, out := &in.TargetQPS, &out.TargetQPS
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SyncPeriod != nil {
+		in, out := &in.SyncPeriod, &out.SyncPeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ScaleUpStabilizationWindow != nil {
+		in, out := &in.ScaleUpStabilizationWindow, &out.ScaleUpStabilizationWindow
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ScaleDownStabilizationWindow != nil {
+		in, out := &in.ScaleDownStabilizationWindow, &out.ScaleDownStabilizationWindow
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ScaleUpCooldown != nil {
+		in, out := &in.ScaleUpCooldown, &out.ScaleUpCooldown
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ScaleDownCooldown != nil {
+		in, out := &in.ScaleDownCooldown, &out.ScaleDownCooldown
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNAutoscaling.
+func (in *CNAutoscaling) DeepCopy() *CNAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(CNAutoscaling)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BucketClaimSpec) DeepCopyInto(out *BucketClaimSpec) {
+func (in *CNAutoscalingRecommendation) DeepCopyInto(out *CNAutoscalingRecommendation) {
 	*out = *in
-	if in.S3 != nil {
-		in, out := &in.S3, &out.S3
-		*out = new(S3Provider)
-		(*in).DeepCopyInto(*out)
-	}
-	in.LogSetTemplate.DeepCopyInto(&out.LogSetTemplate)
+	in.Time.DeepCopyInto(&out.Time)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketClaimSpec.
-func (in *BucketClaimSpec) DeepCopy() *BucketClaimSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNAutoscalingRecommendation.
+func (in *CNAutoscalingRecommendation) DeepCopy() *CNAutoscalingRecommendation {
 	if in == nil {
 		return nil
 	}
-	out := new(BucketClaimSpec)
+	out := new(CNAutoscalingRecommendation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *BucketClaimStatus) DeepCopyInto(out *BucketClaimStatus) {
+func (in *CNAutoscalingStatus) DeepCopyInto(out *CNAutoscalingStatus) {
 	*out = *in
-	in.ConditionalStatus.DeepCopyInto(&out.ConditionalStatus)
+	if in.LastSampleTime != nil {
+		in, out := &in.LastSampleTime, &out.LastSampleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastScaleTime != nil {
+		in, out := &in.LastScaleTime, &out.LastScaleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Recommendations != nil {
+		in, out := &in.Recommendations, &out.Recommendations
+		*out = make([]CNAutoscalingRecommendation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketClaimStatus.
-func (in *BucketClaimStatus) DeepCopy() *BucketClaimStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNAutoscalingStatus.
+func (in *CNAutoscalingStatus) DeepCopy() *CNAutoscalingStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(BucketClaimStatus)
+	out := new(CNAutoscalingStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -386,6 +801,45 @@ func (in *CNGroupsStatus) DeepCopy() *CNGroupsStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CNHibernationStatus) DeepCopyInto(out *CNHibernationStatus) {
+	*out = *in
+	if in.IdleSince != nil {
+		in, out := &in.IdleSince, &out.IdleSince
+		*out = (*in).DeepCopy()
+	}
+	if in.HibernatedTime != nil {
+		in, out := &in.HibernatedTime, &out.HibernatedTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNHibernationStatus.
+func (in *CNHibernationStatus) DeepCopy() *CNHibernationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CNHibernationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CNIdlePolicy) DeepCopyInto(out *CNIdlePolicy) {
+	*out = *in
+	out.IdleTimeout = in.IdleTimeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNIdlePolicy.
+func (in *CNIdlePolicy) DeepCopy() *CNIdlePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CNIdlePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CNLabel) DeepCopyInto(out *CNLabel) {
 	*out = *in
@@ -523,6 +977,31 @@ func (in *CNSetSpec) DeepCopyInto(out *CNSetSpec) {
 		**out = **in
 	}
 	in.UpdateStrategy.DeepCopyInto(&out.UpdateStrategy)
+	if in.StartupPolicy != nil {
+		in, out := &in.StartupPolicy, &out.StartupPolicy
+		*out = new(StartupPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(CNAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IdlePolicy != nil {
+		in, out := &in.IdlePolicy, &out.IdlePolicy
+		*out = new(CNIdlePolicy)
+		**out = **in
+	}
+	if in.ScaleInStores != nil {
+		in, out := &in.ScaleInStores, &out.ScaleInStores
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CordonedStores != nil {
+		in, out := &in.CordonedStores, &out.CordonedStores
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNSetSpec.
@@ -542,7 +1021,19 @@ func (in *CNSetStatus) DeepCopyInto(out *CNSetStatus) {
 	if in.Stores != nil {
 		in, out := &in.Stores, &out.Stores
 		*out = make([]CNStore, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(CNAutoscalingStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hibernation != nil {
+		in, out := &in.Hibernation, &out.Hibernation
+		*out = new(CNHibernationStatus)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -559,6 +1050,16 @@ func (in *CNSetStatus) DeepCopy() *CNSetStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CNStore) DeepCopyInto(out *CNStore) {
 	*out = *in
+	if in.Sessions != nil {
+		in, out := &in.Sessions, &out.Sessions
+		*out = new(int)
+		**out = **in
+	}
+	if in.Draining != nil {
+		in, out := &in.Draining, &out.Draining
+		*out = new(CNStoreDrainingProgress)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNStore.
@@ -571,6 +1072,22 @@ func (in *CNStore) DeepCopy() *CNStore {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CNStoreDrainingProgress) DeepCopyInto(out *CNStoreDrainingProgress) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNStoreDrainingProgress.
+func (in *CNStoreDrainingProgress) DeepCopy() *CNStoreDrainingProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(CNStoreDrainingProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterMetrics) DeepCopyInto(out *ClusterMetrics) {
 	*out = *in
@@ -699,6 +1216,11 @@ func (in *DNSetSpec) DeepCopyInto(out *DNSetSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.SharedStorageCache.DeepCopyInto(&out.SharedStorageCache)
+	if in.StartupPolicy != nil {
+		in, out := &in.StartupPolicy, &out.StartupPolicy
+		*out = new(StartupPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNSetSpec.
@@ -728,6 +1250,21 @@ func (in *DNSetStatus) DeepCopy() *DNSetStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DNShardStatus) DeepCopyInto(out *DNShardStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DNShardStatus.
+func (in *DNShardStatus) DeepCopy() *DNShardStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DNShardStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DefaultArgs) DeepCopyInto(out *DefaultArgs) {
 	*out = *in
@@ -810,6 +1347,16 @@ func (in *FailoverStatus) DeepCopy() *FailoverStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FileSystemProvider) DeepCopyInto(out *FileSystemProvider) {
 	*out = *in
+	if in.PersistentVolumeClaim != nil {
+		in, out := &in.PersistentVolumeClaim, &out.PersistentVolumeClaim
+		*out = new(corev1.PersistentVolumeClaimVolumeSource)
+		**out = **in
+	}
+	if in.HostPath != nil {
+		in, out := &in.HostPath, &out.HostPath
+		*out = new(corev1.HostPathVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileSystemProvider.
@@ -931,6 +1478,62 @@ func (in *LogSetList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogSetReBootstrapRecord) DeepCopyInto(out *LogSetReBootstrapRecord) {
+	*out = *in
+	if in.Previous != nil {
+		in, out := &in.Previous, &out.Previous
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogSetReBootstrapRecord.
+func (in *LogSetReBootstrapRecord) DeepCopy() *LogSetReBootstrapRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(LogSetReBootstrapRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogSetRecoveryStatus) DeepCopyInto(out *LogSetRecoveryStatus) {
+	*out = *in
+	if in.Survivors != nil {
+		in, out := &in.Survivors, &out.Survivors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogSetRecoveryStatus.
+func (in *LogSetRecoveryStatus) DeepCopy() *LogSetRecoveryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LogSetRecoveryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogSetRef) DeepCopyInto(out *LogSetRef) {
 	*out = *in
@@ -956,6 +1559,27 @@ func (in *LogSetRef) DeepCopy() *LogSetRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogSetScaleInStatus) DeepCopyInto(out *LogSetScaleInStatus) {
+	*out = *in
+	if in.PendingReplicas != nil {
+		in, out := &in.PendingReplicas, &out.PendingReplicas
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.StartTime.DeepCopyInto(&out.StartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogSetScaleInStatus.
+func (in *LogSetScaleInStatus) DeepCopy() *LogSetScaleInStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LogSetScaleInStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogSetSpec) DeepCopyInto(out *LogSetSpec) {
 	*out = *in
@@ -978,6 +1602,11 @@ func (in *LogSetSpec) DeepCopyInto(out *LogSetSpec) {
 		*out = new(PVCRetentionPolicy)
 		**out = **in
 	}
+	if in.StartupPolicy != nil {
+		in, out := &in.StartupPolicy, &out.StartupPolicy
+		*out = new(StartupPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogSetSpec.
@@ -1000,6 +1629,42 @@ func (in *LogSetStatus) DeepCopyInto(out *LogSetStatus) {
 		*out = new(LogSetDiscovery)
 		**out = **in
 	}
+	if in.ScaleIn != nil {
+		in, out := &in.ScaleIn, &out.ScaleIn
+		*out = new(LogSetScaleInStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Stores != nil {
+		in, out := &in.Stores, &out.Stores
+		*out = make([]LogStoreStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Recovery != nil {
+		in, out := &in.Recovery, &out.Recovery
+		*out = new(LogSetRecoveryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LogShards != nil {
+		in, out := &in.LogShards, &out.LogShards
+		*out = make([]LogShardStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DNShards != nil {
+		in, out := &in.DNShards, &out.DNShards
+		*out = make([]DNShardStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReBootstraps != nil {
+		in, out := &in.ReBootstraps, &out.ReBootstraps
+		*out = make([]LogSetReBootstrapRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogSetStatus.
@@ -1012,6 +1677,66 @@ func (in *LogSetStatus) DeepCopy() *LogSetStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogShardReplicaStatus) DeepCopyInto(out *LogShardReplicaStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogShardReplicaStatus.
+func (in *LogShardReplicaStatus) DeepCopy() *LogShardReplicaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LogShardReplicaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogShardStatus) DeepCopyInto(out *LogShardStatus) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make([]LogShardReplicaStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogShardStatus.
+func (in *LogShardStatus) DeepCopy() *LogShardStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LogShardStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogStoreStatus) DeepCopyInto(out *LogStoreStatus) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LeaderShards != nil {
+		in, out := &in.LeaderShards, &out.LeaderShards
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogStoreStatus.
+func (in *LogStoreStatus) DeepCopy() *LogStoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LogStoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MainContainer) DeepCopyInto(out *MainContainer) {
 	*out = *in
@@ -1221,6 +1946,11 @@ func (in *MatrixOneClusterSpec) DeepCopyInto(out *MatrixOneClusterSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.BackupRetention != nil {
+		in, out := &in.BackupRetention, &out.BackupRetention
+		*out = new(BackupRetentionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixOneClusterSpec.
@@ -1662,7 +2392,21 @@ func (in *RestoreJobSpec) DeepCopyInto(out *RestoreJobSpec) {
 		*out = new(SharedStorageProvider)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.LatestBackupBefore != nil {
+		in, out := &in.LatestBackupBefore, &out.LatestBackupBefore
+		*out = (*in).DeepCopy()
+	}
 	in.Target.DeepCopyInto(&out.Target)
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Overlay != nil {
+		in, out := &in.Overlay, &out.Overlay
+		*out = new(Overlay)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreJobSpec.
@@ -1679,6 +2423,10 @@ func (in *RestoreJobSpec) DeepCopy() *RestoreJobSpec {
 func (in *RestoreJobStatus) DeepCopyInto(out *RestoreJobStatus) {
 	*out = *in
 	in.ConditionalStatus.DeepCopyInto(&out.ConditionalStatus)
+	if in.RestoredTime != nil {
+		in, out := &in.RestoredTime, &out.RestoredTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreJobStatus.
@@ -1807,7 +2555,7 @@ func (in *SharedStorageProvider) DeepCopyInto(out *SharedStorageProvider) {
 	if in.FileSystem != nil {
 		in, out := &in.FileSystem, &out.FileSystem
 		*out = new(FileSystemProvider)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -1821,6 +2569,31 @@ func (in *SharedStorageProvider) DeepCopy() *SharedStorageProvider {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StartupPolicy) DeepCopyInto(out *StartupPolicy) {
+	*out = *in
+	if in.DNSWaitTimeout != nil {
+		in, out := &in.DNSWaitTimeout, &out.DNSWaitTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DNSWaitPeriod != nil {
+		in, out := &in.DNSWaitPeriod, &out.DNSWaitPeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupPolicy.
+func (in *StartupPolicy) DeepCopy() *StartupPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(StartupPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Store) DeepCopyInto(out *Store) {
 	*out = *in
@@ -0,0 +1,115 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/matrixorigin/matrixone-operator/pkg/moinit"
+	"github.com/pkg/errors"
+)
+
+const installCmd = "install"
+
+// mo-init renders the per-pod config of a MO service and then starts the service with the rendered config:
+//
+//	mo-init [flags] -- /mo-service [service args]
+//
+// Since mo-init is not shipped with the MO image, it is copied to a volume shared with the MO container
+// by an init container first:
+//
+//	mo-init install <dir>
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == installCmd {
+		if len(os.Args) != 3 {
+			exitIf(errors.New("usage: mo-init install <dir>"))
+		}
+		exitIf(install(os.Args[2]))
+		return
+	}
+
+	opts := &moinit.Options{}
+	var output string
+	fs := flag.NewFlagSet("mo-init", flag.ExitOnError)
+	opts.BindFlags(fs)
+	fs.StringVar(&output, "output", "", "path of the rendered config, a temp file is created if empty")
+	exitIf(fs.Parse(os.Args[1:]))
+	service := fs.Args()
+	if len(service) == 0 {
+		exitIf(errors.New("the command of the MO service is required"))
+	}
+
+	env := moinit.EnvFromOS()
+	conf, err := moinit.Render(opts, env, os.ReadFile)
+	exitIf(err)
+	s, err := conf.ToString()
+	exitIf(err)
+	if output == "" {
+		f, err := os.CreateTemp("", "mo-config-*.toml")
+		exitIf(err)
+		exitIf(f.Close())
+		output = f.Name()
+	}
+	exitIf(os.WriteFile(output, []byte(s), 0644))
+
+	if opts.WaitDNS {
+		addr := env.Addr()
+		fmt.Fprintf(os.Stderr, "waiting pod dns name %s resolvable\n", addr)
+		exitIf(moinit.WaitDNS(context.Background(), addr, opts.DNSTimeout, opts.DNSPeriod, func(ctx context.Context, host string) error {
+			_, err := net.DefaultResolver.LookupHost(ctx, host)
+			return err
+		}))
+	}
+
+	argv := append([]string{service[0], "-cfg", output}, service[1:]...)
+	fmt.Fprintln(os.Stderr, argv)
+	exitIf(syscall.Exec(service[0], argv, os.Environ()))
+}
+
+// install copies the mo-init binary to the given directory
+func install(dir string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	src, err := os.Open(self)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(filepath.Join(dir, "mo-init"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+func exitIf(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "mo-init:", err)
+		os.Exit(1)
+	}
+}
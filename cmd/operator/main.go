@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"github.com/go-logr/zapr"
@@ -107,6 +108,7 @@ func main() {
 
 	err = features.DefaultMutableFeatureGate.SetFromMap(operatorCfg.FeatureGates)
 	exitIf(err, "failed to set feature gate")
+	common.MOInitImage = operatorCfg.MOInit.Image
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
@@ -146,7 +148,8 @@ func main() {
 		exitIf(err, "unable to setup validating webhook controller")
 	}
 
-	logSetActor := &logset.Actor{FailoverEnabled: failover}
+	haCliMgr := hacli.NewManager(mgr.GetClient(), mgr.GetLogger())
+	logSetActor := &logset.Actor{FailoverEnabled: failover, HAKeeper: haCliMgr}
 	err = logSetActor.Reconcile(mgr)
 	exitIf(err, "unable to set up log service controller")
 
@@ -154,7 +157,10 @@ func main() {
 	err = dnSetActor.Reconcile(mgr)
 	exitIf(err, "unable to set up dn service controller")
 
-	cnSetActor := &cnset.Actor{}
+	qc, err := querycli.New(zapLogger)
+	exitIf(err, "unable to create query client")
+
+	cnSetActor := &cnset.Actor{QueryCli: qc, HAKeeper: haCliMgr}
 	err = cnSetActor.Reconcile(mgr)
 	exitIf(err, "unable to setup  cn service controller")
 
@@ -167,7 +173,14 @@ func main() {
 	exitIf(err, "unable to set up matrixone cluster controller")
 
 	if features.DefaultFeatureGate.Enabled(features.BRSupport) {
-		backupActor := br.NewBackupActor(operatorCfg.BRConfig.Image)
+		controllermetrics.Registry.MustRegister(br.Collectors()...)
+
+		err = br.SetupBackupIndexer(context.Background(), mgr)
+		exitIf(err, "unable to setup backup indexer")
+		err = br.SetupBackupRefIndexer(context.Background(), mgr)
+		exitIf(err, "unable to setup backup reference indexer")
+
+		backupActor := br.NewBackupActor(operatorCfg.BRConfig.Image, operatorCfg.BRConfig.CleanupImage)
 		err = backupActor.Reconcile(mgr)
 		exitIf(err, "unable to setup backup actor")
 
@@ -175,6 +188,22 @@ func main() {
 		err = restoreActor.Reconcile(mgr)
 		exitIf(err, "unable to setup restore actor")
 
+		scheduleActor := br.NewScheduleActor()
+		err = scheduleActor.Reconcile(mgr)
+		exitIf(err, "unable to setup backup schedule actor")
+
+		backupDataActor := br.NewBackupDataActor(operatorCfg.BRConfig.Image)
+		err = backupDataActor.Reconcile(mgr)
+		exitIf(err, "unable to setup backup data actor")
+
+		retentionActor := &br.ClusterRetentionActor{}
+		err = retentionActor.Reconcile(mgr)
+		exitIf(err, "unable to setup backup retention actor")
+
+		verifyActor := br.NewVerifyActor(operatorCfg.BRConfig.Image)
+		err = verifyActor.Reconcile(mgr)
+		exitIf(err, "unable to setup backup verify actor")
+
 		backupGC := &br.GCActor[*v1alpha1.BackupJob]{
 			ConditionType: v1alpha1.JobConditionTypeEnded,
 		}
@@ -186,10 +215,27 @@ func main() {
 		}
 		err = br.StartJobGCer(mgr, restoreGC, &v1alpha1.RestoreJob{})
 		exitIf(err, "unable to setup restore GCer")
+
+		verifyGC := &br.GCActor[*v1alpha1.BackupVerification]{
+			ConditionType: v1alpha1.JobConditionTypeEnded,
+		}
+		err = br.StartJobGCer(mgr, verifyGC, &v1alpha1.BackupVerification{})
+		exitIf(err, "unable to setup backup verification GCer")
 	}
 
 	if features.DefaultFeatureGate.Enabled(features.ProxySupport) {
 		proxyActor := &proxyset.Actor{}
+		if pc := operatorCfg.ProxyPlugin; pc.ListenAddress != "" {
+			err = proxyset.SetupPodIPIndexer(context.Background(), mgr)
+			exitIf(err, "unable to set up pod IP indexer")
+			err = mgr.Add(&proxyset.WakeUpPlugin{
+				ListenAddress: pc.ListenAddress,
+				Client:        mgr.GetClient(),
+				Logger:        mgr.GetLogger().WithName("proxy-plugin"),
+			})
+			exitIf(err, "unable to set up proxy plugin")
+			proxyActor.PluginBackend = pc.Backend
+		}
 		err = proxyActor.Reconcile(mgr)
 		exitIf(err, "unable to set up proxyset controller")
 	} else {
@@ -204,10 +250,8 @@ func main() {
 		setupLog.Info(fmt.Sprintf("s3 reclaim feature not enabled, skip setup bucketclaim actor"))
 	}
 
-	qc, err := querycli.New(zapLogger)
-	exitIf(err, "unable to create query client")
 	if features.DefaultFeatureGate.Enabled(features.CNLabel) {
-		cnLabelController := cnstore.NewController(hacli.NewManager(mgr.GetClient(), mgr.GetLogger()), qc)
+		cnLabelController := cnstore.NewController(haCliMgr, qc)
 		err = cnLabelController.Reconcile(mgr)
 		exitIf(err, "unable to set up cnlabel controller")
 	} else {
@@ -37,14 +37,23 @@ import (
 
 const (
 	pollInterval = 15 * time.Second
+
+	// finalizerWaitTimeout is the time to wait the finalizer of a Backup to be added before the Backup is
+	// considered not finalizable
+	finalizerWaitTimeout = time.Minute
 )
 
 type BackupActor struct {
-	backupImage string
+	backupImage  string
+	cleanupImage string
 }
 
-func NewBackupActor(image string) *BackupActor {
-	return &BackupActor{backupImage: image}
+// NewBackupActor builds the actor of BackupJob, the default cleanup image is used if cleanupImage is empty
+func NewBackupActor(image, cleanupImage string) *BackupActor {
+	if cleanupImage == "" {
+		cleanupImage = defaultCleanupImage
+	}
+	return &BackupActor{backupImage: image, cleanupImage: cleanupImage}
 }
 
 var _ recon.Actor[*v1alpha1.BackupJob] = &BackupActor{}
@@ -56,6 +65,9 @@ func (c *BackupActor) Observe(ctx *recon.Context[*v1alpha1.BackupJob]) (recon.Ac
 		// completed
 		return nil, nil
 	}
+	if cancelRequested(bj, bj.Spec.Suspend) {
+		return c.cancelBackup, nil
+	}
 	if bj.Status.Phase == v1alpha1.JobPhaseRunning {
 		return c.waitJob, nil
 	}
@@ -85,7 +97,7 @@ func (c *BackupActor) waitJob(ctx *recon.Context[*v1alpha1.BackupJob]) error {
 		return errors.Wrap(err, "error get backup job")
 	}
 	if job.Status.Failed > 0 {
-		return c.failBackup(ctx, "backup job is failed")
+		return c.retryOrFail(ctx, "backup job is failed")
 	}
 
 	svc := buildSvc(bj)
@@ -103,39 +115,222 @@ func (c *BackupActor) waitJob(ctx *recon.Context[*v1alpha1.BackupJob]) error {
 	}
 	// backup succeed
 	if status.ExitCode == 0 {
-		parts := strings.Split(status.Stdout, MetaDelimiter)
-		if len(parts) != 2 {
-			return errors.Errorf("error parse backup id from stdout: %s", status.Stdout)
+		backup, created, err := c.recordBackup(ctx, status.Stdout)
+		if err != nil {
+			return err
+		}
+		if created {
+			observeBackupSucceeded(backup)
 		}
-		raw := strings.Trim(strings.Trim(parts[1], " "), "\n")
-		id := strings.SplitN(raw, ",", 2)[0]
+		return c.completeBackup(ctx, backup)
+	}
 
-		// 1. ensure backup
-		backup := &v1alpha1.Backup{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: fmt.Sprintf("%s-%s", bj.Name, id[:5]),
-			},
-			Meta: v1alpha1.BackupMeta{
-				Location: bj.Spec.Target,
-				ID:       id,
-				// TODO: get AtTime from backup Meta
-				AtTime:       metav1.Now(),
-				CompleteTime: metav1.Now(),
-				SourceRef:    bj.GetSourceRef(),
-				Raw:          raw,
-			},
+	return c.retryOrFail(ctx, status.Stderr)
+}
+
+// recordBackup creates the Backup from the output of the backup command
+func (c *BackupActor) recordBackup(ctx *recon.Context[*v1alpha1.BackupJob], stdout string) (*v1alpha1.Backup, bool, error) {
+	bj := ctx.Obj
+	raw, moMeta, err := backupMetaFromStdout(stdout)
+	if err != nil {
+		return nil, false, err
+	}
+	id := moMeta.ID
+
+	mode := v1alpha1.BackupModeFull
+	var chain []string
+	if bj.Status.ParentBackup != "" {
+		parent := &v1alpha1.Backup{}
+		if err := ctx.Get(types.NamespacedName{Name: bj.Status.ParentBackup}, parent); err != nil {
+			return nil, false, errors.Wrap(err, "error get parent backup")
 		}
-		if err := util.Ignore(apierrors.IsAlreadyExists, ctx.Create(backup)); err != nil {
-			return errors.Wrap(err, "error ensure backup")
+		mode = v1alpha1.BackupModeIncremental
+		chain = append(append([]string{}, parent.Meta.Chain...), parent.Name)
+	}
+
+	labels := map[string]string{
+		common.PreNameLabelKey:   bj.Name,
+		common.PreUUIDLabelKey:   string(bj.UID),
+		common.NamespaceLabelKey: bj.Namespace,
+	}
+	if schedule, ok := bj.Labels[BackupScheduleLabelKey]; ok {
+		labels[BackupScheduleLabelKey] = schedule
+	}
+	version, err := c.sourceVersion(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	for k, v := range catalogLabels(bj, version) {
+		labels[k] = v
+	}
+	var annotations map[string]string
+	if len(bj.Spec.RestoreNamespaces) > 0 {
+		annotations = map[string]string{
+			v1alpha1.BackupRestoreNamespacesAnnoKey: strings.Join(bj.Spec.RestoreNamespaces, ","),
 		}
-		return c.completeBackup(ctx, backup)
 	}
+	backup := &v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-%s", bj.Name, id[:5]),
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Meta: v1alpha1.BackupMeta{
+			Location:  backupLocation(bj),
+			ID:        id,
+			SourceRef: bj.GetSourceRef(),
+			MOVersion: version,
+			Mode:      mode,
+			Chain:     chain,
+			Raw:       raw,
+		},
+	}
+	moMeta.applyTo(&backup.Meta, time.Now())
+	err = ctx.Create(backup)
+	if err := util.Ignore(apierrors.IsAlreadyExists, err); err != nil {
+		return nil, false, errors.Wrap(err, "error ensure backup")
+	}
+	return backup, err == nil, nil
+}
 
-	return c.failBackup(ctx, status.Stderr)
+// sourceVersion returns the MO version of the backup source, empty string is returned if the source is gone
+func (c *BackupActor) sourceVersion(ctx *recon.Context[*v1alpha1.BackupJob]) (string, error) {
+	bj := ctx.Obj
+	var err error
+	switch {
+	case bj.Spec.Source.ClusterRef != nil:
+		mo := &v1alpha1.MatrixOneCluster{}
+		if err = ctx.Get(types.NamespacedName{Namespace: bj.Namespace, Name: *bj.Spec.Source.ClusterRef}, mo); err == nil {
+			return mo.Spec.Version, nil
+		}
+	case bj.Spec.Source.CNSetRef != nil:
+		cn := &v1alpha1.CNSet{}
+		if err = ctx.Get(types.NamespacedName{Namespace: bj.Namespace, Name: *bj.Spec.Source.CNSetRef}, cn); err == nil {
+			return imageTag(cn.Spec.Image), nil
+		}
+	}
+	return "", errors.Wrap(util.Ignore(apierrors.IsNotFound, err), "error get backup source")
+}
+
+// retryOrFail re-creates the backup job if the backoffLimit is not exceeded, otherwise the backup is failed
+func (c *BackupActor) retryOrFail(ctx *recon.Context[*v1alpha1.BackupJob], message string) error {
+	bj := ctx.Obj
+	if !shouldRetry(bj.Spec.BackoffLimit, bj.Status.Retries) {
+		return c.failBackup(ctx, message)
+	}
+	if err := deleteJob(ctx, bj); err != nil {
+		return err
+	}
+	bj.Status.Retries++
+	ctx.Event.EmitEventGeneric("JobRetrying", fmt.Sprintf("retry backup (%d/%d): %s", bj.Status.Retries, *bj.Spec.BackoffLimit, message), nil)
+	bj.Status.Phase = v1alpha1.JobPhasePending
+	meta.SetStatusCondition(&bj.Status.Conditions, metav1.Condition{
+		Type:    v1alpha1.JobConditionTypeEnded,
+		Status:  metav1.ConditionFalse,
+		Reason:  "JobRetrying",
+		Message: message,
+	})
+	return ctx.UpdateStatus(bj)
+}
+
+// cancelBackup stops the running backup and cleans the backup data that has been produced
+func (c *BackupActor) cancelBackup(ctx *recon.Context[*v1alpha1.BackupJob]) error {
+	bj := ctx.Obj
+	if bj.Status.Phase == v1alpha1.JobPhaseRunning {
+		if err := c.stopBackup(ctx); err != nil {
+			return err
+		}
+	}
+	backupList := &v1alpha1.BackupList{}
+	if err := ctx.List(backupList, client.MatchingLabels{common.PreUUIDLabelKey: string(bj.UID)}); err != nil {
+		return errors.Wrap(err, "error list owned backups")
+	}
+	for i := range backupList.Items {
+		b := &backupList.Items[i]
+		if b.DeletionTimestamp != nil {
+			continue
+		}
+		if len(b.Finalizers) == 0 {
+			// the data is only cleaned by the finalizer of the Backup, which is added shortly after the Backup
+			// is created if the backup data actor is enabled
+			if time.Since(b.CreationTimestamp.Time) < finalizerWaitTimeout {
+				return recon.ErrReSync(fmt.Sprintf("wait backup %s to be finalizable", b.Name), retryInterval)
+			}
+			ctx.Event.EmitEventGeneric("BackupRetained", fmt.Sprintf("backup %s is retained since its data cannot be cleaned without the finalizer", b.Name), nil)
+			continue
+		}
+		if err := ctx.Patch(b, func() error {
+			if b.Annotations == nil {
+				b.Annotations = map[string]string{}
+			}
+			b.Annotations[BackupExpiredAnnoKey] = "cancelled"
+			return nil
+		}); err != nil {
+			return errors.Wrapf(err, "error expire backup %s", b.Name)
+		}
+		if err := util.Ignore(apierrors.IsNotFound, ctx.Delete(b)); err != nil {
+			return errors.Wrapf(err, "error delete backup %s", b.Name)
+		}
+	}
+	observeBackupCancelled(bj)
+	ctx.Event.EmitEventGeneric("JobCancelled", "backup is cancelled", nil)
+	bj.Status.Phase = v1alpha1.JobPhaseCancelled
+	meta.SetStatusCondition(&bj.Status.Conditions, metav1.Condition{
+		Type:   v1alpha1.JobConditionTypeEnded,
+		Status: metav1.ConditionTrue,
+		Reason: "JobCancelled",
+	})
+	return ctx.UpdateStatus(bj)
+}
+
+// stopBackup stops the running backup, the partial data of a backup that is stopped before completed is cleaned
+// by a cleanup job, and the stop completes after the cleanup job ends
+func (c *BackupActor) stopBackup(ctx *recon.Context[*v1alpha1.BackupJob]) error {
+	bj := ctx.Obj
+	cleanup := &batchv1.Job{}
+	err := ctx.Get(types.NamespacedName{Namespace: bj.Namespace, Name: cleanupJobName(bj)}, cleanup)
+	if err == nil {
+		return waitCleanupJob(ctx, cleanup)
+	}
+	if !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "error get cleanup job")
+	}
+	status := stopCommand(ctx, bj)
+	if status != nil && status.Completed && status.ExitCode == 0 {
+		// the backup has completed before it is stopped, record it so that the data can be
+		// cleaned along with the Backup
+		if _, _, err := c.recordBackup(ctx, status.Stdout); err != nil {
+			return errors.Wrap(err, "error record the backup completed before cancelled")
+		}
+		return deleteJob(ctx, bj)
+	}
+	if err := deleteJob(ctx, bj); err != nil {
+		return err
+	}
+	// mo_br must have exited before the partial data is cleaned
+	if err := waitJobDeleted(ctx, bj); err != nil {
+		return err
+	}
+	if err := waitJobPodsDeleted(ctx, bj); err != nil {
+		return err
+	}
+	job, err := buildCleanupJob(bj, c.cleanupImage)
+	if errors.Is(err, errUnsafeCleanup) {
+		ctx.Event.EmitEventGeneric("CleanupSkipped", fmt.Sprintf("partial backup data is retained: %v", err), nil)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "error build cleanup job")
+	}
+	if err := util.Ignore(apierrors.IsAlreadyExists, ctx.CreateOwned(job)); err != nil {
+		return errors.Wrap(err, "error create cleanup job")
+	}
+	return recon.ErrReSync("wait the partial backup data to be cleaned", retryInterval)
 }
 
 func (c *BackupActor) failBackup(ctx *recon.Context[*v1alpha1.BackupJob], message string) error {
 	// note: when backup failed, we keep the job for troubleshooting
+	observeBackupFailed(ctx.Obj)
 	ctx.Obj.Status.Phase = v1alpha1.JobPhaseFailed
 	meta.SetStatusCondition(&ctx.Obj.Status.Conditions, metav1.Condition{
 		Type:    v1alpha1.JobConditionTypeEnded,
@@ -154,6 +349,22 @@ func (c *BackupActor) completeBackup(ctx *recon.Context[*v1alpha1.BackupJob], ba
 	//)); err != nil {
 	//	return errors.Wrap(err, "error finalize backup job")
 	//}
+	if bj.Spec.Verify {
+		verification := &v1alpha1.BackupVerification{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: bj.Namespace,
+				Name:      fmt.Sprintf("%s-verify", bj.Name),
+			},
+			Spec: v1alpha1.BackupVerificationSpec{
+				TTL:        bj.Spec.TTL,
+				BackupName: backup.Name,
+				Overlay:    bj.Spec.Overlay,
+			},
+		}
+		if err := util.Ignore(apierrors.IsAlreadyExists, ctx.CreateOwned(verification)); err != nil {
+			return errors.Wrap(err, "error create backup verification")
+		}
+	}
 	bj.Status.Backup = backup.Name
 	bj.Status.Phase = v1alpha1.JobPhaseCompleted
 	meta.SetStatusCondition(&bj.Status.Conditions, metav1.Condition{
@@ -169,6 +380,9 @@ func (c *BackupActor) syncJob(ctx *recon.Context[*v1alpha1.BackupJob]) error {
 	if bj.Status.Phase == "" {
 		bj.Status.Phase = v1alpha1.JobPhasePending
 	}
+	if err := waitJobDeleted(ctx, bj); err != nil {
+		return err
+	}
 	var moSecret string
 	backupCmd := &BackupCommand{}
 	if bj.Spec.Source.CNSetRef != nil {
@@ -205,16 +419,27 @@ func (c *BackupActor) syncJob(ctx *recon.Context[*v1alpha1.BackupJob]) error {
 		backupCmd.Port = mo.Status.Port
 		moSecret = mo.Status.CredentialRef.Name
 	}
-	backupCmd.S3.Endpoint = bj.Spec.Target.S3.Endpoint
-	backupCmd.S3.Type = string(bj.Spec.Target.S3.GetProviderType())
-	parts := strings.SplitN(bj.Spec.Target.S3.Path, "/", 2)
-	backupCmd.S3.Bucket = parts[0]
-	if len(parts) > 1 {
-		backupCmd.S3.Path = parts[1]
+	target, err := newStorage(bj.Spec.Target)
+	if err != nil {
+		return errors.Wrap(err, "bad backup target")
+	}
+	// each backup job writes to its own directory so that the partial data can be cleaned on cancel
+	backupCmd.Target = target.Sub(backupDataDir(bj))
+	var optionalS3Secret *corev1.LocalObjectReference
+	if bj.Spec.Target.S3 != nil {
+		optionalS3Secret = bj.Spec.Target.S3.SecretRef
+	}
+	var parent *v1alpha1.Backup
+	if bj.IsIncremental() {
+		p, err := c.resolveParent(ctx)
+		if err != nil {
+			return err
+		}
+		parent = p
 	}
-	optionalS3Secret := bj.Spec.Target.S3.SecretRef
-	if optionalS3Secret != nil {
-		backupCmd.S3.ReadEnvSecret = true
+	if parent != nil {
+		backupCmd.BaseID = parent.Meta.ID
+		bj.Status.ParentBackup = parent.Name
 	}
 	job := buildJob(bj, c.backupImage, backupCmd.String(), func(c *corev1.Container) {
 		c.Env = []corev1.EnvVar{{
@@ -234,6 +459,12 @@ func (c *BackupActor) syncJob(ctx *recon.Context[*v1alpha1.BackupJob]) error {
 				},
 			},
 		}}
+		if parent != nil {
+			c.Env = append(c.Env, corev1.EnvVar{
+				Name:  RawMetaEnv,
+				Value: parent.Meta.Raw,
+			})
+		}
 		if optionalS3Secret != nil {
 			for _, key := range []string{common.AWSAccessKeyID, common.AWSSecretAccessKey} {
 				c.Env = util.UpsertByKey(c.Env, corev1.EnvVar{Name: key, ValueFrom: &corev1.EnvVarSource{
@@ -245,6 +476,7 @@ func (c *BackupActor) syncJob(ctx *recon.Context[*v1alpha1.BackupJob]) error {
 			}
 		}
 	})
+	mountStorage(job, bj.Spec.Target, "backup")
 	svc := buildSvc(bj)
 	if err := util.Ignore(apierrors.IsAlreadyExists, ctx.CreateOwned(job)); err != nil {
 		return errors.Wrap(err, "error ensure job")
@@ -262,6 +494,35 @@ func (c *BackupActor) syncJob(ctx *recon.Context[*v1alpha1.BackupJob]) error {
 	return ctx.UpdateStatus(bj)
 }
 
+// resolveParent returns the parent of an incremental backup, nil is returned if the parent
+// is not specified and the source has no backup yet
+func (c *BackupActor) resolveParent(ctx *recon.Context[*v1alpha1.BackupJob]) (*v1alpha1.Backup, error) {
+	bj := ctx.Obj
+	name := bj.Spec.ParentBackup
+	if bj.Status.ParentBackup != "" {
+		name = bj.Status.ParentBackup
+	}
+	if name == "" {
+		backupList := &v1alpha1.BackupList{}
+		if err := ctx.List(backupList, client.MatchingFields{v1alpha1.BackupSourceRefField: bj.GetSourceRef()}); err != nil {
+			return nil, errors.Wrap(err, "error list backups")
+		}
+		parent := latestBackupBefore(backupList.Items, bj.GetSourceRef(), time.Now())
+		if parent == nil {
+			ctx.Event.EmitEventGeneric("FullBackup", "no backup of the source is found, take a full backup instead", nil)
+		}
+		return parent, nil
+	}
+	parent := &v1alpha1.Backup{}
+	if err := ctx.Get(types.NamespacedName{Name: name}, parent); err != nil {
+		return nil, errors.Wrap(err, "error get parent backup")
+	}
+	if parent.DeletionTimestamp != nil {
+		return nil, errors.Errorf("parent backup %s is being deleted", name)
+	}
+	return parent, nil
+}
+
 func (c *BackupActor) Finalize(ctx *recon.Context[*v1alpha1.BackupJob]) (bool, error) {
 	bj := ctx.Obj
 	err := ctx.Delete(&batchv1.Job{ObjectMeta: common.ObjMetaTemplate(bj, bj.Name)}, client.PropagationPolicy(metav1.DeletePropagationBackground))
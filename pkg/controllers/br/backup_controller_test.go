@@ -0,0 +1,200 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/matrixorigin/controller-runtime/pkg/fake"
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newBRScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	utilruntime.Must(v1alpha1.AddToScheme(s))
+	utilruntime.Must(batchv1.AddToScheme(s))
+	return s
+}
+
+func TestBackupActor_retryOrFail(t *testing.T) {
+	tests := []struct {
+		name         string
+		backoffLimit *int32
+		retries      int32
+		event        string
+		wantPhase    string
+		wantRetries  int32
+	}{{
+		name:      "no backoffLimit",
+		wantPhase: v1alpha1.JobPhaseFailed,
+	}, {
+		name:         "within backoffLimit",
+		backoffLimit: pointer.Int32(2),
+		retries:      1,
+		event:        "JobRetrying",
+		wantPhase:    v1alpha1.JobPhasePending,
+		wantRetries:  2,
+	}, {
+		name:         "exceed backoffLimit",
+		backoffLimit: pointer.Int32(2),
+		retries:      2,
+		wantPhase:    v1alpha1.JobPhaseFailed,
+		wantRetries:  2,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			bj := &v1alpha1.BackupJob{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "backup"},
+				Spec:       v1alpha1.BackupJobSpec{BackoffLimit: tt.backoffLimit},
+				Status:     v1alpha1.BackupJobStatus{Retries: tt.retries, Phase: v1alpha1.JobPhaseRunning},
+			}
+			job := &batchv1.Job{ObjectMeta: common.ObjMetaTemplate(bj, bj.Name)}
+			cli := fake.KubeClientBuilder().WithScheme(newBRScheme()).WithObjects(bj, job).WithStatusSubresource(bj).Build()
+			emitter := fake.NewMockEventEmitter(gomock.NewController(t))
+			if tt.event != "" {
+				emitter.EXPECT().EmitEventGeneric(tt.event, gomock.Any(), nil).Times(1)
+			}
+			ctx := fake.NewContext(bj, cli, emitter)
+
+			g.Expect((&BackupActor{}).retryOrFail(ctx, "mo_br exited with 1")).To(Succeed())
+			g.Expect(bj.Status.Phase).To(Equal(tt.wantPhase))
+			g.Expect(bj.Status.Retries).To(Equal(tt.wantRetries))
+			cond := meta.FindStatusCondition(bj.Status.Conditions, v1alpha1.JobConditionTypeEnded)
+			g.Expect(cond).NotTo(BeNil())
+			g.Expect(cond.Message).To(Equal("mo_br exited with 1"))
+			err := cli.Get(context.TODO(), client.ObjectKeyFromObject(job), &batchv1.Job{})
+			if tt.wantPhase == v1alpha1.JobPhasePending {
+				g.Expect(err).To(HaveOccurred(), "the job should be deleted to be re-created")
+			} else {
+				g.Expect(err).NotTo(HaveOccurred(), "the failed job should be kept for troubleshooting")
+			}
+		})
+	}
+}
+
+func TestBackupActor_cancelBackup(t *testing.T) {
+	bj := &v1alpha1.BackupJob{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "backup", UID: "uid"},
+		Spec: v1alpha1.BackupJobSpec{Target: v1alpha1.SharedStorageProvider{
+			S3: &v1alpha1.S3Provider{Path: "bucket/backup"},
+		}},
+	}
+	ownedBackup := func(created time.Time, finalizers ...string) *v1alpha1.Backup {
+		return &v1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{
+			Name:              "backup-1",
+			Labels:            map[string]string{common.PreUUIDLabelKey: string(bj.UID)},
+			CreationTimestamp: metav1.NewTime(created),
+			Finalizers:        finalizers,
+		}}
+	}
+	tests := []struct {
+		name    string
+		phase   string
+		objects []client.Object
+		events  []string
+		// wantResync is set if the cancellation is expected to wait
+		wantResync bool
+		expect     func(g *WithT, cli client.Client)
+	}{{
+		name:   "pending",
+		phase:  v1alpha1.JobPhasePending,
+		events: []string{"JobCancelled"},
+	}, {
+		name:    "running with the cleanup job succeeded",
+		phase:   v1alpha1.JobPhaseRunning,
+		objects: []client.Object{cleanupJob(bj, batchv1.JobStatus{Succeeded: 1})},
+		events:  []string{"PartialDataCleaned", "JobCancelled"},
+	}, {
+		name:       "running with the cleanup job in progress",
+		phase:      v1alpha1.JobPhaseRunning,
+		objects:    []client.Object{cleanupJob(bj, batchv1.JobStatus{Active: 1})},
+		wantResync: true,
+	}, {
+		name:    "backup recorded",
+		phase:   v1alpha1.JobPhaseCompleted,
+		objects: []client.Object{ownedBackup(time.Now(), "matrixorigin.io/backup-data")},
+		events:  []string{"JobCancelled"},
+		expect: func(g *WithT, cli client.Client) {
+			b := &v1alpha1.Backup{}
+			g.Expect(cli.Get(context.TODO(), types.NamespacedName{Name: "backup-1"}, b)).To(Succeed())
+			g.Expect(b.Annotations).To(HaveKeyWithValue(BackupExpiredAnnoKey, "cancelled"))
+			g.Expect(b.DeletionTimestamp).NotTo(BeNil())
+		},
+	}, {
+		name:       "backup not finalizable yet",
+		phase:      v1alpha1.JobPhaseCompleted,
+		objects:    []client.Object{ownedBackup(time.Now())},
+		wantResync: true,
+	}, {
+		name:    "backup not finalizable after finalizerWaitTimeout",
+		phase:   v1alpha1.JobPhaseCompleted,
+		objects: []client.Object{ownedBackup(time.Now().Add(-2 * finalizerWaitTimeout))},
+		events:  []string{"BackupRetained", "JobCancelled"},
+		expect: func(g *WithT, cli client.Client) {
+			b := &v1alpha1.Backup{}
+			g.Expect(cli.Get(context.TODO(), types.NamespacedName{Name: "backup-1"}, b)).To(Succeed())
+			g.Expect(b.DeletionTimestamp).To(BeNil(), "the backup should be retained")
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			obj := bj.DeepCopy()
+			obj.Status.Phase = tt.phase
+			cli := fake.KubeClientBuilder().WithScheme(newBRScheme()).
+				WithObjects(append(tt.objects, obj)...).WithStatusSubresource(obj).Build()
+			emitter := fake.NewMockEventEmitter(gomock.NewController(t))
+			for _, e := range tt.events {
+				emitter.EXPECT().EmitEventGeneric(e, gomock.Any(), gomock.Any()).Times(1)
+			}
+			ctx := fake.NewContext(obj, cli, emitter)
+
+			err := (&BackupActor{}).cancelBackup(ctx)
+			if tt.wantResync {
+				_, ok := err.(*recon.ReSync)
+				g.Expect(ok).To(BeTrue(), "expect resync, got %v", err)
+				g.Expect(obj.Status.Phase).To(Equal(tt.phase))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(obj.Status.Phase).To(Equal(v1alpha1.JobPhaseCancelled))
+			}
+			if tt.expect != nil {
+				tt.expect(g, cli)
+			}
+		})
+	}
+}
+
+func cleanupJob(bj *v1alpha1.BackupJob, status batchv1.JobStatus) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Namespace: bj.Namespace, Name: cleanupJobName(bj)},
+		Status:     status,
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/controller-runtime/pkg/util"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/cmd"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// defaultDeleteBackoffLimit is the number of retries of the delete job before the backup data is retained
+const defaultDeleteBackoffLimit int32 = 3
+
+// BackupDataActor cleans the backup data when a Backup is deleted
+type BackupDataActor struct {
+	image string
+
+	deleteBackoffLimit *int32
+}
+
+func NewBackupDataActor(image string) *BackupDataActor {
+	return &BackupDataActor{image: image, deleteBackoffLimit: pointer.Int32(defaultDeleteBackoffLimit)}
+}
+
+var _ recon.Actor[*v1alpha1.Backup] = &BackupDataActor{}
+
+func (c *BackupDataActor) Observe(_ *recon.Context[*v1alpha1.Backup]) (recon.Action[*v1alpha1.Backup], error) {
+	// nothing to do until the backup is deleted
+	return nil, nil
+}
+
+func (c *BackupDataActor) Finalize(ctx *recon.Context[*v1alpha1.Backup]) (bool, error) {
+	b := ctx.Obj
+	if err := c.checkInUse(ctx); err != nil {
+		return false, err
+	}
+
+	if !shouldDeleteBackupData(b) {
+		return true, nil
+	}
+	ns := backupNamespace(b)
+	if ns == "" {
+		ctx.Event.EmitEventGeneric("SkipDeleteData", "cannot infer the namespace to run the delete job, backup data is retained", nil)
+		return true, nil
+	}
+	meta := metav1.ObjectMeta{
+		Name:      fmt.Sprintf("%s-delete", b.Name),
+		Namespace: ns,
+		Labels: map[string]string{
+			common.InstanceLabelKey:  b.Name,
+			common.ComponentLabelKey: "Backup",
+		},
+	}
+	job := &batchv1.Job{}
+	err := ctx.Get(types.NamespacedName{Namespace: meta.Namespace, Name: meta.Name}, job)
+	if apierrors.IsNotFound(err) {
+		return false, c.createDeleteJob(ctx, meta)
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "error get delete job")
+	}
+	if job.DeletionTimestamp != nil {
+		// wait the failed job to be removed before re-creating it
+		return false, nil
+	}
+	if job.Status.Failed > 0 {
+		return c.retryDeleteJob(ctx, meta, "delete job is failed")
+	}
+	status, err := cmd.GetCmdStatus(fmt.Sprintf("%s.%s", meta.Name, meta.Namespace), defaultCMDRestPort)
+	if err != nil {
+		// the job may not be ready, check next time
+		ctx.Log.Info("error get delete status", "error", err.Error())
+		return false, nil
+	}
+	if !status.Completed {
+		return false, nil
+	}
+	if status.ExitCode != 0 {
+		return c.retryDeleteJob(ctx, meta, status.Stderr)
+	}
+	if err := util.Ignore(apierrors.IsNotFound, ctx.Delete(&batchv1.Job{ObjectMeta: meta}, client.PropagationPolicy(metav1.DeletePropagationBackground))); err != nil {
+		return false, errors.Wrap(err, "error cleanup delete job")
+	}
+	return true, nil
+}
+
+// field indexes of the objects that reference a Backup, see SetupBackupRefIndexer
+const (
+	restoreJobBackupField = "backupRef"
+	backupJobParentField  = "status.parentBackup"
+	backupChainField      = "meta.chain"
+)
+
+// backupRefIndexes index the objects that reference a Backup by the name of the Backup
+var backupRefIndexes = []struct {
+	obj     client.Object
+	field   string
+	extract client.IndexerFunc
+}{{
+	obj:   &v1alpha1.RestoreJob{},
+	field: restoreJobBackupField,
+	extract: func(o client.Object) []string {
+		return restoreJobBackupRefs(o.(*v1alpha1.RestoreJob))
+	},
+}, {
+	obj:   &v1alpha1.BackupJob{},
+	field: backupJobParentField,
+	extract: func(o client.Object) []string {
+		if parent := o.(*v1alpha1.BackupJob).Status.ParentBackup; parent != "" {
+			return []string{parent}
+		}
+		return nil
+	},
+}, {
+	obj:   &v1alpha1.Backup{},
+	field: backupChainField,
+	extract: func(o client.Object) []string {
+		return o.(*v1alpha1.Backup).Meta.Chain
+	},
+}}
+
+// SetupBackupRefIndexer indexes the objects that reference a Backup in the cache of the manager, so
+// that the references of a Backup can be checked without scanning all of them. Must be called before
+// the manager starts
+func SetupBackupRefIndexer(ctx context.Context, mgr manager.Manager) error {
+	for _, idx := range backupRefIndexes {
+		if err := mgr.GetFieldIndexer().IndexField(ctx, idx.obj, idx.field, idx.extract); err != nil {
+			return errors.Wrapf(err, "index %s", idx.field)
+		}
+	}
+	return nil
+}
+
+// restoreJobBackupRefs returns the Backups that the restore job is requested to or actually restores from
+func restoreJobBackupRefs(rj *v1alpha1.RestoreJob) []string {
+	var refs []string
+	if rj.Spec.BackupName != "" {
+		refs = append(refs, rj.Spec.BackupName)
+	}
+	if rj.Status.Backup != "" && rj.Status.Backup != rj.Spec.BackupName {
+		refs = append(refs, rj.Status.Backup)
+	}
+	return refs
+}
+
+// checkInUse returns an error if the backup is still used by restore jobs, running incremental
+// backup jobs or other incremental backups
+func (c *BackupDataActor) checkInUse(ctx *recon.Context[*v1alpha1.Backup]) error {
+	b := ctx.Obj
+	restoreList := &v1alpha1.RestoreJobList{}
+	if err := ctx.List(restoreList, client.MatchingFields{restoreJobBackupField: b.Name}); err != nil {
+		return errors.Wrap(err, "error list restore jobs")
+	}
+	for _, rj := range restoreList.Items {
+		if !jobEnded(rj.Status.Phase) {
+			return errors.Errorf("backup is being restored by restore job %s/%s", rj.Namespace, rj.Name)
+		}
+	}
+	backupJobList := &v1alpha1.BackupJobList{}
+	if err := ctx.List(backupJobList, client.MatchingFields{backupJobParentField: b.Name}); err != nil {
+		return errors.Wrap(err, "error list backup jobs")
+	}
+	for _, bj := range backupJobList.Items {
+		if !jobEnded(bj.Status.Phase) {
+			return errors.Errorf("backup is the parent of running backup job %s/%s", bj.Namespace, bj.Name)
+		}
+	}
+	backupList := &v1alpha1.BackupList{}
+	if err := ctx.List(backupList, client.MatchingFields{backupChainField: b.Name}); err != nil {
+		return errors.Wrap(err, "error list backups")
+	}
+	if len(backupList.Items) > 0 {
+		// the base is deleted after all the incremental backups that depend on it are deleted
+		return errors.Errorf("incremental backup %s depends on this backup", backupList.Items[0].Name)
+	}
+	return nil
+}
+
+func (c *BackupDataActor) createDeleteJob(ctx *recon.Context[*v1alpha1.Backup], meta metav1.ObjectMeta) error {
+	b := ctx.Obj
+	deleteCmd := &DeleteCommand{
+		BackupID:      b.Meta.ID,
+		ReadEnvSecret: b.Meta.Location.S3 != nil && b.Meta.Location.S3.SecretRef != nil,
+	}
+	job := newBRJob(meta, nil, c.image, deleteCmd.String(), func(c *corev1.Container) {
+		c.Env = backupMetaEnv(b)
+	})
+	mountStorage(job, b.Meta.Location, "backup")
+	if err := util.Ignore(apierrors.IsAlreadyExists, ctx.CreateOwned(job)); err != nil {
+		return errors.Wrap(err, "error create delete job")
+	}
+	if err := util.Ignore(apierrors.IsAlreadyExists, ctx.CreateOwned(newBRSvc(meta))); err != nil {
+		return errors.Wrap(err, "error create delete service")
+	}
+	return nil
+}
+
+// retryDeleteJob removes the failed delete job so that it can be re-created in the next round. Once the
+// backoffLimit is exceeded, the backup data is retained and the finalizing completes
+func (c *BackupDataActor) retryDeleteJob(ctx *recon.Context[*v1alpha1.Backup], meta metav1.ObjectMeta, msg string) (bool, error) {
+	b := ctx.Obj
+	if err := util.Ignore(apierrors.IsNotFound, ctx.Delete(&batchv1.Job{ObjectMeta: meta}, client.PropagationPolicy(metav1.DeletePropagationBackground))); err != nil {
+		return false, errors.Wrap(err, "error cleanup failed delete job")
+	}
+	if !shouldRetry(c.deleteBackoffLimit, b.Status.DeleteRetries) {
+		ctx.Event.EmitEventGeneric("DeleteDataFailed",
+			fmt.Sprintf("give up deleting data of backup %s after %d retries, backup data is retained", b.Meta.ID, b.Status.DeleteRetries),
+			errors.New(msg))
+		return true, nil
+	}
+	b.Status.DeleteRetries++
+	ctx.Event.EmitEventGeneric("JobRetrying", fmt.Sprintf("retry delete backup data (%d/%d): %s", b.Status.DeleteRetries, *c.deleteBackoffLimit, msg), nil)
+	if err := ctx.UpdateStatus(b); err != nil {
+		return false, errors.Wrap(err, "error update delete retries")
+	}
+	return false, nil
+}
+
+func (c *BackupDataActor) Reconcile(mgr manager.Manager) error {
+	return recon.Setup[*v1alpha1.Backup](&v1alpha1.Backup{}, "backup", mgr, c,
+		recon.SkipStatusSync(),
+		recon.WithBuildFn(func(b *builder.Builder) {
+			b.Owns(&batchv1.Job{})
+		}))
+}
+
+// shouldDeleteBackupData returns whether the backup data should be deleted along with the Backup object,
+// data is deleted when the Backup is expired by a retention policy or the location of the backup
+// specifies a Delete retention policy
+func shouldDeleteBackupData(b *v1alpha1.Backup) bool {
+	expired := b.Annotations[BackupExpiredAnnoKey] != ""
+	loc := b.Meta.Location
+	switch {
+	case loc.S3 != nil:
+		policy := loc.S3.S3RetentionPolicy
+		return expired || (policy != nil && *policy == v1alpha1.PVCRetentionPolicyDelete)
+	case loc.FileSystem != nil:
+		// the fileSystem is only accessible by the delete job if the volume is specified
+		return expired && (loc.FileSystem.PersistentVolumeClaim != nil || loc.FileSystem.HostPath != nil)
+	}
+	return false
+}
+
+// backupNamespace returns the namespace of the source that produced the backup
+func backupNamespace(b *v1alpha1.Backup) string {
+	if ns := b.Labels[common.NamespaceLabelKey]; ns != "" {
+		return ns
+	}
+	// sourceRef is in <kind>/<namespace>/<name> format
+	parts := strings.Split(b.Meta.SourceRef, "/")
+	if len(parts) != 3 {
+		return ""
+	}
+	return parts[1]
+}
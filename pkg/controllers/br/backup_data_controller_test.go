@@ -0,0 +1,118 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/matrixorigin/controller-runtime/pkg/fake"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestBackupDataActor_checkInUse(t *testing.T) {
+	b := &v1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "base"}}
+	tests := []struct {
+		name    string
+		objects []client.Object
+		wantErr bool
+	}{{
+		name: "not referenced",
+		objects: []client.Object{
+			&v1alpha1.RestoreJob{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+				Spec:       v1alpha1.RestoreJobSpec{BackupName: "other"},
+			},
+			&v1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "other-incr"}, Meta: v1alpha1.BackupMeta{Chain: []string{"other"}}},
+		},
+	}, {
+		name: "restored by a completed restore job",
+		objects: []client.Object{&v1alpha1.RestoreJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "restore", Namespace: "default"},
+			Spec:       v1alpha1.RestoreJobSpec{BackupName: "base"},
+			Status:     v1alpha1.RestoreJobStatus{Phase: v1alpha1.JobPhaseCompleted},
+		}},
+	}, {
+		name: "resolved by a running restore job",
+		objects: []client.Object{&v1alpha1.RestoreJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "restore", Namespace: "default"},
+			Status:     v1alpha1.RestoreJobStatus{Backup: "base", Phase: v1alpha1.JobPhaseRunning},
+		}},
+		wantErr: true,
+	}, {
+		name: "parent of a running backup job",
+		objects: []client.Object{&v1alpha1.BackupJob{
+			ObjectMeta: metav1.ObjectMeta{Name: "incr", Namespace: "default"},
+			Status:     v1alpha1.BackupJobStatus{ParentBackup: "base", Phase: v1alpha1.JobPhaseRunning},
+		}},
+		wantErr: true,
+	}, {
+		name: "base of an incremental backup",
+		objects: []client.Object{
+			&v1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "incr"}, Meta: v1alpha1.BackupMeta{Chain: []string{"base"}}},
+		},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			s := runtime.NewScheme()
+			utilruntime.Must(v1alpha1.AddToScheme(s))
+			builder := fake.KubeClientBuilder().WithScheme(s).WithObjects(tt.objects...)
+			for _, idx := range backupRefIndexes {
+				builder = builder.WithIndex(idx.obj, idx.field, idx.extract)
+			}
+			ctx := fake.NewContext(b, builder.Build(), fake.NewMockEventEmitter(gomock.NewController(t)))
+			err := (&BackupDataActor{}).checkInUse(ctx)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
+func TestBackupDataActor_retryDeleteJob(t *testing.T) {
+	g := NewGomegaWithT(t)
+	s := runtime.NewScheme()
+	utilruntime.Must(v1alpha1.AddToScheme(s))
+	utilruntime.Must(batchv1.AddToScheme(s))
+	b := &v1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "backup"}, Meta: v1alpha1.BackupMeta{ID: "id"}}
+	cli := fake.KubeClientBuilder().WithScheme(s).WithObjects(b).WithStatusSubresource(b).Build()
+	emitter := fake.NewMockEventEmitter(gomock.NewController(t))
+	emitter.EXPECT().EmitEventGeneric("JobRetrying", gomock.Any(), nil).Times(2)
+	emitter.EXPECT().EmitEventGeneric("DeleteDataFailed", gomock.Any(), gomock.Not(nil)).Times(1)
+	ctx := fake.NewContext(b, cli, emitter)
+	c := &BackupDataActor{deleteBackoffLimit: pointer.Int32(2)}
+	meta := metav1.ObjectMeta{Name: "backup-delete", Namespace: "default"}
+
+	for i := 1; i <= 2; i++ {
+		done, err := c.retryDeleteJob(ctx, meta, "access denied")
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(done).To(BeFalse())
+		g.Expect(b.Status.DeleteRetries).To(Equal(int32(i)))
+	}
+	done, err := c.retryDeleteJob(ctx, meta, "access denied")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(done).To(BeTrue(), "backup data should be retained once the backoffLimit is exceeded")
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"context"
+	"strings"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// SetupBackupIndexer indexes the Backups by sourceRef in the cache of the manager, so that the backups
+// of a source can be listed without scanning the whole catalog. Must be called before the manager starts
+func SetupBackupIndexer(ctx context.Context, mgr manager.Manager) error {
+	return mgr.GetFieldIndexer().IndexField(ctx, &v1alpha1.Backup{}, v1alpha1.BackupSourceRefField, func(o client.Object) []string {
+		return []string{o.(*v1alpha1.Backup).Meta.SourceRef}
+	})
+}
+
+// catalogLabels returns the labels that catalog the backup produced by the backup job,
+// a label is omitted if the value is not a valid label value
+func catalogLabels(bj *v1alpha1.BackupJob, version string) map[string]string {
+	labels := map[string]string{
+		v1alpha1.BackupSourceNamespaceLabelKey: bj.Namespace,
+	}
+	switch {
+	case bj.Spec.Source.ClusterRef != nil:
+		labels[v1alpha1.BackupSourceClusterLabelKey] = *bj.Spec.Source.ClusterRef
+	case bj.Spec.Source.CNSetRef != nil:
+		labels[v1alpha1.BackupSourceClusterLabelKey] = *bj.Spec.Source.CNSetRef
+	}
+	if version != "" {
+		labels[v1alpha1.BackupMOVersionLabelKey] = version
+	}
+	for k, v := range labels {
+		if len(validation.IsValidLabelValue(v)) > 0 {
+			delete(labels, k)
+		}
+	}
+	return labels
+}
+
+// imageTag returns the tag of the image, empty string is returned if the image has no tag
+func imageTag(image string) string {
+	// strip the digest and the registry host that may contain a port
+	image = strings.SplitN(image, "@", 2)[0]
+	image = image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(image, ":"); i >= 0 {
+		return image[i+1:]
+	}
+	return ""
+}
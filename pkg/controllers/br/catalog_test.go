@@ -0,0 +1,56 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_catalogLabels(t *testing.T) {
+	g := NewGomegaWithT(t)
+	mo := "mo"
+	bj := &v1alpha1.BackupJob{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "backup"},
+		Spec:       v1alpha1.BackupJobSpec{Source: v1alpha1.BackupSource{ClusterRef: &mo}},
+	}
+	g.Expect(catalogLabels(bj, "1.2.0")).To(Equal(map[string]string{
+		v1alpha1.BackupSourceNamespaceLabelKey: "default",
+		v1alpha1.BackupSourceClusterLabelKey:   "mo",
+		v1alpha1.BackupMOVersionLabelKey:       "1.2.0",
+	}))
+	g.Expect(catalogLabels(bj, "invalid/version")).NotTo(HaveKey(v1alpha1.BackupMOVersionLabelKey))
+}
+
+func Test_imageTag(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "matrixorigin/matrixone:1.2.0", want: "1.2.0"},
+		{image: "registry:5000/matrixorigin/matrixone:nightly-abc", want: "nightly-abc"},
+		{image: "registry:5000/matrixorigin/matrixone", want: ""},
+		{image: "matrixone:1.2.0@sha256:abcd", want: "1.2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(imageTag(tt.image)).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"fmt"
+	"path"
+
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/controller-runtime/pkg/util"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// defaultCleanupImage runs the cleanup job if no cleanup image is configured, which provides both the shell
+	// to clean a fileSystem and the aws cli to clean S3
+	defaultCleanupImage = "amazon/aws-cli:2.13.25"
+
+	// legacyJobNameLabel is the label set on the pods of a job by all the versions of kubernetes
+	legacyJobNameLabel = "job-name"
+
+	cleanupBackoffLimit int32 = 3
+)
+
+// backupDataDir is the directory under the backup target that the backup job writes to
+func backupDataDir(bj *v1alpha1.BackupJob) string {
+	return fmt.Sprintf("%s/%s-%s", bj.Namespace, bj.Name, bj.UID)
+}
+
+// backupLocation returns the location of the data written by the backup job, i.e. the backupDataDir under the target,
+// the sub-directory of a fileSystem is mounted to the sub-path so that jobs mounting the location see the same layout
+func backupLocation(bj *v1alpha1.BackupJob) v1alpha1.SharedStorageProvider {
+	loc := *bj.Spec.Target.DeepCopy()
+	dir := backupDataDir(bj)
+	if loc.S3 != nil {
+		loc.S3.Path = path.Join(loc.S3.Path, dir)
+	}
+	if fs := loc.FileSystem; fs != nil {
+		fs.Path = path.Join(fs.Path, dir)
+		fs.SubPath = path.Join(fs.SubPath, dir)
+	}
+	return loc
+}
+
+func cleanupJobName(bj *v1alpha1.BackupJob) string {
+	return fmt.Sprintf("%s-cleanup", bj.Name)
+}
+
+// buildCleanupJob builds the job that removes the data written by the backup job
+func buildCleanupJob(bj *v1alpha1.BackupJob, image string) (*batchv1.Job, error) {
+	target, err := newStorage(bj.Spec.Target)
+	if err != nil {
+		return nil, err
+	}
+	cleanupCmd := &CleanupCommand{Target: target.Sub(backupDataDir(bj))}
+	if err := cleanupCmd.Validate(); err != nil {
+		return nil, err
+	}
+	meta := metav1.ObjectMeta{
+		Name:      cleanupJobName(bj),
+		Namespace: bj.Namespace,
+		Labels: map[string]string{
+			common.NamespaceLabelKey: bj.Namespace,
+			common.InstanceLabelKey:  bj.Name,
+			common.ComponentLabelKey: "BackupCleanup",
+		},
+	}
+	c := corev1.Container{
+		Name:    "cleanup",
+		Image:   image,
+		Command: []string{"/bin/sh", "-c", cleanupCmd.String()},
+	}
+	if bj.Spec.Target.S3 != nil && bj.Spec.Target.S3.SecretRef != nil {
+		for _, key := range []string{common.AWSAccessKeyID, common.AWSSecretAccessKey} {
+			c.Env = util.UpsertByKey(c.Env, corev1.EnvVar{Name: key, ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: *bj.Spec.Target.S3.SecretRef,
+					Key:                  key,
+				},
+			}}, util.EnvVarKey)
+		}
+	}
+	tpl := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: meta.Labels},
+		Spec: corev1.PodSpec{
+			Containers:    []corev1.Container{c},
+			RestartPolicy: corev1.RestartPolicyNever,
+		},
+	}
+	if overlay := bj.GetOverlay(); overlay != nil {
+		// scheduling and service account of the backup job also apply to the cleanup job
+		overlay.OverlayPodMeta(&tpl.ObjectMeta)
+		overlay.OverlayPodSpec(&tpl.Spec)
+	}
+	job := &batchv1.Job{
+		ObjectMeta: meta,
+		Spec: batchv1.JobSpec{
+			Parallelism:  pointer.Int32(1),
+			Completions:  pointer.Int32(1),
+			BackoffLimit: pointer.Int32(cleanupBackoffLimit),
+			Template:     tpl,
+		},
+	}
+	mountStorage(job, bj.Spec.Target, "backup")
+	return job, nil
+}
+
+// waitCleanupJob requeues until the cleanup job ends, the partial data is retained if the cleanup fails
+func waitCleanupJob(ctx *recon.Context[*v1alpha1.BackupJob], job *batchv1.Job) error {
+	switch {
+	case job.Status.Succeeded > 0:
+		ctx.Event.EmitEventGeneric("PartialDataCleaned", "partial backup data is cleaned", nil)
+		return nil
+	case job.Status.Failed > cleanupBackoffLimit:
+		ctx.Event.EmitEventGeneric("CleanupFailed",
+			fmt.Sprintf("partial backup data under %s of the target is retained, see job %s for details", backupDataDir(ctx.Obj), job.Name),
+			errors.New("cleanup job failed"))
+		return nil
+	}
+	return recon.ErrReSync("wait the partial backup data to be cleaned", retryInterval)
+}
+
+// waitJobPodsDeleted requeues until the pods of the job of the job object are all deleted
+func waitJobPodsDeleted[T client.Object](ctx *recon.Context[T], o client.Object) error {
+	podList := &corev1.PodList{}
+	if err := ctx.List(podList, client.InNamespace(o.GetNamespace()), client.MatchingLabels{legacyJobNameLabel: o.GetName()}); err != nil {
+		return errors.Wrap(err, "error list job pods")
+	}
+	if len(podList.Items) > 0 {
+		return recon.ErrReSync("wait the job pods deleted", retryInterval)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/matrixorigin/controller-runtime/pkg/fake"
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_backupLocation(t *testing.T) {
+	meta := metav1.ObjectMeta{Namespace: "default", Name: "bj", UID: "uid"}
+	pvc := &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "backup"}
+	tests := []struct {
+		name   string
+		target v1alpha1.SharedStorageProvider
+		want   v1alpha1.SharedStorageProvider
+	}{
+		{
+			name:   "s3",
+			target: v1alpha1.SharedStorageProvider{S3: &v1alpha1.S3Provider{Path: "bucket/backup"}},
+			want:   v1alpha1.SharedStorageProvider{S3: &v1alpha1.S3Provider{Path: "bucket/backup/default/bj-uid"}},
+		},
+		{
+			name: "fileSystem",
+			target: v1alpha1.SharedStorageProvider{FileSystem: &v1alpha1.FileSystemProvider{
+				Path: "/backup", PersistentVolumeClaim: pvc,
+			}},
+			want: v1alpha1.SharedStorageProvider{FileSystem: &v1alpha1.FileSystemProvider{
+				Path: "/backup/default/bj-uid", PersistentVolumeClaim: pvc, SubPath: "default/bj-uid",
+			}},
+		},
+		{
+			name: "fileSystem with subPath",
+			target: v1alpha1.SharedStorageProvider{FileSystem: &v1alpha1.FileSystemProvider{
+				Path: "/backup/", HostPath: &corev1.HostPathVolumeSource{Path: "/data"}, SubPath: "mo",
+			}},
+			want: v1alpha1.SharedStorageProvider{FileSystem: &v1alpha1.FileSystemProvider{
+				Path: "/backup/default/bj-uid", HostPath: &corev1.HostPathVolumeSource{Path: "/data"}, SubPath: "mo/default/bj-uid",
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			bj := &v1alpha1.BackupJob{ObjectMeta: meta, Spec: v1alpha1.BackupJobSpec{Target: tt.target}}
+			g.Expect(backupLocation(bj)).To(Equal(tt.want))
+			g.Expect(bj.Spec.Target).To(Equal(tt.target), "the target of the backup job must not be mutated")
+		})
+	}
+}
+
+func Test_buildCleanupJob(t *testing.T) {
+	meta := metav1.ObjectMeta{Namespace: "default", Name: "bj", UID: "uid"}
+	tests := []struct {
+		name   string
+		target v1alpha1.SharedStorageProvider
+		expect func(g *WithT, job *batchv1.Job)
+	}{{
+		name: "s3",
+		target: v1alpha1.SharedStorageProvider{S3: &v1alpha1.S3Provider{
+			Path:      "bucket/backup",
+			SecretRef: &corev1.LocalObjectReference{Name: "aws"},
+		}},
+		expect: func(g *WithT, job *batchv1.Job) {
+			c := job.Spec.Template.Spec.Containers[0]
+			g.Expect(c.Command[2]).To(ContainSubstring("s3 rm 's3://bucket/backup/default/bj-uid' --recursive"))
+			g.Expect(c.Env).To(HaveLen(2))
+			g.Expect(c.Env[0].ValueFrom.SecretKeyRef.Name).To(Equal("aws"))
+			g.Expect(job.Spec.Template.Spec.Volumes).To(BeEmpty())
+		},
+	}, {
+		name: "fileSystem",
+		target: v1alpha1.SharedStorageProvider{FileSystem: &v1alpha1.FileSystemProvider{
+			Path:                  "/backup",
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "nfs"},
+		}},
+		expect: func(g *WithT, job *batchv1.Job) {
+			c := job.Spec.Template.Spec.Containers[0]
+			g.Expect(c.Command[2]).To(Equal("rm -rf -- '/backup/default/bj-uid'"))
+			g.Expect(c.VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: "backup", MountPath: "/backup"}))
+			g.Expect(job.Spec.Template.Spec.Volumes).To(HaveLen(1))
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			bj := &v1alpha1.BackupJob{ObjectMeta: meta, Spec: v1alpha1.BackupJobSpec{Target: tt.target}}
+			job, err := buildCleanupJob(bj, "aws-cli:v1")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(job.Name).To(Equal("bj-cleanup"))
+			g.Expect(job.Labels).To(HaveKeyWithValue(common.ComponentLabelKey, "BackupCleanup"))
+			g.Expect(*job.Spec.BackoffLimit).To(Equal(cleanupBackoffLimit))
+			g.Expect(job.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+			g.Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal("aws-cli:v1"))
+			tt.expect(g, job)
+		})
+	}
+}
+
+func Test_waitCleanupJob(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     batchv1.JobStatus
+		event      string
+		wantResync bool
+	}{{
+		name:       "running",
+		status:     batchv1.JobStatus{Active: 1},
+		wantResync: true,
+	}, {
+		name:       "retrying",
+		status:     batchv1.JobStatus{Failed: cleanupBackoffLimit},
+		wantResync: true,
+	}, {
+		name:   "succeeded",
+		status: batchv1.JobStatus{Succeeded: 1},
+		event:  "PartialDataCleaned",
+	}, {
+		name:   "failed",
+		status: batchv1.JobStatus{Failed: cleanupBackoffLimit + 1},
+		event:  "CleanupFailed",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			bj := &v1alpha1.BackupJob{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bj"}}
+			emitter := fake.NewMockEventEmitter(gomock.NewController(t))
+			if tt.event != "" {
+				emitter.EXPECT().EmitEventGeneric(tt.event, gomock.Any(), gomock.Any()).Times(1)
+			}
+			ctx := fake.NewContext(bj, fake.KubeClientBuilder().WithScheme(newBRScheme()).Build(), emitter)
+			err := waitCleanupJob(ctx, cleanupJob(bj, tt.status))
+			if tt.wantResync {
+				_, ok := err.(*recon.ReSync)
+				g.Expect(ok).To(BeTrue(), "expect resync, got %v", err)
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
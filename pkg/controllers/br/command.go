@@ -17,6 +17,8 @@ package br
 import (
 	"fmt"
 	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/pkg/errors"
+	"path"
 	"strings"
 )
 
@@ -32,9 +34,18 @@ const (
 )
 
 type BackupCommand struct {
-	Host string
-	Port int
-	S3   S3
+	Host   string
+	Port   int
+	Target Storage
+
+	// BaseID is the ID of the parent backup, an incremental backup is taken if set
+	BaseID string
+}
+
+// Storage is the location of the backup data, either S3 or FileSystem is set
+type Storage struct {
+	S3         *S3
+	FileSystem *FileSystem
 }
 
 type S3 struct {
@@ -42,28 +53,82 @@ type S3 struct {
 	Bucket        string
 	Path          string
 	Type          string
+	Region        string
 	ReadEnvSecret bool
 }
 
+type FileSystem struct {
+	Path string
+}
+
+func newStorage(p v1alpha1.SharedStorageProvider) (Storage, error) {
+	if p.FileSystem != nil {
+		return Storage{FileSystem: &FileSystem{Path: p.FileSystem.Path}}, nil
+	}
+	if p.S3 == nil {
+		return Storage{}, errors.New("either s3 or fileSystem must be set")
+	}
+	s3 := &S3{
+		Endpoint:      p.S3.Endpoint,
+		Type:          string(p.S3.GetProviderType()),
+		Region:        p.S3.Region,
+		ReadEnvSecret: p.S3.SecretRef != nil,
+	}
+	parts := strings.SplitN(p.S3.Path, "/", 2)
+	s3.Bucket = parts[0]
+	if len(parts) > 1 {
+		s3.Path = parts[1]
+	}
+	return Storage{S3: s3}, nil
+}
+
+// Sub returns the storage at the sub directory of the storage
+func (s Storage) Sub(dir string) Storage {
+	if s.FileSystem != nil {
+		fs := *s.FileSystem
+		fs.Path = path.Join(fs.Path, dir)
+		return Storage{FileSystem: &fs}
+	}
+	if s.S3 != nil {
+		s3 := *s.S3
+		s3.Path = path.Join(s3.Path, dir)
+		return Storage{S3: &s3}
+	}
+	return s
+}
+
 func (b *BackupCommand) String() string {
 	sb := strings.Builder{}
+	if b.BaseID != "" {
+		// mo_br looks up the parent backup in the meta file
+		sb.WriteString(fmt.Sprintf("echo \"$%s\" > /mo_br.meta && ", RawMetaEnv))
+	}
 	sb.WriteString("/mo_br backup")
 	sb.WriteString(fmt.Sprintf(" --host=%s", b.Host))
 	sb.WriteString(fmt.Sprintf(" --port=%d", b.Port))
 	sb.WriteString(" --user=$MO_USER")
 	sb.WriteString(" --password=$MO_PASSWORD")
-	sb.WriteString(" --backup_dir=s3")
-	sb.WriteString(fmt.Sprintf(" --endpoint=%s", b.S3.Endpoint))
-	sb.WriteString(fmt.Sprintf(" --bucket=%s", b.S3.Bucket))
-	if b.S3.Path != "" {
-		sb.WriteString(fmt.Sprintf(" --filepath=%s", b.S3.Path))
-	}
-	if b.S3.Type == string(v1alpha1.S3ProviderTypeMinIO) {
-		sb.WriteString(" --is_minio")
+	if fs := b.Target.FileSystem; fs != nil {
+		sb.WriteString(" --backup_dir=filesystem")
+		sb.WriteString(fmt.Sprintf(" --path=%s", fs.Path))
+	} else if s3 := b.Target.S3; s3 != nil {
+		sb.WriteString(" --backup_dir=s3")
+		sb.WriteString(fmt.Sprintf(" --endpoint=%s", s3.Endpoint))
+		sb.WriteString(fmt.Sprintf(" --bucket=%s", s3.Bucket))
+		if s3.Path != "" {
+			sb.WriteString(fmt.Sprintf(" --filepath=%s", s3.Path))
+		}
+		if s3.Type == string(v1alpha1.S3ProviderTypeMinIO) {
+			sb.WriteString(" --is_minio")
+		}
+		if s3.ReadEnvSecret {
+			sb.WriteString(" --access_key_id=$AWS_ACCESS_KEY_ID")
+			sb.WriteString(" --secret_access_key=$AWS_SECRET_ACCESS_KEY")
+		}
 	}
-	if b.S3.ReadEnvSecret {
-		sb.WriteString(" --access_key_id=$AWS_ACCESS_KEY_ID")
-		sb.WriteString(" --secret_access_key=$AWS_SECRET_ACCESS_KEY")
+	if b.BaseID != "" {
+		sb.WriteString(" --backup_type=incremental")
+		sb.WriteString(fmt.Sprintf(" --base_id=%s", b.BaseID))
 	}
 	sb.WriteString(fmt.Sprintf(" && echo %s && cat /mo_br.meta", MetaDelimiter))
 	return sb.String()
@@ -71,7 +136,7 @@ func (b *BackupCommand) String() string {
 
 type RestoreCommand struct {
 	BackupID string
-	Target   S3
+	Target   Storage
 	RawMeta  string
 
 	ReadSourceEnvSecret bool
@@ -79,25 +144,114 @@ type RestoreCommand struct {
 
 func (c *RestoreCommand) String() string {
 	sb := strings.Builder{}
-	sb.WriteString(fmt.Sprintf("echo $%s > /mo_br.meta", RawMetaEnv))
+	sb.WriteString(fmt.Sprintf("echo \"$%s\" > /mo_br.meta", RawMetaEnv))
 	sb.WriteString(" && /mo_br restore")
 	sb.WriteString(fmt.Sprintf(" %s", c.BackupID))
-	sb.WriteString(" --restore_dir s3")
 	if c.ReadSourceEnvSecret {
 		sb.WriteString(" --backup_access_key_id=$AWS_ACCESS_KEY_ID")
 		sb.WriteString(" --backup_secret_access_key=$AWS_SECRET_ACCESS_KEY")
 	}
-	sb.WriteString(fmt.Sprintf(" --restore_endpoint=%s", c.Target.Endpoint))
-	sb.WriteString(fmt.Sprintf(" --restore_bucket=%s", c.Target.Bucket))
-	if c.Target.Path != "" {
-		sb.WriteString(fmt.Sprintf(" --restore_filepath=%s", c.Target.Path))
+	if fs := c.Target.FileSystem; fs != nil {
+		sb.WriteString(" --restore_dir filesystem")
+		sb.WriteString(fmt.Sprintf(" --restore_path=%s", fs.Path))
+	} else if s3 := c.Target.S3; s3 != nil {
+		sb.WriteString(" --restore_dir s3")
+		sb.WriteString(fmt.Sprintf(" --restore_endpoint=%s", s3.Endpoint))
+		sb.WriteString(fmt.Sprintf(" --restore_bucket=%s", s3.Bucket))
+		if s3.Path != "" {
+			sb.WriteString(fmt.Sprintf(" --restore_filepath=%s", s3.Path))
+		}
+		if s3.ReadEnvSecret {
+			sb.WriteString(fmt.Sprintf(" --restore_access_key_id=$%s", RestoreAccessEnvKey))
+			sb.WriteString(fmt.Sprintf(" --restore_secret_access_key=$%s", RestoreSecretEnvKey))
+		}
+		if s3.Type == string(v1alpha1.S3ProviderTypeMinIO) {
+			sb.WriteString(" --restore_is_minio")
+		}
+	}
+	return sb.String()
+}
+
+type DeleteCommand struct {
+	BackupID string
+
+	ReadEnvSecret bool
+}
+
+func (c *DeleteCommand) String() string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("echo \"$%s\" > /mo_br.meta", RawMetaEnv))
+	sb.WriteString(" && /mo_br delete")
+	sb.WriteString(fmt.Sprintf(" %s", c.BackupID))
+	if c.ReadEnvSecret {
+		sb.WriteString(" --access_key_id=$AWS_ACCESS_KEY_ID")
+		sb.WriteString(" --secret_access_key=$AWS_SECRET_ACCESS_KEY")
+	}
+	return sb.String()
+}
+
+type VerifyCommand struct {
+	BackupID string
+
+	ReadEnvSecret bool
+}
+
+func (c *VerifyCommand) String() string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("echo \"$%s\" > /mo_br.meta", RawMetaEnv))
+	sb.WriteString(" && /mo_br check")
+	sb.WriteString(fmt.Sprintf(" %s", c.BackupID))
+	if c.ReadEnvSecret {
+		sb.WriteString(" --access_key_id=$AWS_ACCESS_KEY_ID")
+		sb.WriteString(" --secret_access_key=$AWS_SECRET_ACCESS_KEY")
+	}
+	return sb.String()
+}
+
+// CleanupCommand removes the data under the storage, which is run by the aws-cli image
+type CleanupCommand struct {
+	Target Storage
+}
+
+// errUnsafeCleanup is returned for the cleanup targets that would remove more than the data of a backup
+var errUnsafeCleanup = errors.New("unsafe cleanup target")
+
+// Validate rejects the targets that would remove more than the data of a backup
+func (c *CleanupCommand) Validate() error {
+	if fs := c.Target.FileSystem; fs != nil {
+		if p := path.Clean(fs.Path); fs.Path == "" || p == "/" || p == "." {
+			return errors.Wrapf(errUnsafeCleanup, "refuse to clean the path %q", fs.Path)
+		}
 	}
-	if c.Target.ReadEnvSecret {
-		sb.WriteString(fmt.Sprintf(" --restore_access_key_id=$%s", RestoreAccessEnvKey))
-		sb.WriteString(fmt.Sprintf(" --restore_secret_access_key=$%s", RestoreSecretEnvKey))
+	if s3 := c.Target.S3; s3 != nil {
+		if s3.Bucket == "" || strings.Trim(s3.Path, "/") == "" {
+			return errors.Wrapf(errUnsafeCleanup, "refuse to clean the whole bucket %q", s3.Bucket)
+		}
 	}
-	if c.Target.Type == string(v1alpha1.S3ProviderTypeMinIO) {
-		sb.WriteString(" --restore_is_minio")
+	return nil
+}
+
+func (c *CleanupCommand) String() string {
+	if fs := c.Target.FileSystem; fs != nil {
+		return fmt.Sprintf("rm -rf -- %s", shellQuote(fs.Path))
+	}
+	s3 := c.Target.S3
+	if s3 == nil {
+		return "true"
+	}
+	sb := strings.Builder{}
+	if s3.Region != "" {
+		sb.WriteString(fmt.Sprintf("AWS_REGION=%s ", shellQuote(s3.Region)))
 	}
+	sb.WriteString("aws")
+	if s3.Endpoint != "" {
+		sb.WriteString(fmt.Sprintf(" --endpoint-url %s", shellQuote(s3.Endpoint)))
+	}
+	sb.WriteString(fmt.Sprintf(" s3 rm %s --recursive", shellQuote("s3://"+path.Join(s3.Bucket, s3.Path))))
 	return sb.String()
 }
+
+// shellQuote quotes s as a single word of the shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,109 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestBackupCommand_String(t *testing.T) {
+	g := NewGomegaWithT(t)
+	s3, err := newStorage(v1alpha1.SharedStorageProvider{S3: &v1alpha1.S3Provider{
+		Path:      "bucket/backup",
+		Endpoint:  "http://minio",
+		Type:      func() *v1alpha1.S3ProviderType { t := v1alpha1.S3ProviderTypeMinIO; return &t }(),
+		SecretRef: &corev1.LocalObjectReference{Name: "s3"},
+	}})
+	g.Expect(err).To(Succeed())
+	c := &BackupCommand{Host: "mo", Port: 6001, Target: s3}
+	g.Expect(c.String()).To(Equal("/mo_br backup --host=mo --port=6001 --user=$MO_USER --password=$MO_PASSWORD" +
+		" --backup_dir=s3 --endpoint=http://minio --bucket=bucket --filepath=backup --is_minio" +
+		" --access_key_id=$AWS_ACCESS_KEY_ID --secret_access_key=$AWS_SECRET_ACCESS_KEY" +
+		" && echo META_DELIMITER && cat /mo_br.meta"))
+
+	fs, err := newStorage(v1alpha1.SharedStorageProvider{FileSystem: &v1alpha1.FileSystemProvider{Path: "/backup"}})
+	g.Expect(err).To(Succeed())
+	c = &BackupCommand{Host: "mo", Port: 6001, Target: fs, BaseID: "base"}
+	g.Expect(c.String()).To(Equal("echo \"$RAW_META\" > /mo_br.meta && " +
+		"/mo_br backup --host=mo --port=6001 --user=$MO_USER --password=$MO_PASSWORD" +
+		" --backup_dir=filesystem --path=/backup --backup_type=incremental --base_id=base" +
+		" && echo META_DELIMITER && cat /mo_br.meta"))
+
+	_, err = newStorage(v1alpha1.SharedStorageProvider{})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestRestoreCommand_String(t *testing.T) {
+	g := NewGomegaWithT(t)
+	fs, err := newStorage(v1alpha1.SharedStorageProvider{FileSystem: &v1alpha1.FileSystemProvider{Path: "/restore"}})
+	g.Expect(err).To(Succeed())
+	c := &RestoreCommand{BackupID: "id", Target: fs, ReadSourceEnvSecret: true}
+	g.Expect(c.String()).To(Equal("echo \"$RAW_META\" > /mo_br.meta && /mo_br restore id" +
+		" --backup_access_key_id=$AWS_ACCESS_KEY_ID --backup_secret_access_key=$AWS_SECRET_ACCESS_KEY" +
+		" --restore_dir filesystem --restore_path=/restore"))
+}
+
+func TestCleanupCommand_String(t *testing.T) {
+	g := NewGomegaWithT(t)
+	s3, err := newStorage(v1alpha1.SharedStorageProvider{S3: &v1alpha1.S3Provider{
+		Path:     "bucket/backup",
+		Endpoint: "http://minio",
+		Region:   "us-west-2",
+	}})
+	g.Expect(err).To(Succeed())
+	c := &CleanupCommand{Target: s3.Sub("default/job-uid")}
+	g.Expect(c.Validate()).To(Succeed())
+	g.Expect(c.String()).To(Equal("AWS_REGION='us-west-2' aws --endpoint-url 'http://minio' s3 rm 's3://bucket/backup/default/job-uid' --recursive"))
+
+	fs, err := newStorage(v1alpha1.SharedStorageProvider{FileSystem: &v1alpha1.FileSystemProvider{Path: "/backup"}})
+	g.Expect(err).To(Succeed())
+	c = &CleanupCommand{Target: fs.Sub("default/job-uid")}
+	g.Expect(c.Validate()).To(Succeed())
+	g.Expect(c.String()).To(Equal("rm -rf -- '/backup/default/job-uid'"))
+	g.Expect(fs.FileSystem.Path).To(Equal("/backup"), "Sub should not modify the original storage")
+
+	c = &CleanupCommand{Target: Storage{FileSystem: &FileSystem{Path: "/backup/it's $(date) *"}}}
+	g.Expect(c.String()).To(Equal(`rm -rf -- '/backup/it'\''s $(date) *'`))
+}
+
+func TestCleanupCommand_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  Storage
+		wantErr bool
+	}{
+		{name: "fileSystem", target: Storage{FileSystem: &FileSystem{Path: "/backup/default/job-uid"}}},
+		{name: "empty path", target: Storage{FileSystem: &FileSystem{}}, wantErr: true},
+		{name: "root", target: Storage{FileSystem: &FileSystem{Path: "/"}}, wantErr: true},
+		{name: "uncleaned root", target: Storage{FileSystem: &FileSystem{Path: "//backup/.."}}, wantErr: true},
+		{name: "s3", target: Storage{S3: &S3{Bucket: "bucket", Path: "backup/default/job-uid"}}},
+		{name: "whole bucket", target: Storage{S3: &S3{Bucket: "bucket", Path: "/"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			err := (&CleanupCommand{Target: tt.target}).Validate()
+			if tt.wantErr {
+				g.Expect(err).To(MatchError(errUnsafeCleanup))
+			} else {
+				g.Expect(err).To(Succeed())
+			}
+		})
+	}
+}
@@ -15,12 +15,19 @@
 package br
 
 import (
+	"fmt"
+
 	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/controller-runtime/pkg/util"
 	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/cmd"
 	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	"github.com/pkg/errors"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -29,6 +36,11 @@ import (
 
 const (
 	defaultCMDRestPort = 8080
+
+	// JobCancelAnnoKey cancels a BackupJob or RestoreJob if it has not ended yet
+	JobCancelAnnoKey = "matrixorigin.io/cancel"
+
+	retryInterval = 5 * time.Second
 )
 
 type JobObject interface {
@@ -40,13 +52,17 @@ type JobObject interface {
 }
 
 func buildJob(o JobObject, image string, command string, injectEnv func(c *corev1.Container)) *batchv1.Job {
-	meta := common.ObjMetaTemplate(o, o.GetName())
+	return newBRJob(common.ObjMetaTemplate(o, o.GetName()), o.GetOverlay(), image, command, injectEnv)
+}
+
+// newBRJob builds a job that runs the command by cmdrest in the br container
+func newBRJob(meta metav1.ObjectMeta, overlay *v1alpha1.Overlay, image string, command string, injectEnv func(c *corev1.Container)) *batchv1.Job {
 	brContainer := corev1.Container{
 		Name:  "br",
 		Image: image,
 	}
-	if o.GetOverlay() != nil {
-		o.GetOverlay().OverlayMainContainer(&brContainer)
+	if overlay != nil {
+		overlay.OverlayMainContainer(&brContainer)
 	}
 	brContainer.Command = []string{"/cmdrest", "--", command}
 	injectEnv(&brContainer)
@@ -59,9 +75,9 @@ func buildJob(o JobObject, image string, command string, injectEnv func(c *corev
 			RestartPolicy: corev1.RestartPolicyNever,
 		},
 	}
-	if o.GetOverlay() != nil {
-		o.GetOverlay().OverlayPodMeta(&tpl.ObjectMeta)
-		o.GetOverlay().OverlayPodSpec(&tpl.Spec)
+	if overlay != nil {
+		overlay.OverlayPodMeta(&tpl.ObjectMeta)
+		overlay.OverlayPodSpec(&tpl.Spec)
 	}
 	job := &batchv1.Job{
 		ObjectMeta: meta,
@@ -76,7 +92,11 @@ func buildJob(o JobObject, image string, command string, injectEnv func(c *corev
 }
 
 func buildSvc(o client.Object) *corev1.Service {
-	meta := common.ObjMetaTemplate(o, o.GetName())
+	return newBRSvc(common.ObjMetaTemplate(o, o.GetName()))
+}
+
+// newBRSvc builds a service to access the cmdrest server of a job
+func newBRSvc(meta metav1.ObjectMeta) *corev1.Service {
 	svc := &corev1.Service{
 		ObjectMeta: meta,
 		Spec: corev1.ServiceSpec{
@@ -90,3 +110,105 @@ func buildSvc(o client.Object) *corev1.Service {
 	}
 	return svc
 }
+
+// backupMetaEnv returns the env to access the data of the backup by mo_br
+func backupMetaEnv(b *v1alpha1.Backup) []corev1.EnvVar {
+	env := []corev1.EnvVar{{
+		Name:  RawMetaEnv,
+		Value: b.Meta.Raw,
+	}}
+	if b.Meta.Location.S3 != nil && b.Meta.Location.S3.SecretRef != nil {
+		for _, key := range []string{common.AWSAccessKeyID, common.AWSSecretAccessKey} {
+			env = util.UpsertByKey(env, corev1.EnvVar{Name: key, ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: *b.Meta.Location.S3.SecretRef,
+					Key:                  key,
+				},
+			}}, util.EnvVarKey)
+		}
+	}
+	return env
+}
+
+// mountStorage mounts the volume that provides the fileSystem storage to the br container of the job,
+// nothing is done if the storage is not a fileSystem or has no volume specified
+func mountStorage(job *batchv1.Job, p v1alpha1.SharedStorageProvider, volumeName string) {
+	fs := p.FileSystem
+	if fs == nil {
+		return
+	}
+	var source corev1.VolumeSource
+	switch {
+	case fs.PersistentVolumeClaim != nil:
+		source.PersistentVolumeClaim = fs.PersistentVolumeClaim.DeepCopy()
+	case fs.HostPath != nil:
+		source.HostPath = fs.HostPath.DeepCopy()
+	default:
+		return
+	}
+	podSpec := &job.Spec.Template.Spec
+	c := &podSpec.Containers[0]
+	for _, m := range c.VolumeMounts {
+		if m.MountPath == fs.Path {
+			// already mounted, e.g. restore to the same fileSystem of the backup
+			return
+		}
+	}
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{Name: volumeName, VolumeSource: source})
+	c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{Name: volumeName, MountPath: fs.Path, SubPath: fs.SubPath})
+}
+
+// cancelRequested returns whether the job is requested to be cancelled by spec.suspend or the cancel annotation
+func cancelRequested(o client.Object, suspend bool) bool {
+	return suspend || o.GetAnnotations()[JobCancelAnnoKey] != ""
+}
+
+// shouldRetry returns whether a failed job should be re-created according to the backoffLimit
+func shouldRetry(backoffLimit *int32, retries int32) bool {
+	return backoffLimit != nil && retries < *backoffLimit
+}
+
+// stopCommand stops the command that is run by the job and returns the last status of the command,
+// nil is returned if the status is not available, e.g. the job pod is not started or has exited
+func stopCommand[T client.Object](ctx *recon.Context[T], o client.Object) *cmd.Status {
+	svc := buildSvc(o)
+	host := fmt.Sprintf("%s.%s", svc.Name, svc.Namespace)
+	status, err := cmd.GetCmdStatus(host, defaultCMDRestPort)
+	if err != nil {
+		ctx.Log.Info("error get command status before stopping", "error", err.Error())
+		status = nil
+	}
+	if status == nil || !status.Completed {
+		if err := cmd.Stop(host, defaultCMDRestPort); err != nil {
+			ctx.Log.Info("error stop command", "error", err.Error())
+		}
+	}
+	return status
+}
+
+// deleteJob deletes the underlying job of the job object
+func deleteJob[T client.Object](ctx *recon.Context[T], o client.Object) error {
+	err := ctx.Delete(&batchv1.Job{ObjectMeta: common.ObjMetaTemplate(o, o.GetName())}, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	return errors.Wrap(util.Ignore(apierrors.IsNotFound, err), "error delete job")
+}
+
+// waitJobDeleted requeues the reconciliation until the job of the previous attempt is deleted
+func waitJobDeleted[T client.Object](ctx *recon.Context[T], o client.Object) error {
+	job := &batchv1.Job{}
+	err := ctx.Get(types.NamespacedName{Namespace: o.GetNamespace(), Name: o.GetName()}, job)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "error get job")
+	}
+	if job.DeletionTimestamp != nil {
+		return recon.ErrReSync("wait the job of the previous attempt deleted", retryInterval)
+	}
+	return nil
+}
+
+// jobEnded returns whether a BackupJob or RestoreJob in the phase has ended
+func jobEnded(phase string) bool {
+	return phase == v1alpha1.JobPhaseCompleted || phase == v1alpha1.JobPhaseFailed || phase == v1alpha1.JobPhaseCancelled
+}
@@ -0,0 +1,57 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func Test_cancelRequested(t *testing.T) {
+	g := NewGomegaWithT(t)
+	bj := &v1alpha1.BackupJob{}
+	g.Expect(cancelRequested(bj, bj.Spec.Suspend)).To(BeFalse())
+	bj.Spec.Suspend = true
+	g.Expect(cancelRequested(bj, bj.Spec.Suspend)).To(BeTrue())
+
+	rj := &v1alpha1.RestoreJob{ObjectMeta: metav1.ObjectMeta{
+		Annotations: map[string]string{JobCancelAnnoKey: "true"},
+	}}
+	g.Expect(cancelRequested(rj, rj.Spec.Suspend)).To(BeTrue())
+}
+
+func Test_shouldRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		backoffLimit *int32
+		retries      int32
+		want         bool
+	}{
+		{name: "no backoffLimit", want: false},
+		{name: "zero backoffLimit", backoffLimit: pointer.Int32(0), want: false},
+		{name: "within backoffLimit", backoffLimit: pointer.Int32(2), retries: 1, want: true},
+		{name: "exceed backoffLimit", backoffLimit: pointer.Int32(2), retries: 2, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(shouldRetry(tt.backoffLimit, tt.retries)).To(Equal(tt.want))
+		})
+	}
+}
@@ -28,6 +28,10 @@ type GCActor[T JobObject] struct {
 }
 
 func (r *GCActor[T]) Observe(c *recon.Context[T]) error {
+	if _, ok := c.Obj.GetLabels()[BackupScheduleLabelKey]; ok {
+		// jobs created by a BackupSchedule are retained according to the history limits of the schedule
+		return nil
+	}
 	cond, ok := recon.GetCondition(c.Obj, r.ConditionType)
 	if !ok || cond.Status == metav1.ConditionFalse {
 		// not completed, nothing to do
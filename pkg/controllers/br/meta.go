@@ -0,0 +1,188 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// the field index of the mo_br meta record:
+// id, size, path, at time, duration, complete time, backup ts, backup type
+const (
+	metaFieldID = iota
+	metaFieldSize
+	metaFieldPath
+	metaFieldAtTime
+	metaFieldDuration
+	metaFieldCompleteTime
+	metaFieldBackupTS
+	metaFieldBackupType
+)
+
+const metaTimeLayout = "2006-01-02 15:04:05 -0700"
+
+// moBRMeta is the parsed mo_br backup meta
+type moBRMeta struct {
+	ID           string
+	AtTime       time.Time
+	StartTime    time.Time
+	CompleteTime time.Time
+	Duration     time.Duration
+	BackupTS     string
+	// Size is the backup data size in bytes, -1 if unknown
+	Size int64
+}
+
+// backupMetaFromStdout extracts the raw meta that is printed after the MetaDelimiter by the backup command
+func backupMetaFromStdout(stdout string) (string, *moBRMeta, error) {
+	parts := strings.Split(stdout, MetaDelimiter)
+	if len(parts) != 2 {
+		return "", nil, errors.Errorf("error parse backup id from stdout: %s", stdout)
+	}
+	raw := strings.Trim(strings.Trim(parts[1], " "), "\n")
+	m, err := parseBackupMeta(raw)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "error parse backup meta")
+	}
+	return raw, m, nil
+}
+
+// parseBackupMeta parses the comma separated meta record that mo_br writes after a backup,
+// fields that are missing or malformed are left empty so that the record written by an older
+// mo_br can still be parsed.
+// The meta of an incremental backup also contains the records of the backups it depends on,
+// the record of the backup itself is the last line.
+func parseBackupMeta(raw string) (*moBRMeta, error) {
+	lines := strings.Split(strings.TrimSpace(raw), "\n")
+	fields := strings.Split(lines[len(lines)-1], ",")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	field := func(i int) string {
+		if i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+	m := &moBRMeta{
+		ID:       field(metaFieldID),
+		BackupTS: field(metaFieldBackupTS),
+	}
+	if m.ID == "" {
+		return nil, errors.Errorf("backup id not found in meta: %s", raw)
+	}
+	if t, err := time.Parse(metaTimeLayout, field(metaFieldAtTime)); err == nil {
+		m.StartTime = t
+		m.AtTime = t
+	}
+	if t, err := time.Parse(metaTimeLayout, field(metaFieldCompleteTime)); err == nil {
+		m.CompleteTime = t
+	}
+	if d, err := time.ParseDuration(field(metaFieldDuration)); err == nil {
+		m.Duration = d
+	} else if !m.StartTime.IsZero() && !m.CompleteTime.IsZero() {
+		m.Duration = m.CompleteTime.Sub(m.StartTime)
+	}
+	m.Size = parseSize(field(metaFieldSize))
+	// the backup ts is the consistent timestamp of the backup data, which is more accurate than
+	// the time the backup is started
+	if t, ok := parseBackupTS(m.BackupTS); ok {
+		m.AtTime = t
+	}
+	return m, nil
+}
+
+// applyTo fills the times and statistics of the backup, now is used if the time is not reported by mo_br
+func (m *moBRMeta) applyTo(meta *v1alpha1.BackupMeta, now time.Time) {
+	meta.BackupTS = m.BackupTS
+	meta.AtTime = metav1.Time{Time: now}
+	if !m.AtTime.IsZero() {
+		meta.AtTime = metav1.Time{Time: m.AtTime}
+	}
+	meta.CompleteTime = metav1.Time{Time: now}
+	if !m.CompleteTime.IsZero() {
+		meta.CompleteTime = metav1.Time{Time: m.CompleteTime}
+	}
+	if !m.StartTime.IsZero() {
+		meta.StartTime = &metav1.Time{Time: m.StartTime}
+	}
+	if m.Duration > 0 {
+		meta.Duration = &metav1.Duration{Duration: m.Duration}
+	}
+	if m.Size >= 0 {
+		meta.Size = resource.NewQuantity(m.Size, resource.BinarySI)
+		if m.Duration > 0 {
+			meta.Throughput = resource.NewQuantity(int64(float64(m.Size)/m.Duration.Seconds()), resource.BinarySI)
+		}
+	}
+}
+
+// parseBackupTS parses the physical time of a HLC timestamp in <physical nanoseconds>-<logical> format
+func parseBackupTS(ts string) (time.Time, bool) {
+	physical := strings.SplitN(ts, "-", 2)[0]
+	if physical == "" {
+		return time.Time{}, false
+	}
+	ns, err := strconv.ParseInt(physical, 10, 64)
+	if err != nil || ns <= 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, ns).UTC(), true
+}
+
+// sizeUnits are the units used by mo_br to format the backup size
+var sizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"pb":  1e15,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+}
+
+// parseSize parses a human-readable size like "586 kB" to bytes, -1 is returned if the size is malformed
+func parseSize(s string) int64 {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	num, unit := s, ""
+	if i >= 0 {
+		num, unit = s[:i], strings.TrimSpace(s[i:])
+	}
+	multiplier, ok := sizeUnits[strings.ToLower(unit)]
+	if !ok {
+		return -1
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return -1
+	}
+	return int64(math.Round(f * multiplier))
+}
@@ -0,0 +1,142 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_parseBackupMeta(t *testing.T) {
+	tests := []struct {
+		name             string
+		raw              string
+		wantErr          bool
+		wantID           string
+		wantAtTime       time.Time
+		wantCompleteTime time.Time
+	}{
+		{
+			name:             "full record",
+			raw:              "4d21b228-10dd-11ef-9497-26dd28356ef2,586 kB,BackupDir: s3  Path: bucket/backup,2024-05-13 12:00:12 +0800,1.7s,2024-05-13 12:00:13 +0800,1715572812174311000-1,full",
+			wantID:           "4d21b228-10dd-11ef-9497-26dd28356ef2",
+			wantAtTime:       time.Unix(0, 1715572812174311000).UTC(),
+			wantCompleteTime: time.Date(2024, 5, 13, 12, 0, 13, 0, time.FixedZone("", 8*3600)),
+		},
+		{
+			name:       "no backup ts",
+			raw:        "4d21b228,586 kB,path,2024-05-13 12:00:12 +0800",
+			wantID:     "4d21b228",
+			wantAtTime: time.Date(2024, 5, 13, 12, 0, 12, 0, time.FixedZone("", 8*3600)),
+		},
+		{
+			name:   "id only",
+			raw:    "4d21b228",
+			wantID: "4d21b228",
+		},
+		{
+			name:    "empty",
+			raw:     "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			m, err := parseBackupMeta(tt.raw)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(Succeed())
+			g.Expect(m.ID).To(Equal(tt.wantID))
+			g.Expect(m.AtTime.Equal(tt.wantAtTime)).To(BeTrue())
+			g.Expect(m.CompleteTime.Equal(tt.wantCompleteTime)).To(BeTrue())
+		})
+	}
+}
+
+func Test_latestBackupBefore(t *testing.T) {
+	g := NewGomegaWithT(t)
+	base := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	backup := func(name, source string, at time.Time) v1alpha1.Backup {
+		return v1alpha1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Meta: v1alpha1.BackupMeta{
+				SourceRef: source,
+				AtTime:    metav1.Time{Time: at},
+			},
+		}
+	}
+	backups := []v1alpha1.Backup{
+		backup("b1", "matrixonecluster/default/mo", base),
+		backup("b2", "matrixonecluster/default/mo", base.Add(2*time.Hour)),
+		backup("b3", "matrixonecluster/default/mo", base.Add(4*time.Hour)),
+		backup("other", "matrixonecluster/default/other", base.Add(3*time.Hour)),
+	}
+	g.Expect(latestBackupBefore(backups, "matrixonecluster/default/mo", base.Add(-time.Hour))).To(BeNil())
+	g.Expect(latestBackupBefore(backups, "matrixonecluster/default/mo", base).Name).To(Equal("b1"))
+	g.Expect(latestBackupBefore(backups, "matrixonecluster/default/mo", base.Add(3*time.Hour)).Name).To(Equal("b2"))
+	g.Expect(latestBackupBefore(backups, "matrixonecluster/default/mo", base.Add(5*time.Hour)).Name).To(Equal("b3"))
+	g.Expect(latestBackupBefore(backups, "matrixonecluster/default/other", base.Add(5*time.Hour)).Name).To(Equal("other"))
+}
+
+func Test_parseSize(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int64
+	}{
+		{s: "586 kB", want: 586000},
+		{s: "1.5 GB", want: 1500000000},
+		{s: "2MiB", want: 2 << 20},
+		{s: "100 B", want: 100},
+		{s: "100", want: 100},
+		{s: "", want: -1},
+		{s: "12 XB", want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(parseSize(tt.s)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_moBRMeta_applyTo(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC)
+	m, err := parseBackupMeta("4d21b228,100 MB,path,2024-05-13 12:00:00 +0800,50s,2024-05-13 12:00:50 +0800,,full")
+	g.Expect(err).To(Succeed())
+	meta := &v1alpha1.BackupMeta{}
+	m.applyTo(meta, now)
+	g.Expect(meta.StartTime.Time.Equal(time.Date(2024, 5, 13, 4, 0, 0, 0, time.UTC))).To(BeTrue())
+	g.Expect(meta.CompleteTime.Time.Equal(time.Date(2024, 5, 13, 4, 0, 50, 0, time.UTC))).To(BeTrue())
+	g.Expect(meta.Duration.Duration).To(Equal(50 * time.Second))
+	g.Expect(meta.Size.Value()).To(Equal(int64(100000000)))
+	g.Expect(meta.Throughput.Value()).To(Equal(int64(2000000)))
+
+	m, err = parseBackupMeta("4d21b228")
+	g.Expect(err).To(Succeed())
+	meta = &v1alpha1.BackupMeta{}
+	m.applyTo(meta, now)
+	g.Expect(meta.AtTime.Time).To(Equal(now))
+	g.Expect(meta.CompleteTime.Time).To(Equal(now))
+	g.Expect(meta.Size).To(BeNil())
+	g.Expect(meta.Duration).To(BeNil())
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "matrixone"
+	metricsSubsystem = "backup"
+
+	resultSucceeded = "succeeded"
+	resultFailed    = "failed"
+	resultCancelled = "cancelled"
+)
+
+var (
+	backupDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "duration_seconds",
+		Help:      "Time taken by the completed backups",
+		// 1m ~ 34h
+		Buckets: prometheus.ExponentialBuckets(60, 2, 12),
+	}, []string{"source", "mode"})
+
+	backupSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "size_bytes",
+		Help:      "Data size of the completed backups",
+		// 16MiB ~ 64TiB
+		Buckets: prometheus.ExponentialBuckets(1<<24, 4, 12),
+	}, []string{"source", "mode"})
+
+	backupThroughput = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "throughput_bytes_per_second",
+		Help:      "Average throughput of the latest completed backup",
+	}, []string{"source", "mode"})
+
+	backupTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "jobs_total",
+		Help:      "Number of the ended backup jobs",
+	}, []string{"source", "result"})
+)
+
+// Collectors returns the collectors of the backup metrics
+func Collectors() []prometheus.Collector {
+	return []prometheus.Collector{backupDuration, backupSize, backupThroughput, backupTotal}
+}
+
+func observeBackupSucceeded(b *v1alpha1.Backup) {
+	source, mode := b.Meta.SourceRef, string(b.Meta.Mode)
+	backupTotal.WithLabelValues(source, resultSucceeded).Inc()
+	if b.Meta.Duration != nil {
+		backupDuration.WithLabelValues(source, mode).Observe(b.Meta.Duration.Seconds())
+	}
+	if b.Meta.Size != nil {
+		backupSize.WithLabelValues(source, mode).Observe(float64(b.Meta.Size.Value()))
+	}
+	if b.Meta.Throughput != nil {
+		backupThroughput.WithLabelValues(source, mode).Set(float64(b.Meta.Throughput.Value()))
+	}
+}
+
+func observeBackupFailed(bj *v1alpha1.BackupJob) {
+	backupTotal.WithLabelValues(bj.GetSourceRef(), resultFailed).Inc()
+}
+
+func observeBackupCancelled(bj *v1alpha1.BackupJob) {
+	backupTotal.WithLabelValues(bj.GetSourceRef(), resultCancelled).Inc()
+}
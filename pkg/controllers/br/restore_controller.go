@@ -31,7 +31,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
-	"strings"
+	"time"
 )
 
 type RestoreActor struct {
@@ -45,11 +45,15 @@ func NewRestoreActor(image string) *RestoreActor {
 var _ recon.Actor[*v1alpha1.RestoreJob] = &RestoreActor{}
 
 func (c *RestoreActor) Observe(ctx *recon.Context[*v1alpha1.RestoreJob]) (recon.Action[*v1alpha1.RestoreJob], error) {
-	phase := ctx.Obj.GetPhase()
-	if phase == v1alpha1.JobPhaseFailed || phase == v1alpha1.JobPhaseCompleted {
+	rj := ctx.Obj
+	phase := rj.GetPhase()
+	if jobEnded(phase) {
 		// completed
 		return nil, nil
 	}
+	if cancelRequested(rj, rj.Spec.Suspend) {
+		return c.cancelRestore, nil
+	}
 	if phase == v1alpha1.JobPhaseRunning {
 		return c.waitJob, nil
 	}
@@ -66,7 +70,7 @@ func (c *RestoreActor) waitJob(ctx *recon.Context[*v1alpha1.RestoreJob]) error {
 		return errors.Wrap(err, "error get backup job")
 	}
 	if job.Status.Failed > 0 {
-		return c.failRestore(ctx, "backup job is failed")
+		return c.retryOrFail(ctx, "restore job is failed")
 	}
 	svc := buildSvc(rj)
 	status, err := cmd.GetCmdStatus(fmt.Sprintf("%s.%s", svc.Name, svc.Namespace), defaultCMDRestPort)
@@ -80,7 +84,47 @@ func (c *RestoreActor) waitJob(ctx *recon.Context[*v1alpha1.RestoreJob]) error {
 	if status.ExitCode == 0 {
 		return c.successRestore(ctx)
 	}
-	return c.failRestore(ctx, status.Stderr)
+	return c.retryOrFail(ctx, status.Stderr)
+}
+
+// retryOrFail re-creates the restore job if the backoffLimit is not exceeded, otherwise the restore is failed
+func (c *RestoreActor) retryOrFail(ctx *recon.Context[*v1alpha1.RestoreJob], msg string) error {
+	rj := ctx.Obj
+	if !shouldRetry(rj.Spec.BackoffLimit, rj.Status.Retries) {
+		return c.failRestore(ctx, msg)
+	}
+	if err := deleteJob(ctx, rj); err != nil {
+		return err
+	}
+	rj.Status.Retries++
+	ctx.Event.EmitEventGeneric("JobRetrying", fmt.Sprintf("retry restore (%d/%d): %s", rj.Status.Retries, *rj.Spec.BackoffLimit, msg), nil)
+	rj.Status.Phase = v1alpha1.JobPhasePending
+	meta.SetStatusCondition(&rj.Status.Conditions, metav1.Condition{
+		Type:    v1alpha1.JobConditionTypeEnded,
+		Status:  metav1.ConditionFalse,
+		Reason:  "JobRetrying",
+		Message: msg,
+	})
+	return ctx.UpdateStatus(rj)
+}
+
+// cancelRestore stops the running restore, the data that has been restored to the target is left as is
+func (c *RestoreActor) cancelRestore(ctx *recon.Context[*v1alpha1.RestoreJob]) error {
+	rj := ctx.Obj
+	if rj.Status.Phase == v1alpha1.JobPhaseRunning {
+		stopCommand(ctx, rj)
+	}
+	if err := deleteJob(ctx, rj); err != nil {
+		return err
+	}
+	ctx.Event.EmitEventGeneric("JobCancelled", "restore is cancelled, the partially restored data in the target should be cleaned before reuse", nil)
+	rj.Status.Phase = v1alpha1.JobPhaseCancelled
+	meta.SetStatusCondition(&rj.Status.Conditions, metav1.Condition{
+		Type:   v1alpha1.JobConditionTypeEnded,
+		Status: metav1.ConditionTrue,
+		Reason: "JobCancelled",
+	})
+	return ctx.UpdateStatus(rj)
 }
 
 func (c *RestoreActor) failRestore(ctx *recon.Context[*v1alpha1.RestoreJob], msg string) error {
@@ -116,53 +160,61 @@ func (c *RestoreActor) syncJob(ctx *recon.Context[*v1alpha1.RestoreJob]) error {
 	if rj.Status.Phase == "" {
 		rj.Status.Phase = v1alpha1.JobPhasePending
 	}
+	if err := waitJobDeleted(ctx, rj); err != nil {
+		return err
+	}
 	restoreCmd := &RestoreCommand{}
-	backup := &v1alpha1.Backup{}
-	if err := ctx.Get(types.NamespacedName{Name: rj.Spec.BackupName}, backup); err != nil {
-		return errors.Wrap(err, "error get backup")
+	backup, err := c.resolveBackup(ctx)
+	if err != nil {
+		return err
 	}
-	restoreCmd.BackupID = backup.Meta.ID
-	optionalSourceSecret := backup.Meta.Location.S3.SecretRef
-	restoreCmd.ReadSourceEnvSecret = optionalSourceSecret != nil
-	restoreCmd.Target.Endpoint = rj.Spec.Target.S3.Endpoint
-	restoreCmd.Target.Type = string(rj.Spec.Target.S3.GetProviderType())
-	parts := strings.SplitN(rj.Spec.Target.S3.Path, "/", 2)
-	restoreCmd.Target.Bucket = parts[0]
-	if len(parts) > 1 {
-		restoreCmd.Target.Path = parts[1]
-	}
-	optionalTargetSecret := rj.Spec.Target.S3.SecretRef
-	restoreCmd.Target.ReadEnvSecret = optionalTargetSecret != nil
-	job := buildJob(rj, c.restoreImage, restoreCmd.String(), func(c *corev1.Container) {
-		c.Env = []corev1.EnvVar{{
-			Name:  RawMetaEnv,
-			Value: backup.Meta.Raw,
-		}}
-		if optionalSourceSecret != nil {
-			for _, key := range []string{common.AWSAccessKeyID, common.AWSSecretAccessKey} {
-				c.Env = util.UpsertByKey(c.Env, corev1.EnvVar{Name: key, ValueFrom: &corev1.EnvVarSource{
-					SecretKeyRef: &corev1.SecretKeySelector{
-						LocalObjectReference: *optionalSourceSecret,
-						Key:                  key,
-					},
-				}}, util.EnvVarKey)
+	if backup == nil {
+		return c.failRestore(ctx, fmt.Sprintf("no backup of %s is available before %s", rj.Spec.SourceRef, rj.Spec.LatestBackupBefore))
+	}
+	if !backup.RestorableTo(rj.Namespace) {
+		return c.failRestore(ctx, fmt.Sprintf("backup %s cannot be restored to namespace %s", backup.Name, rj.Namespace))
+	}
+	// an incremental backup can only be restored along with the backups it depends on
+	for _, name := range backup.Meta.Chain {
+		if err := ctx.Get(types.NamespacedName{Name: name}, &v1alpha1.Backup{}); err != nil {
+			if apierrors.IsNotFound(err) {
+				return c.failRestore(ctx, fmt.Sprintf("backup %s that %s depends on is not found", name, backup.Name))
 			}
+			return errors.Wrap(err, "error get base backup")
 		}
+	}
+	rj.Status.Backup = backup.Name
+	rj.Status.RestoredTime = backup.Meta.AtTime.DeepCopy()
+	restoreCmd.BackupID = backup.Meta.ID
+	restoreCmd.ReadSourceEnvSecret = backup.Meta.Location.S3 != nil && backup.Meta.Location.S3.SecretRef != nil
+	target, err := newStorage(rj.Spec.Target)
+	if err != nil {
+		return errors.Wrap(err, "bad restore target")
+	}
+	restoreCmd.Target = target
+	var optionalTargetSecret *corev1.LocalObjectReference
+	if rj.Spec.Target.S3 != nil {
+		optionalTargetSecret = rj.Spec.Target.S3.SecretRef
+	}
+	job := buildJob(rj, c.restoreImage, restoreCmd.String(), func(c *corev1.Container) {
+		c.Env = backupMetaEnv(backup)
 		if optionalTargetSecret != nil {
 			c.Env = util.UpsertByKey(c.Env, corev1.EnvVar{Name: RestoreAccessEnvKey, ValueFrom: &corev1.EnvVarSource{
 				SecretKeyRef: &corev1.SecretKeySelector{
-					LocalObjectReference: *optionalSourceSecret,
+					LocalObjectReference: *optionalTargetSecret,
 					Key:                  common.AWSAccessKeyID,
 				},
 			}}, util.EnvVarKey)
 			c.Env = util.UpsertByKey(c.Env, corev1.EnvVar{Name: RestoreSecretEnvKey, ValueFrom: &corev1.EnvVarSource{
 				SecretKeyRef: &corev1.SecretKeySelector{
-					LocalObjectReference: *optionalSourceSecret,
+					LocalObjectReference: *optionalTargetSecret,
 					Key:                  common.AWSSecretAccessKey,
 				},
 			}}, util.EnvVarKey)
 		}
 	})
+	mountStorage(job, backup.Meta.Location, "backup")
+	mountStorage(job, rj.Spec.Target, "restore")
 	svc := buildSvc(rj)
 	if err := util.Ignore(apierrors.IsAlreadyExists, ctx.CreateOwned(job)); err != nil {
 		return errors.Wrap(err, "error ensure job")
@@ -174,6 +226,49 @@ func (c *RestoreActor) syncJob(ctx *recon.Context[*v1alpha1.RestoreJob]) error {
 	return ctx.UpdateStatus(rj)
 }
 
+// resolveBackup returns the backup to restore, nil is returned if there is no backup available
+// before the requested time
+func (c *RestoreActor) resolveBackup(ctx *recon.Context[*v1alpha1.RestoreJob]) (*v1alpha1.Backup, error) {
+	rj := ctx.Obj
+	name := rj.Spec.BackupName
+	if rj.Status.Backup != "" {
+		// the backup has been chosen in previous round
+		name = rj.Status.Backup
+	} else if rj.Spec.LatestBackupBefore != nil {
+		backupList := &v1alpha1.BackupList{}
+		if err := ctx.List(backupList, client.MatchingFields{v1alpha1.BackupSourceRefField: rj.Spec.SourceRef}); err != nil {
+			return nil, errors.Wrap(err, "error list backups")
+		}
+		var restorable []v1alpha1.Backup
+		for _, b := range backupList.Items {
+			if b.RestorableTo(rj.Namespace) {
+				restorable = append(restorable, b)
+			}
+		}
+		return latestBackupBefore(restorable, rj.Spec.SourceRef, rj.Spec.LatestBackupBefore.Time), nil
+	}
+	backup := &v1alpha1.Backup{}
+	if err := ctx.Get(types.NamespacedName{Name: name}, backup); err != nil {
+		return nil, errors.Wrap(err, "error get backup")
+	}
+	return backup, nil
+}
+
+// latestBackupBefore returns the latest backup of the source that is consistent at or before the given time
+func latestBackupBefore(backups []v1alpha1.Backup, sourceRef string, t time.Time) *v1alpha1.Backup {
+	var chosen *v1alpha1.Backup
+	for i := range backups {
+		b := &backups[i]
+		if b.Meta.SourceRef != sourceRef || b.DeletionTimestamp != nil || b.Meta.AtTime.Time.After(t) {
+			continue
+		}
+		if chosen == nil || chosen.Meta.AtTime.Before(&b.Meta.AtTime) {
+			chosen = b
+		}
+	}
+	return chosen
+}
+
 func (c *RestoreActor) Finalize(ctx *recon.Context[*v1alpha1.RestoreJob]) (bool, error) {
 	rj := ctx.Obj
 	err := ctx.Delete(&batchv1.Job{ObjectMeta: common.ObjMetaTemplate(rj, rj.Name)}, client.PropagationPolicy(metav1.DeletePropagationBackground))
@@ -0,0 +1,170 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/matrixorigin/controller-runtime/pkg/observer"
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/controller-runtime/pkg/util"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// BackupExpiredAnnoKey marks a Backup that is expired by a retention policy,
+	// the backup data of an expired Backup will be deleted along with the Backup object
+	BackupExpiredAnnoKey = "matrixorigin.io/backup-expired"
+
+	retentionResyncInterval = 1 * time.Hour
+)
+
+// expiredBackups returns the backups that are not retained by any rule of the policy
+func expiredBackups(policy *v1alpha1.BackupRetentionPolicy, backups []v1alpha1.Backup, now time.Time) []v1alpha1.Backup {
+	if policy == nil || policy.IsEmpty() {
+		return nil
+	}
+	sorted := append([]v1alpha1.Backup{}, backups...)
+	// newest first
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[j].Meta.AtTime.Before(&sorted[i].Meta.AtTime)
+	})
+	keep := make([]bool, len(sorted))
+	if policy.KeepLast != nil {
+		for i := 0; i < len(sorted) && i < int(*policy.KeepLast); i++ {
+			keep[i] = true
+		}
+	}
+	if policy.KeepFor != nil {
+		for i := range sorted {
+			if now.Sub(sorted[i].Meta.AtTime.Time) <= policy.KeepFor.Duration {
+				keep[i] = true
+			}
+		}
+	}
+	keepLatestPerBucket(sorted, keep, policy.KeepDaily, func(t time.Time) string {
+		return t.UTC().Format("2006-01-02")
+	})
+	keepLatestPerBucket(sorted, keep, policy.KeepWeekly, func(t time.Time) string {
+		year, week := t.UTC().ISOWeek()
+		return fmt.Sprintf("%d-%d", year, week)
+	})
+	keepLatestPerBucket(sorted, keep, policy.KeepMonthly, func(t time.Time) string {
+		return t.UTC().Format("2006-01")
+	})
+	// a backup cannot be expired while an retained incremental backup depends on it
+	index := map[string]int{}
+	for i := range sorted {
+		index[sorted[i].Name] = i
+	}
+	for i := range sorted {
+		if !keep[i] {
+			continue
+		}
+		for _, name := range sorted[i].Meta.Chain {
+			if j, ok := index[name]; ok {
+				keep[j] = true
+			}
+		}
+	}
+	var expired []v1alpha1.Backup
+	for i := range sorted {
+		if !keep[i] {
+			expired = append(expired, sorted[i])
+		}
+	}
+	return expired
+}
+
+// keepLatestPerBucket marks the newest backup of each of the latest n time buckets as kept,
+// backups must be sorted from newest to oldest
+func keepLatestPerBucket(sorted []v1alpha1.Backup, keep []bool, n *int32, bucketFn func(time.Time) string) {
+	if n == nil {
+		return
+	}
+	seen := map[string]bool{}
+	for i := range sorted {
+		if len(seen) >= int(*n) {
+			return
+		}
+		bucket := bucketFn(sorted[i].Meta.AtTime.Time)
+		if !seen[bucket] {
+			seen[bucket] = true
+			keep[i] = true
+		}
+	}
+}
+
+// applyRetention expires the backups that are not retained by the policy
+func applyRetention(kubeCli recon.KubeClient, policy *v1alpha1.BackupRetentionPolicy, backups []v1alpha1.Backup) error {
+	for _, b := range expiredBackups(policy, backups, time.Now()) {
+		if b.DeletionTimestamp != nil {
+			continue
+		}
+		backup := b.DeepCopy()
+		if err := kubeCli.Patch(backup, func() error {
+			if backup.Annotations == nil {
+				backup.Annotations = map[string]string{}
+			}
+			backup.Annotations[BackupExpiredAnnoKey] = "true"
+			return nil
+		}); err != nil {
+			return errors.Wrapf(err, "error mark backup %s as expired", backup.Name)
+		}
+		if err := util.Ignore(apierrors.IsNotFound, kubeCli.Delete(backup)); err != nil {
+			return errors.Wrapf(err, "error delete expired backup %s", backup.Name)
+		}
+	}
+	return nil
+}
+
+// ClusterRetentionActor applies the backup retention policy of MatrixOneClusters
+type ClusterRetentionActor struct{}
+
+var _ observer.Observer[*v1alpha1.MatrixOneCluster] = &ClusterRetentionActor{}
+
+func (r *ClusterRetentionActor) Observe(ctx *recon.Context[*v1alpha1.MatrixOneCluster]) error {
+	mo := ctx.Obj
+	if mo.Spec.BackupRetention == nil {
+		return nil
+	}
+	sourceRef := fmt.Sprintf("matrixonecluster/%s/%s", mo.Namespace, mo.Name)
+	backupList := &v1alpha1.BackupList{}
+	if err := ctx.List(backupList, client.MatchingFields{v1alpha1.BackupSourceRefField: sourceRef}); err != nil {
+		return errors.Wrap(err, "error list backups")
+	}
+	var backups []v1alpha1.Backup
+	for _, b := range backupList.Items {
+		if _, ok := b.Labels[BackupScheduleLabelKey]; ok {
+			// follow the retention policy of the schedule
+			continue
+		}
+		backups = append(backups, b)
+	}
+	if err := applyRetention(ctx, mo.Spec.BackupRetention, backups); err != nil {
+		return err
+	}
+	return recon.ErrReSync("wait next retention check", retentionResyncInterval)
+}
+
+func (r *ClusterRetentionActor) Reconcile(mgr manager.Manager) error {
+	return observer.Setup[*v1alpha1.MatrixOneCluster](&v1alpha1.MatrixOneCluster{}, "backup-retention", mgr, r)
+}
@@ -0,0 +1,134 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func Test_expiredBackups(t *testing.T) {
+	now := time.Date(2023, 9, 30, 12, 0, 0, 0, time.UTC)
+	// one backup every 12 hours since 2023-08-31, newest first: b0, b1, ...
+	var backups []v1alpha1.Backup
+	for i := 0; i < 62; i++ {
+		backups = append(backups, v1alpha1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("b%d", i)},
+			Meta: v1alpha1.BackupMeta{
+				AtTime: metav1.Time{Time: now.Add(-time.Duration(i) * 12 * time.Hour)},
+			},
+		})
+	}
+	names := func(bs []v1alpha1.Backup) map[string]bool {
+		m := map[string]bool{}
+		for _, b := range bs {
+			m[b.Name] = true
+		}
+		return m
+	}
+	tests := []struct {
+		name     string
+		policy   *v1alpha1.BackupRetentionPolicy
+		wantKept int
+		kept     []string
+	}{
+		{
+			name:     "nil policy",
+			policy:   nil,
+			wantKept: 62,
+		},
+		{
+			name:     "empty policy",
+			policy:   &v1alpha1.BackupRetentionPolicy{},
+			wantKept: 62,
+		},
+		{
+			name:     "keep last",
+			policy:   &v1alpha1.BackupRetentionPolicy{KeepLast: pointer.Int32(3)},
+			wantKept: 3,
+			kept:     []string{"b0", "b1", "b2"},
+		},
+		{
+			name:     "keep for",
+			policy:   &v1alpha1.BackupRetentionPolicy{KeepFor: &metav1.Duration{Duration: 48 * time.Hour}},
+			wantKept: 5,
+			kept:     []string{"b0", "b4"},
+		},
+		{
+			name:     "keep daily",
+			policy:   &v1alpha1.BackupRetentionPolicy{KeepDaily: pointer.Int32(3)},
+			wantKept: 3,
+			// b0 is taken at 12:00 and b1 at 00:00 of the same day, only the latest one is kept
+			kept: []string{"b0", "b2", "b4"},
+		},
+		{
+			name: "rules are unioned",
+			policy: &v1alpha1.BackupRetentionPolicy{
+				KeepLast:    pointer.Int32(2),
+				KeepMonthly: pointer.Int32(2),
+			},
+			// b0, b1 by keepLast, the latest of September (b0) and August (b60)
+			wantKept: 3,
+			kept:     []string{"b0", "b1", "b60"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			expired := expiredBackups(tt.policy, backups, now)
+			g.Expect(len(backups) - len(expired)).To(Equal(tt.wantKept))
+			expiredNames := names(expired)
+			for _, k := range tt.kept {
+				g.Expect(expiredNames).NotTo(HaveKey(k))
+			}
+		})
+	}
+}
+
+func Test_expiredBackups_incrementalChain(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Date(2023, 9, 30, 12, 0, 0, 0, time.UTC)
+	backup := func(name string, hoursAgo int, chain ...string) v1alpha1.Backup {
+		return v1alpha1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Meta: v1alpha1.BackupMeta{
+				AtTime: metav1.Time{Time: now.Add(-time.Duration(hoursAgo) * time.Hour)},
+				Chain:  chain,
+			},
+		}
+	}
+	backups := []v1alpha1.Backup{
+		backup("full-1", 10),
+		backup("incr-1", 8, "full-1"),
+		backup("incr-2", 6, "full-1", "incr-1"),
+		backup("full-2", 4),
+		backup("incr-3", 2, "full-2"),
+	}
+	expired := expiredBackups(&v1alpha1.BackupRetentionPolicy{KeepLast: pointer.Int32(1)}, backups, now)
+	g.Expect(expired).To(HaveLen(3))
+	for _, b := range expired {
+		g.Expect(b.Name).NotTo(BeElementOf("incr-3", "full-2"))
+	}
+
+	// incr-2 is retained, so is the whole chain
+	expired = expiredBackups(&v1alpha1.BackupRetentionPolicy{KeepFor: &metav1.Duration{Duration: 7 * time.Hour}}, backups, now)
+	g.Expect(expired).To(BeEmpty())
+}
@@ -0,0 +1,265 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/controller-runtime/pkg/util"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// BackupScheduleLabelKey labels the BackupJobs created by a BackupSchedule
+	BackupScheduleLabelKey = "matrixorigin.io/backup-schedule"
+
+	// maxMissedSchedules bounds the iteration when catching up missed schedules, only the
+	// latest missed schedule will be run anyway
+	maxMissedSchedules = 100
+
+	conditionTypeScheduled = "Scheduled"
+
+	// conditionTypeBackupVerified reflects the verification result of the latest verified backup,
+	// so that a corrupt backup can be alerted on the schedule
+	conditionTypeBackupVerified = "BackupVerified"
+)
+
+type ScheduleActor struct{}
+
+func NewScheduleActor() *ScheduleActor {
+	return &ScheduleActor{}
+}
+
+var _ recon.Actor[*v1alpha1.BackupSchedule] = &ScheduleActor{}
+
+func (c *ScheduleActor) Observe(ctx *recon.Context[*v1alpha1.BackupSchedule]) (recon.Action[*v1alpha1.BackupSchedule], error) {
+	bs := ctx.Obj
+	sched, err := cron.ParseStandard(bs.Spec.Schedule)
+	if err != nil {
+		// a bad schedule cannot be fixed by retrying, wait for the next spec change
+		bs.Status.SetCondition(metav1.Condition{
+			Type:    conditionTypeScheduled,
+			Status:  metav1.ConditionFalse,
+			Reason:  "InvalidSchedule",
+			Message: err.Error(),
+		})
+		return nil, nil
+	}
+
+	jobList := &v1alpha1.BackupJobList{}
+	if err := ctx.List(jobList, client.InNamespace(bs.Namespace), client.MatchingLabels{BackupScheduleLabelKey: bs.Name}); err != nil {
+		return nil, errors.Wrap(err, "error list backup jobs of schedule")
+	}
+	active, succeeded, failed := classifyBackupJobs(jobList.Items)
+	bs.Status.Active = nil
+	for _, bj := range active {
+		bs.Status.Active = append(bs.Status.Active, bj.Name)
+	}
+	for _, bj := range succeeded {
+		if cond, ok := recon.GetCondition(&bj, v1alpha1.JobConditionTypeEnded); ok {
+			if bs.Status.LastSuccessfulTime == nil || bs.Status.LastSuccessfulTime.Before(&cond.LastTransitionTime) {
+				bs.Status.LastSuccessfulTime = cond.LastTransitionTime.DeepCopy()
+			}
+		}
+	}
+	if err := c.cleanupHistory(ctx, succeeded, bs.GetSuccessfulJobsHistoryLimit()); err != nil {
+		return nil, errors.Wrap(err, "error cleanup successful backup jobs")
+	}
+	if err := c.cleanupHistory(ctx, failed, bs.GetFailedJobsHistoryLimit()); err != nil {
+		return nil, errors.Wrap(err, "error cleanup failed backup jobs")
+	}
+	backupList := &v1alpha1.BackupList{}
+	if err := ctx.List(backupList, client.MatchingLabels{
+		BackupScheduleLabelKey:   bs.Name,
+		common.NamespaceLabelKey: bs.Namespace,
+	}); err != nil {
+		return nil, errors.Wrap(err, "error list backups of schedule")
+	}
+	if cond, ok := latestVerification(backupList.Items); ok {
+		bs.Status.SetCondition(cond)
+	}
+	if bs.Spec.Retention != nil {
+		if err := applyRetention(ctx, bs.Spec.Retention, backupList.Items); err != nil {
+			return nil, errors.Wrap(err, "error apply retention policy")
+		}
+	}
+
+	if bs.IsSuspended() {
+		bs.Status.NextScheduleTime = nil
+		bs.Status.SetCondition(metav1.Condition{
+			Type:   conditionTypeScheduled,
+			Status: metav1.ConditionFalse,
+			Reason: "Suspended",
+		})
+		return nil, nil
+	}
+
+	now := time.Now()
+	missed, next := getScheduleTimes(bs, sched, now)
+	bs.Status.NextScheduleTime = &metav1.Time{Time: next}
+	bs.Status.SetCondition(metav1.Condition{
+		Type:   conditionTypeScheduled,
+		Status: metav1.ConditionTrue,
+		Reason: "Scheduled",
+	})
+	if missed.IsZero() {
+		return nil, recon.ErrReSync("wait next schedule", next.Sub(now))
+	}
+	return func(ctx *recon.Context[*v1alpha1.BackupSchedule]) error {
+		return c.runSchedule(ctx, active, missed)
+	}, nil
+}
+
+func (c *ScheduleActor) runSchedule(ctx *recon.Context[*v1alpha1.BackupSchedule], active []v1alpha1.BackupJob, scheduledTime time.Time) error {
+	bs := ctx.Obj
+	switch bs.GetConcurrencyPolicy() {
+	case v1alpha1.ConcurrencyPolicyForbid:
+		if len(active) > 0 {
+			ctx.Event.EmitEventGeneric("BackupSkipped", fmt.Sprintf("skip schedule at %s since backup job %s is still running", scheduledTime, active[0].Name), nil)
+			bs.Status.LastScheduleTime = &metav1.Time{Time: scheduledTime}
+			return ctx.UpdateStatus(bs)
+		}
+	case v1alpha1.ConcurrencyPolicyReplace:
+		for i := range active {
+			if err := util.Ignore(apierrors.IsNotFound, ctx.Delete(&active[i])); err != nil {
+				return errors.Wrapf(err, "error replace running backup job %s", active[i].Name)
+			}
+		}
+	}
+	bj := backupJobForSchedule(bs, scheduledTime)
+	if err := util.Ignore(apierrors.IsAlreadyExists, ctx.CreateOwned(bj)); err != nil {
+		return errors.Wrap(err, "error create backup job")
+	}
+	bs.Status.LastScheduleTime = &metav1.Time{Time: scheduledTime}
+	bs.Status.Active = append(bs.Status.Active, bj.Name)
+	return ctx.UpdateStatus(bs)
+}
+
+// cleanupHistory deletes the oldest ended BackupJobs that exceed the history limit
+func (c *ScheduleActor) cleanupHistory(ctx *recon.Context[*v1alpha1.BackupSchedule], jobs []v1alpha1.BackupJob, limit int32) error {
+	if int32(len(jobs)) <= limit {
+		return nil
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreationTimestamp.Before(&jobs[j].CreationTimestamp)
+	})
+	for i := 0; i < len(jobs)-int(limit); i++ {
+		if err := util.Ignore(apierrors.IsNotFound, ctx.Delete(&jobs[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ScheduleActor) Finalize(ctx *recon.Context[*v1alpha1.BackupSchedule]) (bool, error) {
+	// BackupJobs are owned by the schedule and will be garbage collected by kubernetes
+	return true, nil
+}
+
+func (c *ScheduleActor) Reconcile(mgr manager.Manager) error {
+	return recon.Setup[*v1alpha1.BackupSchedule](&v1alpha1.BackupSchedule{}, "backupschedule", mgr, c, recon.WithBuildFn(func(b *builder.Builder) {
+		b.Owns(&v1alpha1.BackupJob{})
+	}))
+}
+
+// getScheduleTimes returns the latest missed schedule time (zero if there is none) and the next schedule time
+func getScheduleTimes(bs *v1alpha1.BackupSchedule, sched cron.Schedule, now time.Time) (missed time.Time, next time.Time) {
+	earliest := bs.CreationTimestamp.Time
+	if bs.Status.LastScheduleTime != nil {
+		earliest = bs.Status.LastScheduleTime.Time
+	}
+	t := sched.Next(earliest)
+	for i := 0; !t.After(now) && i < maxMissedSchedules; i++ {
+		missed = t
+		t = sched.Next(t)
+	}
+	if !t.After(now) {
+		// too many missed schedules, fast-forward
+		missed = now
+		t = sched.Next(now)
+	}
+	return missed, t
+}
+
+// latestVerification returns the verification result of the latest verified backup
+func latestVerification(backups []v1alpha1.Backup) (metav1.Condition, bool) {
+	var latest *v1alpha1.Backup
+	var verified *metav1.Condition
+	for i := range backups {
+		b := &backups[i]
+		cond, ok := recon.GetCondition(b, v1alpha1.BackupConditionTypeVerified)
+		if !ok || cond.Status == metav1.ConditionUnknown {
+			continue
+		}
+		if latest == nil || latest.Meta.AtTime.Before(&b.Meta.AtTime) {
+			latest, verified = b, cond
+		}
+	}
+	if latest == nil {
+		return metav1.Condition{}, false
+	}
+	msg := fmt.Sprintf("backup %s is verified", latest.Name)
+	if verified.Status == metav1.ConditionFalse {
+		msg = fmt.Sprintf("backup %s failed verification: %s", latest.Name, verified.Message)
+	}
+	return metav1.Condition{
+		Type:    conditionTypeBackupVerified,
+		Status:  verified.Status,
+		Reason:  verified.Reason,
+		Message: msg,
+	}, true
+}
+
+func classifyBackupJobs(jobs []v1alpha1.BackupJob) (active, succeeded, failed []v1alpha1.BackupJob) {
+	for _, bj := range jobs {
+		switch bj.Status.Phase {
+		case v1alpha1.JobPhaseCompleted:
+			succeeded = append(succeeded, bj)
+		case v1alpha1.JobPhaseFailed, v1alpha1.JobPhaseCancelled:
+			failed = append(failed, bj)
+		default:
+			if bj.DeletionTimestamp == nil {
+				active = append(active, bj)
+			}
+		}
+	}
+	return active, succeeded, failed
+}
+
+func backupJobForSchedule(bs *v1alpha1.BackupSchedule, scheduledTime time.Time) *v1alpha1.BackupJob {
+	spec := bs.Spec.BackupTemplate.DeepCopy()
+	return &v1alpha1.BackupJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: bs.Namespace,
+			// use the schedule time in minutes as the suffix so that a schedule is only executed once
+			Name: fmt.Sprintf("%s-%d", bs.Name, scheduledTime.Unix()/60),
+			Labels: map[string]string{
+				BackupScheduleLabelKey: bs.Name,
+			},
+		},
+		Spec: *spec,
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	. "github.com/onsi/gomega"
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_getScheduleTimes(t *testing.T) {
+	base := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		lastSchedule *time.Time
+		now          time.Time
+		wantMissed   time.Time
+		wantNext     time.Time
+	}{
+		{
+			name:       "not yet scheduled",
+			now:        base.Add(30 * time.Minute),
+			wantMissed: time.Time{},
+			wantNext:   base.Add(time.Hour),
+		},
+		{
+			name:       "first schedule is due",
+			now:        base.Add(61 * time.Minute),
+			wantMissed: base.Add(time.Hour),
+			wantNext:   base.Add(2 * time.Hour),
+		},
+		{
+			name:         "only the latest missed schedule is run",
+			lastSchedule: func() *time.Time { t := base.Add(time.Hour); return &t }(),
+			now:          base.Add(3*time.Hour + time.Minute),
+			wantMissed:   base.Add(3 * time.Hour),
+			wantNext:     base.Add(4 * time.Hour),
+		},
+		{
+			name:         "already scheduled",
+			lastSchedule: func() *time.Time { t := base.Add(time.Hour); return &t }(),
+			now:          base.Add(time.Hour + time.Minute),
+			wantMissed:   time.Time{},
+			wantNext:     base.Add(2 * time.Hour),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			sched, err := cron.ParseStandard("0 * * * *")
+			g.Expect(err).To(Succeed())
+			bs := &v1alpha1.BackupSchedule{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.Time{Time: base}},
+			}
+			if tt.lastSchedule != nil {
+				bs.Status.LastScheduleTime = &metav1.Time{Time: *tt.lastSchedule}
+			}
+			missed, next := getScheduleTimes(bs, sched, tt.now)
+			g.Expect(missed).To(Equal(tt.wantMissed))
+			g.Expect(next).To(Equal(tt.wantNext))
+		})
+	}
+}
+
+func Test_classifyBackupJobs(t *testing.T) {
+	g := NewGomegaWithT(t)
+	jobs := []v1alpha1.BackupJob{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pending"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "running"}, Status: v1alpha1.BackupJobStatus{Phase: v1alpha1.JobPhaseRunning}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "completed"}, Status: v1alpha1.BackupJobStatus{Phase: v1alpha1.JobPhaseCompleted}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "failed"}, Status: v1alpha1.BackupJobStatus{Phase: v1alpha1.JobPhaseFailed}},
+	}
+	active, succeeded, failed := classifyBackupJobs(jobs)
+	g.Expect(active).To(HaveLen(2))
+	g.Expect(succeeded).To(HaveLen(1))
+	g.Expect(succeeded[0].Name).To(Equal("completed"))
+	g.Expect(failed).To(HaveLen(1))
+	g.Expect(failed[0].Name).To(Equal("failed"))
+}
+
+func Test_latestVerification(t *testing.T) {
+	g := NewGomegaWithT(t)
+	base := time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC)
+	backup := func(name string, at time.Time, verified metav1.ConditionStatus) v1alpha1.Backup {
+		b := v1alpha1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Meta:       v1alpha1.BackupMeta{AtTime: metav1.Time{Time: at}},
+		}
+		if verified != "" {
+			b.SetCondition(metav1.Condition{Type: v1alpha1.BackupConditionTypeVerified, Status: verified, Reason: "Test"})
+		}
+		return b
+	}
+	_, ok := latestVerification([]v1alpha1.Backup{backup("b1", base, "")})
+	g.Expect(ok).To(BeFalse())
+
+	cond, ok := latestVerification([]v1alpha1.Backup{
+		backup("b1", base, metav1.ConditionTrue),
+		backup("b2", base.Add(time.Hour), metav1.ConditionFalse),
+		backup("b3", base.Add(2*time.Hour), metav1.ConditionUnknown),
+		backup("b4", base.Add(3*time.Hour), ""),
+	})
+	g.Expect(ok).To(BeTrue())
+	g.Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(cond.Message).To(ContainSubstring("b2"))
+}
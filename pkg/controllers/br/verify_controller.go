@@ -0,0 +1,184 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package br
+
+import (
+	"fmt"
+
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/controller-runtime/pkg/util"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/cmd"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+type VerifyActor struct {
+	image string
+}
+
+func NewVerifyActor(image string) *VerifyActor {
+	return &VerifyActor{image: image}
+}
+
+var _ recon.Actor[*v1alpha1.BackupVerification] = &VerifyActor{}
+
+func (c *VerifyActor) Observe(ctx *recon.Context[*v1alpha1.BackupVerification]) (recon.Action[*v1alpha1.BackupVerification], error) {
+	phase := ctx.Obj.GetPhase()
+	if phase == v1alpha1.JobPhaseFailed || phase == v1alpha1.JobPhaseCompleted {
+		// completed
+		return nil, nil
+	}
+	if phase == v1alpha1.JobPhaseRunning {
+		return c.waitJob, nil
+	}
+	return c.syncJob, nil
+}
+
+func (c *VerifyActor) syncJob(ctx *recon.Context[*v1alpha1.BackupVerification]) error {
+	bv := ctx.Obj
+	if bv.Status.Phase == "" {
+		bv.Status.Phase = v1alpha1.JobPhasePending
+	}
+	backup := &v1alpha1.Backup{}
+	if err := ctx.Get(types.NamespacedName{Name: bv.Spec.BackupName}, backup); err != nil {
+		return errors.Wrap(err, "error get backup")
+	}
+	verifyCmd := &VerifyCommand{
+		BackupID:      backup.Meta.ID,
+		ReadEnvSecret: backup.Meta.Location.S3 != nil && backup.Meta.Location.S3.SecretRef != nil,
+	}
+	job := buildJob(bv, c.image, verifyCmd.String(), func(c *corev1.Container) {
+		c.Env = backupMetaEnv(backup)
+	})
+	mountStorage(job, backup.Meta.Location, "backup")
+	if err := util.Ignore(apierrors.IsAlreadyExists, ctx.CreateOwned(job)); err != nil {
+		return errors.Wrap(err, "error ensure job")
+	}
+	if err := util.Ignore(apierrors.IsAlreadyExists, ctx.CreateOwned(buildSvc(bv))); err != nil {
+		return errors.Wrap(err, "error ensure service")
+	}
+	if err := c.setVerified(ctx, metav1.ConditionUnknown, "Verifying", fmt.Sprintf("verifying by %s/%s", bv.Namespace, bv.Name)); err != nil {
+		return err
+	}
+	bv.Status.Phase = v1alpha1.JobPhaseRunning
+	return ctx.UpdateStatus(bv)
+}
+
+func (c *VerifyActor) waitJob(ctx *recon.Context[*v1alpha1.BackupVerification]) error {
+	bv := ctx.Obj
+	job := &batchv1.Job{}
+	if err := ctx.Get(types.NamespacedName{Namespace: bv.Namespace, Name: bv.Name}, job); err != nil {
+		if apierrors.IsNotFound(err) {
+			return c.failVerify(ctx, "job is cleaned externally")
+		}
+		return errors.Wrap(err, "error get verify job")
+	}
+	if job.Status.Failed > 0 {
+		return c.failVerify(ctx, "verify job is failed")
+	}
+	svc := buildSvc(bv)
+	status, err := cmd.GetCmdStatus(fmt.Sprintf("%s.%s", svc.Name, svc.Namespace), defaultCMDRestPort)
+	if err != nil {
+		return errors.Wrap(err, "error get verify status")
+	}
+	if !status.Completed {
+		return recon.ErrReSync("wait verify complete", pollInterval)
+	}
+	if status.ExitCode == 0 {
+		return c.successVerify(ctx)
+	}
+	return c.failVerify(ctx, status.Stderr)
+}
+
+func (c *VerifyActor) failVerify(ctx *recon.Context[*v1alpha1.BackupVerification], msg string) error {
+	bv := ctx.Obj
+	// the job is kept for troubleshooting
+	ctx.Event.EmitEventGeneric("VerificationFailed", fmt.Sprintf("backup %s failed verification: %s", bv.Spec.BackupName, msg), nil)
+	if err := c.setVerified(ctx, metav1.ConditionFalse, "VerificationFailed", msg); err != nil {
+		return err
+	}
+	bv.Status.Phase = v1alpha1.JobPhaseFailed
+	meta.SetStatusCondition(&bv.Status.Conditions, metav1.Condition{
+		Type:    v1alpha1.JobConditionTypeEnded,
+		Status:  metav1.ConditionTrue,
+		Reason:  "JobFailed",
+		Message: msg,
+	})
+	return ctx.UpdateStatus(bv)
+}
+
+func (c *VerifyActor) successVerify(ctx *recon.Context[*v1alpha1.BackupVerification]) error {
+	bv := ctx.Obj
+	if err := c.setVerified(ctx, metav1.ConditionTrue, "VerificationSucceeded", ""); err != nil {
+		return err
+	}
+	if err := util.Ignore(apierrors.IsNotFound, ctx.Delete(
+		&batchv1.Job{ObjectMeta: common.ObjMetaTemplate(bv, bv.Name)},
+		client.PropagationPolicy(metav1.DeletePropagationBackground),
+	)); err != nil {
+		return errors.Wrap(err, "error finalize verify job")
+	}
+	bv.Status.Phase = v1alpha1.JobPhaseCompleted
+	meta.SetStatusCondition(&bv.Status.Conditions, metav1.Condition{
+		Type:   v1alpha1.JobConditionTypeEnded,
+		Status: metav1.ConditionTrue,
+		Reason: "JobComplete",
+	})
+	return ctx.UpdateStatus(bv)
+}
+
+// setVerified records the verification result as the Verified condition of the backup
+func (c *VerifyActor) setVerified(ctx *recon.Context[*v1alpha1.BackupVerification], status metav1.ConditionStatus, reason string, msg string) error {
+	backup := &v1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Name: ctx.Obj.Spec.BackupName}}
+	err := ctx.PatchStatus(backup, func() error {
+		backup.SetCondition(metav1.Condition{
+			Type:    v1alpha1.BackupConditionTypeVerified,
+			Status:  status,
+			Reason:  reason,
+			Message: msg,
+		})
+		return nil
+	})
+	return errors.Wrap(util.Ignore(apierrors.IsNotFound, err), "error record verification result to backup")
+}
+
+func (c *VerifyActor) Finalize(ctx *recon.Context[*v1alpha1.BackupVerification]) (bool, error) {
+	bv := ctx.Obj
+	err := ctx.Delete(&batchv1.Job{ObjectMeta: common.ObjMetaTemplate(bv, bv.Name)}, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if err == nil {
+		// check next time
+		return false, nil
+	}
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+	return false, errors.Wrap(err, "error delete job")
+}
+
+func (c *VerifyActor) Reconcile(mgr manager.Manager) error {
+	return recon.Setup[*v1alpha1.BackupVerification](&v1alpha1.BackupVerification{}, "backupverification", mgr, c, recon.WithBuildFn(func(b *builder.Builder) {
+		b.Owns(&batchv1.Job{})
+	}))
+}
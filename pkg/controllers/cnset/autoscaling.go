@@ -0,0 +1,286 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnset
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/mosql"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// autoscalingMetricsWindow is the window to aggregate metrics, which covers a few collect intervals of MO
+	autoscalingMetricsWindow = time.Minute
+	// autoscalingTolerance is the ratio of the metric to the target below which the replicas is not changed
+	autoscalingTolerance = 0.1
+)
+
+// cnMetrics is the sum of the workload metrics of all the CN stores
+type cnMetrics struct {
+	connections float64
+	cpuPercent  float64
+	qps         float64
+}
+
+// Autoscale samples the workload metrics of the CNSet and scales the CNSet to the replicas desired by the autoscaler
+func (c *WithResources) Autoscale(ctx *recon.Context[*v1alpha1.CNSet]) error {
+	cn := ctx.Obj
+	a := cn.Spec.Autoscaling
+	if cn.Status.Autoscaling == nil {
+		cn.Status.Autoscaling = &v1alpha1.CNAutoscalingStatus{}
+	}
+	status := cn.Status.Autoscaling
+	now := time.Now()
+	status.LastSampleTime = &metav1.Time{Time: now}
+
+	m, err := c.sampleMetrics(ctx)
+	if err != nil {
+		status.Message = fmt.Sprintf("failed to sample metrics: %v", err)
+		if err := ctx.UpdateStatus(cn); err != nil {
+			return errors.Wrap(err, "update autoscaling status")
+		}
+		return recon.ErrReSync(status.Message, a.GetSyncPeriod())
+	}
+	status.Connections = int64(math.Round(m.connections))
+	status.CPUPercent = int64(math.Round(m.cpuPercent))
+	status.QPS = int64(math.Round(m.qps))
+
+	current := cn.Spec.Replicas
+	recommended := recommendReplicas(a, current, m)
+	desired, msg := stabilizeReplicas(a, status, now, current, recommended)
+	status.DesiredReplicas = desired
+	status.Message = msg
+	if desired != current {
+		status.LastScaleTime = &metav1.Time{Time: now}
+	}
+	// persist the status before scaling so that the cooldown is always respected
+	if err := ctx.UpdateStatus(cn); err != nil {
+		return errors.Wrap(err, "update autoscaling status")
+	}
+	if desired != current {
+		ctx.Log.Info("autoscale cnset", "from", current, "to", desired, "reason", msg)
+		if err := ctx.Patch(cn, func() error {
+			cn.Spec.Replicas = desired
+			return nil
+		}); err != nil {
+			return errors.Wrap(err, "scale cnset")
+		}
+		ctx.Event.EmitEventGeneric("Autoscaled", fmt.Sprintf("scale from %d to %d replicas: %s", current, desired, msg), nil)
+	}
+	return recon.ErrReSync("wait for next autoscaling sample", a.GetSyncPeriod())
+}
+
+// nextAutoscalingSample returns the duration to wait before the next sample, 0 if a sample is due
+func nextAutoscalingSample(cn *v1alpha1.CNSet, now time.Time) time.Duration {
+	status := cn.Status.Autoscaling
+	if status == nil || status.LastSampleTime == nil {
+		return 0
+	}
+	next := status.LastSampleTime.Add(cn.Spec.Autoscaling.GetSyncPeriod())
+	if now.Before(next) {
+		return next.Sub(now)
+	}
+	return 0
+}
+
+func (c *WithResources) sampleMetrics(ctx *recon.Context[*v1alpha1.CNSet]) (cnMetrics, error) {
+	cn := ctx.Obj
+	a := cn.Spec.Autoscaling
+	var m cnMetrics
+	cli, err := c.metricsClient(ctx)
+	if err != nil {
+		return m, err
+	}
+	var nodes []string
+	for _, s := range cn.Status.Stores {
+		nodes = append(nodes, s.UUID)
+	}
+	sum := func(metric string, agg mosql.Aggregation) (float64, error) {
+		values, err := cli.GetNodeMetrics(ctx, metric, agg, nodes, autoscalingMetricsWindow)
+		if err != nil {
+			return 0, errors.Wrapf(err, "query metric %s", metric)
+		}
+		var total float64
+		for _, v := range values {
+			total += v
+		}
+		return total, nil
+	}
+	if a.TargetConnections != nil {
+		if m.connections, err = sum(mosql.MetricServerConnections, mosql.AggregationAvg); err != nil {
+			return m, err
+		}
+	}
+	if a.TargetCPUPercent != nil {
+		if m.cpuPercent, err = sum(mosql.MetricProcessCPUPercent, mosql.AggregationAvg); err != nil {
+			return m, err
+		}
+	}
+	if a.TargetQPS != nil {
+		statements, err := sum(mosql.MetricSQLStatementTotal, mosql.AggregationSum)
+		if err != nil {
+			return m, err
+		}
+		m.qps = statements / autoscalingMetricsWindow.Seconds()
+	}
+	return m, nil
+}
+
+// metricsClient returns the SQL client to sample the metrics of the CNSet, clients are cached by CNSet since
+// each client holds a connection pool, a cached client is closed once the target or the secret changes
+func (c *Actor) metricsClient(ctx *recon.Context[*v1alpha1.CNSet]) (mosql.Client, error) {
+	cn := ctx.Obj
+	ref := cn.Spec.MetricsSecretRef
+	if ref == nil {
+		return nil, errors.New("metricsSecretRef must be set to sample metrics")
+	}
+	secret := types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}
+	if secret.Namespace == "" {
+		secret.Namespace = cn.Namespace
+	}
+	target := fmt.Sprintf("%s:%d", cn.Status.Host, cn.Status.Port)
+	key := fmt.Sprintf("%s/%s", target, secret)
+	if v, ok := c.metricsClients.Load(client.ObjectKeyFromObject(cn)); ok {
+		cached := v.(*cachedMetricsClient)
+		if cached.key == key {
+			return cached.Client, nil
+		}
+	}
+	cli := mosql.NewClient(target, ctx.Client, secret)
+	// reconciliations of the same CNSet are serialized, so there is no concurrent store of the CNSet
+	if v, ok := c.metricsClients.Swap(client.ObjectKeyFromObject(cn), &cachedMetricsClient{key: key, Client: cli}); ok {
+		closeMetricsClient(ctx, v.(*cachedMetricsClient))
+	}
+	return cli, nil
+}
+
+// evictMetricsClient closes and removes the cached metrics client of the CNSet
+func (c *Actor) evictMetricsClient(ctx *recon.Context[*v1alpha1.CNSet]) {
+	if v, ok := c.metricsClients.LoadAndDelete(client.ObjectKeyFromObject(ctx.Obj)); ok {
+		closeMetricsClient(ctx, v.(*cachedMetricsClient))
+	}
+}
+
+func closeMetricsClient(ctx *recon.Context[*v1alpha1.CNSet], cached *cachedMetricsClient) {
+	if err := cached.Close(); err != nil {
+		ctx.Log.Error(err, "failed to close metrics client", "key", cached.key)
+	}
+}
+
+// cachedMetricsClient is a metrics client with the target and the secret it is built for
+type cachedMetricsClient struct {
+	mosql.Client
+	key string
+}
+
+// recommendReplicas returns the replicas required by the sampled metrics, which is the maximum of the
+// replicas required by each target
+func recommendReplicas(a *v1alpha1.CNAutoscaling, current int32, m cnMetrics) int32 {
+	var recommended int32
+	targets := []struct {
+		target *int32
+		value  float64
+	}{
+		{a.TargetConnections, m.connections},
+		{a.TargetCPUPercent, m.cpuPercent},
+		{a.TargetQPS, m.qps},
+	}
+	for _, t := range targets {
+		if t.target == nil {
+			continue
+		}
+		if r := replicasForTarget(current, t.value, *t.target); r > recommended {
+			recommended = r
+		}
+	}
+	if recommended < a.MinReplicas {
+		recommended = a.MinReplicas
+	}
+	if recommended > a.MaxReplicas {
+		recommended = a.MaxReplicas
+	}
+	return recommended
+}
+
+func replicasForTarget(current int32, total float64, target int32) int32 {
+	if current > 0 {
+		ratio := total / (float64(current) * float64(target))
+		if math.Abs(ratio-1) <= autoscalingTolerance {
+			return current
+		}
+	}
+	return int32(math.Ceil(total / float64(target)))
+}
+
+// stabilizeReplicas records the recommendation and returns the desired replicas with a description of the
+// decision. Like HPA, scale-up takes the lowest recommendation in the scale-up stabilization window and
+// scale-down takes the highest recommendation in the scale-down stabilization window, the desired replicas
+// is then held if the CNSet was scaled within the cooldown.
+func stabilizeReplicas(a *v1alpha1.CNAutoscaling, status *v1alpha1.CNAutoscalingStatus, now time.Time, current, recommended int32) (int32, string) {
+	upWindow := a.GetScaleUpStabilizationWindow()
+	downWindow := a.GetScaleDownStabilizationWindow()
+	keep := upWindow
+	if downWindow > keep {
+		keep = downWindow
+	}
+	var recs []v1alpha1.CNAutoscalingRecommendation
+	for _, r := range status.Recommendations {
+		if now.Sub(r.Time.Time) <= keep {
+			recs = append(recs, r)
+		}
+	}
+	recs = append(recs, v1alpha1.CNAutoscalingRecommendation{Time: metav1.Time{Time: now}, Replicas: recommended})
+	status.Recommendations = recs
+
+	upRec, downRec := recommended, recommended
+	for _, r := range recs {
+		age := now.Sub(r.Time.Time)
+		if age <= upWindow && r.Replicas < upRec {
+			upRec = r.Replicas
+		}
+		if age <= downWindow && r.Replicas > downRec {
+			downRec = r.Replicas
+		}
+	}
+
+	var sinceLastScale time.Duration = math.MaxInt64
+	if status.LastScaleTime != nil {
+		sinceLastScale = now.Sub(status.LastScaleTime.Time)
+	}
+	switch {
+	case upRec > current:
+		if cooldown := a.GetScaleUpCooldown(); sinceLastScale < cooldown {
+			return current, fmt.Sprintf("scale-up to %d replicas is held by the cooldown of %s", upRec, cooldown)
+		}
+		return upRec, fmt.Sprintf("metrics require %d replicas", upRec)
+	case downRec < current:
+		if cooldown := a.GetScaleDownCooldown(); sinceLastScale < cooldown {
+			return current, fmt.Sprintf("scale-down to %d replicas is held by the cooldown of %s", downRec, cooldown)
+		}
+		return downRec, fmt.Sprintf("metrics require %d replicas", downRec)
+	}
+	if recommended != current {
+		return current, fmt.Sprintf("recommendation of %d replicas is stabilized", recommended)
+	}
+	return current, "metrics are within the targets"
+}
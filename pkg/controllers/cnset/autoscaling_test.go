@@ -0,0 +1,219 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnset
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/mosql"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_recommendReplicas(t *testing.T) {
+	a := &v1alpha1.CNAutoscaling{
+		MinReplicas:       2,
+		MaxReplicas:       10,
+		TargetConnections: pointer.Int32(100),
+		TargetCPUPercent:  pointer.Int32(200),
+	}
+	tests := []struct {
+		name    string
+		current int32
+		metrics cnMetrics
+		want    int32
+	}{{
+		name:    "idle cluster keeps min replicas",
+		current: 3,
+		want:    2,
+	}, {
+		name:    "within tolerance",
+		current: 3,
+		metrics: cnMetrics{connections: 320},
+		want:    3,
+	}, {
+		name:    "connections require more replicas",
+		current: 3,
+		metrics: cnMetrics{connections: 450, cpuPercent: 100},
+		want:    5,
+	}, {
+		name:    "the highest requirement wins",
+		current: 3,
+		metrics: cnMetrics{connections: 450, cpuPercent: 1300},
+		want:    7,
+	}, {
+		name:    "capped by max replicas",
+		current: 3,
+		metrics: cnMetrics{connections: 5000},
+		want:    10,
+	}, {
+		name:    "qps is ignored without target",
+		current: 3,
+		metrics: cnMetrics{connections: 300, qps: 100000},
+		want:    3,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(recommendReplicas(a, tt.current, tt.metrics)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_stabilizeReplicas(t *testing.T) {
+	now := time.Now()
+	ago := func(d time.Duration) metav1.Time {
+		return metav1.Time{Time: now.Add(-d)}
+	}
+	a := &v1alpha1.CNAutoscaling{
+		MinReplicas:                  1,
+		MaxReplicas:                  10,
+		ScaleUpStabilizationWindow:   &metav1.Duration{Duration: time.Minute},
+		ScaleDownStabilizationWindow: &metav1.Duration{Duration: 5 * time.Minute},
+		ScaleUpCooldown:              &metav1.Duration{Duration: time.Minute},
+		ScaleDownCooldown:            &metav1.Duration{Duration: 5 * time.Minute},
+	}
+	tests := []struct {
+		name        string
+		status      v1alpha1.CNAutoscalingStatus
+		current     int32
+		recommended int32
+		want        int32
+		wantRecs    int
+	}{{
+		name:        "scale up",
+		current:     3,
+		recommended: 5,
+		want:        5,
+		wantRecs:    1,
+	}, {
+		name: "scale up takes the lowest recommendation in window",
+		status: v1alpha1.CNAutoscalingStatus{Recommendations: []v1alpha1.CNAutoscalingRecommendation{
+			{Time: ago(30 * time.Second), Replicas: 4},
+			{Time: ago(2 * time.Minute), Replicas: 3},
+		}},
+		current:     3,
+		recommended: 6,
+		want:        4,
+		wantRecs:    3,
+	}, {
+		name: "scale down takes the highest recommendation in window",
+		status: v1alpha1.CNAutoscalingStatus{Recommendations: []v1alpha1.CNAutoscalingRecommendation{
+			{Time: ago(4 * time.Minute), Replicas: 4},
+			{Time: ago(10 * time.Minute), Replicas: 8},
+		}},
+		current:     6,
+		recommended: 2,
+		want:        4,
+		wantRecs:    2,
+	}, {
+		name: "scale down is stabilized",
+		status: v1alpha1.CNAutoscalingStatus{Recommendations: []v1alpha1.CNAutoscalingRecommendation{
+			{Time: ago(time.Minute), Replicas: 6},
+		}},
+		current:     6,
+		recommended: 2,
+		want:        6,
+		wantRecs:    2,
+	}, {
+		name:        "scale up is held by cooldown",
+		status:      v1alpha1.CNAutoscalingStatus{LastScaleTime: &metav1.Time{Time: now.Add(-30 * time.Second)}},
+		current:     3,
+		recommended: 5,
+		want:        3,
+		wantRecs:    1,
+	}, {
+		name:        "scale down is held by cooldown",
+		status:      v1alpha1.CNAutoscalingStatus{LastScaleTime: &metav1.Time{Time: now.Add(-3 * time.Minute)}},
+		current:     5,
+		recommended: 3,
+		want:        5,
+		wantRecs:    1,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			status := tt.status.DeepCopy()
+			got, msg := stabilizeReplicas(a, status, now, tt.current, tt.recommended)
+			g.Expect(got).To(Equal(tt.want), msg)
+			g.Expect(status.Recommendations).To(HaveLen(tt.wantRecs))
+		})
+	}
+}
+
+func Test_nextAutoscalingSample(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Now()
+	cn := &v1alpha1.CNSet{Spec: v1alpha1.CNSetSpec{Autoscaling: &v1alpha1.CNAutoscaling{}}}
+	g.Expect(nextAutoscalingSample(cn, now)).To(BeZero())
+	cn.Status.Autoscaling = &v1alpha1.CNAutoscalingStatus{LastSampleTime: &metav1.Time{Time: now.Add(-10 * time.Second)}}
+	g.Expect(nextAutoscalingSample(cn, now)).To(Equal(20 * time.Second))
+	cn.Status.Autoscaling.LastSampleTime = &metav1.Time{Time: now.Add(-time.Minute)}
+	g.Expect(nextAutoscalingSample(cn, now)).To(BeZero())
+}
+
+type closeRecorder struct {
+	mosql.Client
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func Test_metricsClient(t *testing.T) {
+	g := NewGomegaWithT(t)
+	newClient := mosql.NewClient
+	defer func() { mosql.NewClient = newClient }()
+	mosql.NewClient = func(target string, kubeCli client.Client, secret types.NamespacedName) mosql.Client {
+		return &closeRecorder{Client: mosql.NewFakeClient(target, kubeCli, secret)}
+	}
+
+	cn := &v1alpha1.CNSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cn"},
+		Spec:       v1alpha1.CNSetSpec{MetricsSecretRef: &v1alpha1.ObjectRef{Name: "metrics"}},
+		Status:     v1alpha1.CNSetStatus{Host: "cn.default", Port: 6001},
+	}
+	ctx := &recon.Context[*v1alpha1.CNSet]{Context: context.Background(), Obj: cn, Log: logr.Discard()}
+	a := &Actor{}
+
+	first, err := a.metricsClient(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	cached, err := a.metricsClient(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cached).To(BeIdenticalTo(first))
+
+	// the secret changes
+	cn.Spec.MetricsSecretRef.Name = "rotated"
+	second, err := a.metricsClient(ctx)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(second).ToNot(BeIdenticalTo(first))
+	g.Expect(first.(*closeRecorder).closed).To(BeTrue())
+	g.Expect(second.(*closeRecorder).closed).To(BeFalse())
+
+	a.evictMetricsClient(ctx)
+	g.Expect(second.(*closeRecorder).closed).To(BeTrue())
+	_, ok := a.metricsClients.Load(client.ObjectKeyFromObject(cn))
+	g.Expect(ok).To(BeFalse())
+}
@@ -16,6 +16,8 @@ package cnset
 
 import (
 	"fmt"
+	"sync"
+
 	"github.com/matrixorigin/matrixone-operator/api/features"
 	"github.com/openkruise/kruise-api/apps/pub"
 	kruisev1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
@@ -45,7 +47,16 @@ const (
 	reSyncAfter = 10 * time.Second
 )
 
-type Actor struct{}
+type Actor struct {
+	// metricsClients caches the SQL clients to sample metrics for autoscaling by CNSet
+	metricsClients sync.Map
+
+	// QueryCli lists the sessions of CN stores to detect idleness, the idle policy is skipped if nil
+	QueryCli ProcessLister
+
+	// HAKeeper resolves the query service addresses of CN stores, the configured port is used if nil
+	HAKeeper QueryAddressGetter
+}
 
 var _ recon.Actor[*v1alpha1.CNSet] = &Actor{}
 
@@ -105,28 +116,32 @@ func (c *Actor) Observe(ctx *recon.Context[*v1alpha1.CNSet]) (recon.Action[*v1al
 	if err != nil {
 		return nil, errors.Wrap(err, "list cn pods")
 	}
-	for _, pod := range podList.Items {
-		uid := v1alpha1.GetCNPodUUID(&pod)
-		cnState := pod.Annotations[common.CNStateAnno]
-		if cnState == "" {
-			cnState = v1alpha1.CNStoreStateUnknown
-		}
-		stores = append(stores, v1alpha1.CNStore{
-			UUID:    uid,
-			PodName: pod.Name,
-			State:   cnState,
-		})
+	for i := range podList.Items {
+		stores = append(stores, cnStoreStatus(&podList.Items[i]))
+	}
+	replicas := desiredReplicas(cn)
+	if replicas > 0 && replicas < *cs.Spec.Replicas {
+		c.observeStoreSessions(ctx, podList.Items, stores)
 	}
 	cn.Status.Stores = stores
 	cn.Status.Replicas = cs.Status.Replicas
 	cn.Status.LabelSelector = cs.Status.LabelSelector
+	if cn.Spec.IdlePolicy == nil && !cn.Status.IsHibernated() {
+		cn.Status.Hibernation = nil
+	}
+	if shouldWakeUp(cn) {
+		return c.with(cs).WakeUp, nil
+	}
 	// sync status from cloneset
-	if cs.Status.ReadyReplicas >= cn.Spec.Replicas {
+	switch {
+	case cn.Status.IsHibernated():
+		setHibernated(cn)
+	case cs.Status.ReadyReplicas >= replicas:
 		setReady(cn)
-	} else {
+	default:
 		setNotReady(cn)
 	}
-	if cs.Status.UpdatedReplicas >= cn.Spec.Replicas {
+	if cs.Status.UpdatedReplicas >= replicas {
 		setSynced(cn)
 	} else {
 		setNotSynced(cn)
@@ -140,22 +155,54 @@ func (c *Actor) Observe(ctx *recon.Context[*v1alpha1.CNSet]) (recon.Action[*v1al
 			}
 		}
 	}
-	if cn.Spec.Replicas != *cs.Spec.Replicas {
+	if replicas != *cs.Spec.Replicas {
 		return c.with(cs).Scale, nil
 	}
+	if cn.Status.IsHibernated() {
+		// wait for the wake-up request
+		return nil, nil
+	}
 
+	if cn.Spec.Autoscaling == nil {
+		cn.Status.Autoscaling = nil
+		c.evictMetricsClient(ctx)
+	}
 	if recon.IsReady(&cn.Status.ConditionalStatus) {
 		cn.Status.Host = fmt.Sprintf("%s.%s", svc.Name, svc.Namespace)
 		cn.Status.Port = CNSQLPort
-		return nil, c.cleanup(ctx)
+		if err := c.cleanup(ctx); err != nil {
+			return nil, err
+		}
+		if cn.Spec.IdlePolicy != nil && c.checkIdle(ctx, podList.Items) {
+			return c.with(cs).Hibernate, nil
+		}
+		// autoscaling is paused until the CNSet is ready with the last desired replicas
+		if cn.Spec.Autoscaling != nil {
+			if wait := nextAutoscalingSample(cn, time.Now()); wait > 0 {
+				return nil, recon.ErrReSync("wait for next autoscaling sample", wait)
+			}
+			return c.with(cs).Autoscale, nil
+		}
+		if cn.Spec.IdlePolicy != nil {
+			return nil, recon.ErrReSync("check idle cn stores", idleCheckInterval)
+		}
+		return nil, nil
 	}
 
 	return nil, recon.ErrReSync("cnset is not ready", reSyncAfter)
 }
 
 func (c *WithResources) Scale(ctx *recon.Context[*v1alpha1.CNSet]) error {
+	cn := ctx.Obj
+	var toDelete []string
+	if replicas := desiredReplicas(cn); replicas > 0 && replicas < *c.cs.Spec.Replicas {
+		toDelete = scaleInStores(cn, int(*c.cs.Spec.Replicas-replicas))
+	}
 	return ctx.Patch(c.cs, func() error {
-		syncReplicas(ctx.Obj, c.cs)
+		syncReplicas(cn, c.cs)
+		if len(toDelete) > 0 {
+			c.cs.Spec.ScaleStrategy.PodsToDelete = toDelete
+		}
 		return nil
 	})
 }
@@ -181,6 +228,7 @@ func (c *Actor) cleanup(ctx *recon.Context[*v1alpha1.CNSet]) error {
 
 func (c *Actor) Finalize(ctx *recon.Context[*v1alpha1.CNSet]) (bool, error) {
 	cn := ctx.Obj
+	c.evictMetricsClient(ctx)
 
 	objs := []client.Object{&kruisev1alpha1.CloneSet{ObjectMeta: metav1.ObjectMeta{
 		Name: setName(cn),
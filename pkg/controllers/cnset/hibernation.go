@@ -0,0 +1,191 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnset
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	querypb "github.com/matrixorigin/matrixone/pkg/pb/query"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// idleCheckInterval is the interval to check the sessions of the CNSet with an idle policy
+const idleCheckInterval = 30 * time.Second
+
+// ProcessLister lists the sessions of a CN store through its query service
+type ProcessLister interface {
+	ShowProcessList(ctx context.Context, address string) (*querypb.ShowProcessListResponse, error)
+}
+
+// QueryAddressGetter gets the query service addresses that the CN stores registered in HAKeeper
+type QueryAddressGetter interface {
+	CNQueryAddresses(ls *v1alpha1.LogSet) (map[string]string, error)
+}
+
+// Hibernate scales the CNSet to zero, the replicas in spec is kept to be restored on wake-up
+func (c *WithResources) Hibernate(ctx *recon.Context[*v1alpha1.CNSet]) error {
+	cn := ctx.Obj
+	now := metav1.Now()
+	cn.Status.Hibernation.Hibernated = true
+	cn.Status.Hibernation.HibernatedTime = &now
+	setHibernated(cn)
+	// persist the hibernation first, otherwise the replicas would be restored by the next reconciliation
+	if err := ctx.UpdateStatus(cn); err != nil {
+		return errors.Wrap(err, "record hibernation")
+	}
+	if err := ctx.Patch(c.cs, func() error {
+		syncReplicas(cn, c.cs)
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "scale cnset to zero")
+	}
+	ctx.Event.EmitEventGeneric("Hibernated", fmt.Sprintf("no session for %s, scale to zero", cn.Spec.IdlePolicy.IdleTimeout.Duration), nil)
+	return nil
+}
+
+// WakeUp restores the replicas of a hibernated CNSet and consumes the wake-up request
+func (c *WithResources) WakeUp(ctx *recon.Context[*v1alpha1.CNSet]) error {
+	cn := ctx.Obj
+	if cn.Status.IsHibernated() {
+		cn.Status.Hibernation = nil
+		if cn.Spec.IdlePolicy != nil {
+			// restart the idle timer
+			cn.Status.Hibernation = &v1alpha1.CNHibernationStatus{}
+		}
+		if err := ctx.UpdateStatus(cn); err != nil {
+			return errors.Wrap(err, "clear hibernation")
+		}
+		if err := ctx.Patch(c.cs, func() error {
+			syncReplicas(cn, c.cs)
+			return nil
+		}); err != nil {
+			return errors.Wrap(err, "restore cnset replicas")
+		}
+		ctx.Event.EmitEventGeneric("WakeUp", fmt.Sprintf("restore %d replicas", cn.Spec.Replicas), nil)
+	}
+	if _, ok := cn.Annotations[v1alpha1.CNSetWakeUpAnnoKey]; ok {
+		if err := ctx.Patch(cn, func() error {
+			delete(cn.Annotations, v1alpha1.CNSetWakeUpAnnoKey)
+			return nil
+		}); err != nil {
+			return errors.Wrap(err, "remove wake-up request")
+		}
+	}
+	return nil
+}
+
+// shouldWakeUp returns whether the CNSet is requested to wake up or is no longer subject to hibernation
+func shouldWakeUp(cn *v1alpha1.CNSet) bool {
+	if _, ok := cn.Annotations[v1alpha1.CNSetWakeUpAnnoKey]; ok {
+		return true
+	}
+	return cn.Status.IsHibernated() && cn.Spec.IdlePolicy == nil
+}
+
+// desiredReplicas returns the replicas the CloneSet should run, which is zero when the CNSet is hibernated
+func desiredReplicas(cn *v1alpha1.CNSet) int32 {
+	if cn.Status.IsHibernated() {
+		return 0
+	}
+	return cn.Spec.Replicas
+}
+
+// checkIdle observes the sessions of the CN stores and returns whether the CNSet should be hibernated
+func (c *Actor) checkIdle(ctx *recon.Context[*v1alpha1.CNSet], pods []corev1.Pod) bool {
+	cn := ctx.Obj
+	if c.QueryCli == nil {
+		return false
+	}
+	if cn.Status.Hibernation == nil {
+		cn.Status.Hibernation = &v1alpha1.CNHibernationStatus{}
+	}
+	busy := false
+	sessions, err := c.countSessions(ctx, pods)
+	if err != nil {
+		// consider the CNSet busy since we cannot tell
+		ctx.Log.Info("failed to count sessions of cnset", "error", err.Error())
+		busy = true
+	} else if sessions > 0 {
+		busy = true
+	}
+	return observeIdle(cn.Status.Hibernation, busy, cn.Spec.IdlePolicy.IdleTimeout.Duration, time.Now())
+}
+
+func (c *Actor) countSessions(ctx *recon.Context[*v1alpha1.CNSet], pods []corev1.Pod) (int, error) {
+	sessions := 0
+	addrs := c.queryAddresses(ctx)
+	for i := range pods {
+		n, err := c.podSessions(ctx, &pods[i], addrs)
+		if err != nil {
+			return 0, err
+		}
+		sessions += n
+	}
+	return sessions, nil
+}
+
+func (c *Actor) podSessions(ctx *recon.Context[*v1alpha1.CNSet], pod *corev1.Pod, addrs map[string]string) (int, error) {
+	if pod.Status.PodIP == "" {
+		return 0, errors.Errorf("pod %s has no IP", pod.Name)
+	}
+	resp, err := c.QueryCli.ShowProcessList(ctx, QueryAddress(pod, addrs))
+	if err != nil {
+		return 0, errors.Wrapf(err, "show process list of pod %s", pod.Name)
+	}
+	return len(resp.GetSessions()), nil
+}
+
+// queryAddresses returns the query service addresses that the CN stores of the CNSet registered in HAKeeper,
+// nil if they cannot be resolved, in which case QueryAddress falls back to the configured port
+func (c *Actor) queryAddresses(ctx *recon.Context[*v1alpha1.CNSet]) map[string]string {
+	ls := ctx.Obj.Deps.LogSet
+	if c.HAKeeper == nil || ls == nil || ls.Status.Discovery == nil {
+		return nil
+	}
+	addrs, err := c.HAKeeper.CNQueryAddresses(ls)
+	if err != nil {
+		ctx.Log.Info("failed to get query service addresses from HAKeeper, fallback to the configured port", "error", err.Error())
+		return nil
+	}
+	return addrs
+}
+
+// observeIdle records since when the CNSet is idle and returns whether it has been idle for the timeout
+func observeIdle(status *v1alpha1.CNHibernationStatus, busy bool, timeout time.Duration, now time.Time) bool {
+	if busy {
+		status.IdleSince = nil
+		return false
+	}
+	if status.IdleSince == nil {
+		status.IdleSince = &metav1.Time{Time: now}
+	}
+	return now.Sub(status.IdleSince.Time) >= timeout
+}
+
+func setHibernated(cn *v1alpha1.CNSet) {
+	cn.Status.SetCondition(metav1.Condition{
+		Type:    recon.ConditionTypeReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  common.ReasonHibernated,
+		Message: "cnset is scaled to zero by the idle policy",
+	})
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_observeIdle(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Now()
+	timeout := 10 * time.Minute
+	status := &v1alpha1.CNHibernationStatus{}
+
+	g.Expect(observeIdle(status, false, timeout, now)).To(BeFalse())
+	g.Expect(status.IdleSince.Time).To(Equal(now))
+
+	g.Expect(observeIdle(status, false, timeout, now.Add(5*time.Minute))).To(BeFalse())
+	g.Expect(status.IdleSince.Time).To(Equal(now), "idle timer should not be reset when idle")
+
+	g.Expect(observeIdle(status, false, timeout, now.Add(timeout))).To(BeTrue())
+
+	g.Expect(observeIdle(status, true, timeout, now.Add(11*time.Minute))).To(BeFalse())
+	g.Expect(status.IdleSince).To(BeNil(), "idle timer should be reset by sessions")
+}
+
+func Test_hibernationReplicas(t *testing.T) {
+	tests := []struct {
+		name         string
+		anno         map[string]string
+		policy       *v1alpha1.CNIdlePolicy
+		hibernation  *v1alpha1.CNHibernationStatus
+		wantReplicas int32
+		wantWakeUp   bool
+	}{{
+		name:         "not hibernated",
+		policy:       &v1alpha1.CNIdlePolicy{IdleTimeout: metav1.Duration{Duration: time.Minute}},
+		hibernation:  &v1alpha1.CNHibernationStatus{},
+		wantReplicas: 3,
+	}, {
+		name:         "hibernated",
+		policy:       &v1alpha1.CNIdlePolicy{IdleTimeout: metav1.Duration{Duration: time.Minute}},
+		hibernation:  &v1alpha1.CNHibernationStatus{Hibernated: true},
+		wantReplicas: 0,
+	}, {
+		name:         "hibernated and requested to wake up",
+		anno:         map[string]string{v1alpha1.CNSetWakeUpAnnoKey: ""},
+		policy:       &v1alpha1.CNIdlePolicy{IdleTimeout: metav1.Duration{Duration: time.Minute}},
+		hibernation:  &v1alpha1.CNHibernationStatus{Hibernated: true},
+		wantReplicas: 0,
+		wantWakeUp:   true,
+	}, {
+		name:         "hibernated and idle policy removed",
+		hibernation:  &v1alpha1.CNHibernationStatus{Hibernated: true},
+		wantReplicas: 0,
+		wantWakeUp:   true,
+	}, {
+		name:         "stale wake-up request",
+		anno:         map[string]string{v1alpha1.CNSetWakeUpAnnoKey: ""},
+		wantReplicas: 3,
+		wantWakeUp:   true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			cn := &v1alpha1.CNSet{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.anno},
+				Spec: v1alpha1.CNSetSpec{
+					PodSet:     v1alpha1.PodSet{Replicas: 3},
+					IdlePolicy: tt.policy,
+				},
+				Status: v1alpha1.CNSetStatus{Hibernation: tt.hibernation},
+			}
+			g.Expect(desiredReplicas(cn)).To(Equal(tt.wantReplicas))
+			g.Expect(shouldWakeUp(cn)).To(Equal(tt.wantWakeUp))
+		})
+	}
+}
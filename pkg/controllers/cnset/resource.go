@@ -26,6 +26,7 @@ import (
 	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
 	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
 	"github.com/matrixorigin/matrixone-operator/pkg/controllers/logset"
+	"github.com/matrixorigin/matrixone-operator/pkg/moinit"
 	"github.com/openkruise/kruise-api/apps/pub"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -60,17 +61,62 @@ cat <<EOF > ${lsc}
 service-address = "${POD_IP}:{{ .LockServicePort }}"
 EOF
 sed -i "/\[cn.lockservice\]/r ${lsc}" ${conf}
-
+{{ if .WaitDNS }}
+# there is a chance that the dns is not yet added to kubedns and the
+# server will crash, wait before myself to be resolvable
+elapseTime=0
+period={{ .DNSWaitPeriod }}
+threshold={{ .DNSWaitTimeout }}
+while true; do
+    sleep ${period}
+    elapseTime=$(( elapseTime+period ))
+    if [ ${elapseTime} -ge ${threshold} ]; then
+        echo "waiting for dns resolvable timeout" >&2 && exit 1
+    fi
+    if nslookup ${ADDR} >/dev/null; then
+        break
+    else
+        echo "waiting pod dns name ${ADDR} resolvable" >&2
+    fi
+done
+{{ end }}
 echo "/mo-service -cfg ${conf} $@"
 exec /mo-service -cfg ${conf} $@
 `))
 
+// moInitOptions builds the per-pod config by mo-init the same as startScriptTpl
+func moInitOptions(cn *v1alpha1.CNSet) *moinit.Options {
+	policy := cn.Spec.StartupPolicy
+	return &moinit.Options{
+		ConfigFiles: []string{fmt.Sprintf("%s/%s", common.ConfigPath, common.ConfigFile)},
+		UUID:        moinit.UUIDAddress,
+		Sets: []moinit.Set{
+			{Key: "cn.uuid", Value: moinit.VarUUID},
+			{Key: "cn.listen-address", Value: fmt.Sprintf("0.0.0.0:%d", cnRPCPort)},
+			{Key: "cn.service-address", Value: fmt.Sprintf("%s:%d", moinit.VarPodIP, cnRPCPort)},
+			{Key: "cn.sql-address", Value: fmt.Sprintf("%s:%d", moinit.VarPodIP, CNSQLPort)},
+			{Key: "cn.service-host", Value: moinit.VarPodIP},
+			{Key: "cn.lockservice.service-address", Value: fmt.Sprintf("%s:%d", moinit.VarPodIP, common.LockServicePort)},
+		},
+		WaitDNS:    cnWaitDNS(policy),
+		DNSTimeout: policy.GetDNSWaitTimeout(),
+		DNSPeriod:  policy.GetDNSWaitPeriod(),
+	}
+}
+
+// cnWaitDNS returns whether CN stores wait for their DNS names, which is opt-in to keep the behavior of existing CN stores
+func cnWaitDNS(p *v1alpha1.StartupPolicy) bool {
+	return p != nil && p.WaitDNS()
+}
+
 type model struct {
 	ConfigFilePath string
 	CNSQLPort      int
 	CNRpcPort      int
 
 	LockServicePort int
+
+	common.DNSWait
 }
 
 func buildHeadlessSvc(cn *v1alpha1.CNSet) *corev1.Service {
@@ -110,7 +156,8 @@ func syncPersistentVolumeClaim(cn *v1alpha1.CNSet, cs *kruisev1alpha1.CloneSet)
 }
 
 func syncReplicas(cn *v1alpha1.CNSet, cs *kruisev1alpha1.CloneSet) {
-	cs.Spec.Replicas = &cn.Spec.Replicas
+	replicas := desiredReplicas(cn)
+	cs.Spec.Replicas = &replicas
 }
 
 func syncService(cn *v1alpha1.CNSet, svc *corev1.Service) {
@@ -188,6 +235,7 @@ func syncPodSpec(cn *v1alpha1.CNSet, cs *kruisev1alpha1.CloneSet, sp v1alpha1.Sh
 	common.AddReadinessGate(specRef, pub.KruisePodReadyConditionType)
 	common.AddReadinessGate(specRef, pub.InPlaceUpdateReady)
 
+	common.SyncMOInitContainer(mainRef, moInitOptions(cn))
 	// process overlay
 	cn.Spec.Overlay.OverlayMainContainer(mainRef)
 
@@ -195,6 +243,7 @@ func syncPodSpec(cn *v1alpha1.CNSet, cs *kruisev1alpha1.CloneSet, sp v1alpha1.Sh
 	specRef.NodeSelector = cn.Spec.NodeSelector
 	common.SetStorageProviderConfig(sp, specRef)
 	common.SyncTopology(cn.Spec.TopologyEvenSpread, specRef, cs.Spec.Selector)
+	common.SyncMOInitPod(specRef)
 	cn.Spec.Overlay.OverlayPodSpec(specRef)
 }
 
@@ -223,6 +272,7 @@ func buildCNSetConfigMap(cn *v1alpha1.CNSet, ls *v1alpha1.LogSet) (*corev1.Confi
 		CNSQLPort:       CNSQLPort,
 		CNRpcPort:       cnRPCPort,
 		LockServicePort: common.LockServicePort,
+		DNSWait:         common.NewDNSWait(cnWaitDNS(cn.Spec.StartupPolicy), cn.Spec.StartupPolicy),
 	})
 	if err != nil {
 		return nil, err
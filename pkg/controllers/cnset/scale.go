@@ -0,0 +1,89 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnset
+
+import (
+	"sort"
+
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"golang.org/x/exp/slices"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+// observeStoreSessions records the sessions of each store to pick the least-loaded stores on scale-in,
+// the sessions of a store is left unknown if it cannot be observed
+func (c *Actor) observeStoreSessions(ctx *recon.Context[*v1alpha1.CNSet], pods []corev1.Pod, stores []v1alpha1.CNStore) {
+	if c.QueryCli == nil {
+		return
+	}
+	addrs := c.queryAddresses(ctx)
+	for i := range pods {
+		if pods[i].DeletionTimestamp != nil {
+			// already being removed
+			continue
+		}
+		if pods[i].Status.PodIP == "" {
+			// not serving yet, which is the cheapest to remove
+			stores[i].Sessions = pointer.Int(0)
+			continue
+		}
+		sessions, err := c.podSessions(ctx, &pods[i], addrs)
+		if err != nil {
+			ctx.Log.Info("failed to observe sessions of cn store", "pod", pods[i].Name, "error", err.Error())
+			continue
+		}
+		stores[i].Sessions = &sessions
+	}
+}
+
+// scaleInStores picks n stores to remove on scale-in and returns their pod names. The stores listed in
+// spec are picked first, then the stores with the fewest sessions. Fewer than n stores are returned if the
+// sessions of the rest are unknown, in which case the CloneSet picks the remaining ones.
+func scaleInStores(cn *v1alpha1.CNSet, n int) []string {
+	var picked []string
+	for _, id := range cn.Spec.ScaleInStores {
+		if len(picked) >= n {
+			return picked
+		}
+		i := slices.IndexFunc(cn.Status.Stores, func(s v1alpha1.CNStore) bool {
+			return s.PodName == id || (s.UUID != "" && s.UUID == id)
+		})
+		if i < 0 || slices.Contains(picked, cn.Status.Stores[i].PodName) {
+			continue
+		}
+		picked = append(picked, cn.Status.Stores[i].PodName)
+	}
+	var candidates []v1alpha1.CNStore
+	for _, s := range cn.Status.Stores {
+		if s.Sessions != nil && !slices.Contains(picked, s.PodName) {
+			candidates = append(candidates, s)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if *candidates[i].Sessions != *candidates[j].Sessions {
+			return *candidates[i].Sessions < *candidates[j].Sessions
+		}
+		return candidates[i].PodName < candidates[j].PodName
+	})
+	for _, s := range candidates {
+		if len(picked) >= n {
+			break
+		}
+		picked = append(picked, s.PodName)
+	}
+	return picked
+}
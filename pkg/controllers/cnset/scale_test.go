@@ -0,0 +1,72 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnset
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
+)
+
+func Test_scaleInStores(t *testing.T) {
+	stores := []v1alpha1.CNStore{
+		{PodName: "cn-a", UUID: "uuid-a", Sessions: pointer.Int(10)},
+		{PodName: "cn-b", UUID: "uuid-b", Sessions: pointer.Int(0)},
+		{PodName: "cn-c", UUID: "uuid-c"},
+		{PodName: "cn-d", UUID: "uuid-d", Sessions: pointer.Int(3)},
+		{PodName: "cn-e", UUID: "uuid-e", Sessions: pointer.Int(3)},
+	}
+	tests := []struct {
+		name     string
+		explicit []string
+		n        int
+		want     []string
+	}{{
+		name: "least loaded first",
+		n:    2,
+		want: []string{"cn-b", "cn-d"},
+	}, {
+		name:     "explicit stores by pod name and uuid first",
+		explicit: []string{"uuid-a", "cn-c"},
+		n:        3,
+		want:     []string{"cn-a", "cn-c", "cn-b"},
+	}, {
+		name:     "explicit stores beyond scale-in are ignored",
+		explicit: []string{"cn-e", "cn-a"},
+		n:        1,
+		want:     []string{"cn-e"},
+	}, {
+		name:     "unknown and duplicated explicit stores are ignored",
+		explicit: []string{"cn-x", "cn-d", "uuid-d"},
+		n:        2,
+		want:     []string{"cn-d", "cn-b"},
+	}, {
+		name: "stores with unknown sessions are left to the CloneSet",
+		n:    5,
+		want: []string{"cn-b", "cn-d", "cn-e", "cn-a"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			cn := &v1alpha1.CNSet{
+				Spec:   v1alpha1.CNSetSpec{ScaleInStores: tt.explicit},
+				Status: v1alpha1.CNSetStatus{Stores: stores},
+			}
+			g.Expect(scaleInStores(cn, tt.n)).To(Equal(tt.want))
+		})
+	}
+}
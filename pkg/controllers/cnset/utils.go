@@ -15,7 +15,11 @@
 package cnset
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -25,6 +29,12 @@ const (
 	CNSQLPort  = 6001
 	cnRPCPort  = 6002
 	cnPortBase = 6002
+
+	// queryServicePortSlot is the slot of the query service in the ports allocated from the port-base,
+	// which must be kept in sync with cnservice.QueryService of MO
+	queryServicePortSlot = 3
+	// QueryServicePort is the port of the CN query service
+	QueryServicePort = cnPortBase + queryServicePortSlot
 )
 
 func getCNServicePort() corev1.ServicePort {
@@ -54,3 +64,35 @@ func configMapName(cn *v1alpha1.CNSet) string {
 func resourceName(cn *v1alpha1.CNSet) string {
 	return cn.Name + nameSuffix
 }
+
+// cnStoreStatus builds the status of the CN store from the annotations maintained by the cnstore controller
+func cnStoreStatus(pod *corev1.Pod) v1alpha1.CNStore {
+	store := v1alpha1.CNStore{
+		UUID:    v1alpha1.GetCNPodUUID(pod),
+		PodName: pod.Name,
+		State:   pod.Annotations[common.CNStateAnno],
+	}
+	if store.State == "" {
+		store.State = v1alpha1.CNStoreStateUnknown
+	}
+	if store.State == v1alpha1.CNStoreStateDraining {
+		if s, ok := pod.Annotations[common.CNDrainingProgressAnno]; ok {
+			progress := &v1alpha1.CNStoreDrainingProgress{}
+			// the progress is informative only, ignore it if it is malformed
+			if err := json.Unmarshal([]byte(s), progress); err == nil {
+				store.Draining = progress
+			}
+		}
+	}
+	return store
+}
+
+// QueryAddress returns the query service address of the CN store in the pod. The address that the store
+// registered in HAKeeper is preferred, and the address allocated from the port-base in the CNSet config is
+// used if the store has not registered its query service yet.
+func QueryAddress(pod *corev1.Pod, registered map[string]string) string {
+	if addr := registered[v1alpha1.GetCNPodUUID(pod)]; addr != "" {
+		return addr
+	}
+	return fmt.Sprintf("%s:%d", pod.Status.PodIP, QueryServicePort)
+}
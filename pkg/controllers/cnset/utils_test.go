@@ -0,0 +1,81 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnset
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_cnStoreStatus(t *testing.T) {
+	progress := `{"startTime":"2023-09-01T00:00:00Z","sessions":3,"migratingByProxy":true,"message":"migrating"}`
+	tests := []struct {
+		name         string
+		annos        map[string]string
+		wantState    string
+		wantSessions int
+		wantDraining bool
+	}{{
+		name:      "unknown",
+		wantState: v1alpha1.CNStoreStateUnknown,
+	}, {
+		name:      "up",
+		annos:     map[string]string{common.CNStateAnno: v1alpha1.CNStoreStateUp, common.CNDrainingProgressAnno: progress},
+		wantState: v1alpha1.CNStoreStateUp,
+	}, {
+		name:         "draining",
+		annos:        map[string]string{common.CNStateAnno: v1alpha1.CNStoreStateDraining, common.CNDrainingProgressAnno: progress},
+		wantState:    v1alpha1.CNStoreStateDraining,
+		wantSessions: 3,
+		wantDraining: true,
+	}, {
+		name:      "malformed progress",
+		annos:     map[string]string{common.CNStateAnno: v1alpha1.CNStoreStateDraining, common.CNDrainingProgressAnno: "{"},
+		wantState: v1alpha1.CNStoreStateDraining,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-cn-0", Annotations: tt.annos}}
+			store := cnStoreStatus(pod)
+			g.Expect(store.PodName).To(Equal("test-cn-0"))
+			g.Expect(store.State).To(Equal(tt.wantState))
+			if !tt.wantDraining {
+				g.Expect(store.Draining).To(BeNil())
+				return
+			}
+			g.Expect(store.Draining).NotTo(BeNil())
+			g.Expect(store.Draining.Sessions).To(Equal(tt.wantSessions))
+			g.Expect(store.Draining.MigratingByProxy).To(BeTrue())
+		})
+	}
+}
+
+func TestQueryAddress(t *testing.T) {
+	g := NewGomegaWithT(t)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cn-0"},
+		Spec:       corev1.PodSpec{Subdomain: "cn-headless"},
+		Status:     corev1.PodStatus{PodIP: "10.0.0.1"},
+	}
+	g.Expect(QueryAddress(pod, nil)).To(Equal("10.0.0.1:6005"))
+	g.Expect(QueryAddress(pod, map[string]string{"other": "10.0.0.2:7005"})).To(Equal("10.0.0.1:6005"))
+	g.Expect(QueryAddress(pod, map[string]string{v1alpha1.GetCNPodUUID(pod): "10.0.0.1:7005"})).To(Equal("10.0.0.1:7005"))
+}
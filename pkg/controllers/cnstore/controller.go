@@ -37,6 +37,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"time"
@@ -52,7 +53,12 @@ const (
 	messageCNStoreReady  = "CNStoreReady"
 )
 
-const retryInterval = 15 * time.Second
+const (
+	retryInterval = 15 * time.Second
+	// migrateRetryInterval is the interval to check sessions being migrated by proxy, which
+	// transfers the connections of draining CN stores every few seconds
+	migrateRetryInterval = 5 * time.Second
+)
 
 type Controller struct {
 	clientMgr *hacli.HAKeeperClientManager
@@ -131,6 +137,7 @@ func (c *withCNSet) OnPreparingStop(ctx *recon.Context[*corev1.Pod]) error {
 			return errors.Wrap(err, "error patching store draining start time")
 		}
 	}
+	// the draining state also asks the proxy (if any) to migrate the connections to other CN stores
 	ctx.Log.Info("call HAKeeper to drain CN store", "uuid", uid)
 	err := c.withHAKeeperClient(ctx, func(timeout context.Context, hc logservice.ProxyHAKeeperClient) error {
 		return hc.PatchCNStore(timeout, logpb.CNStateLabel{
@@ -142,33 +149,50 @@ func (c *withCNSet) OnPreparingStop(ctx *recon.Context[*corev1.Pod]) error {
 		return errors.Wrap(err, "error set CN state draining")
 	}
 	ctx.Log.Info("call MO to collect Store status", "uuid", uid)
-	var accountSession int
-	// TODO: make query service port coherent after port refactor merged
-	resp, err := c.queryCli.ShowProcessList(ctx, fmt.Sprintf("%s:6005", pod.Status.PodIP))
+	resp, err := c.queryCli.ShowProcessList(ctx, c.queryServiceAddress(ctx))
 	if err != nil {
 		return errors.Wrap(err, "error query process list")
 	}
-	for _, sess := range resp.GetSessions() {
-		if sess.Account != "" && sess.Account != "sys" {
-			ctx.Log.Info("session is not drained, session account", "account", sess.Account)
-			accountSession++
-		}
-	}
-	ctx.Log.Info("CN draining", "account sessions", accountSession)
-	if accountSession == 0 {
+	sessions := len(resp.GetSessions())
+	ctx.Log.Info("CN draining", "sessions", sessions)
+	if sessions == 0 {
 		return c.completeDraining(ctx)
 	}
 	if time.Since(startTime) > sc.GetStoreDrainTimeout() {
-		ctx.Log.Info("store draining timeout, force delete CN", "uuid", uid)
+		ctx.Log.Info("store draining timeout, force delete CN", "uuid", uid, "sessions", sessions)
 		return c.completeDraining(ctx)
 	}
-	return recon.ErrReSync("wait for CN store draining", retryInterval)
+
+	proxy, err := c.frontingProxy(ctx)
+	if err != nil {
+		return err
+	}
+	progress := &v1alpha1.CNStoreDrainingProgress{
+		StartTime:        metav1.NewTime(startTime),
+		Sessions:         sessions,
+		MigratingByProxy: proxyMigratesSessions(proxy),
+	}
+	interval := retryInterval
+	switch {
+	case progress.MigratingByProxy:
+		progress.Message = fmt.Sprintf("proxy %s is migrating %d sessions to other CN stores", proxy.Name, sessions)
+		interval = migrateRetryInterval
+	case proxy != nil:
+		progress.Message = fmt.Sprintf("rebalancer of proxy %s is disabled, wait %d sessions to be closed", proxy.Name, sessions)
+	default:
+		progress.Message = fmt.Sprintf("wait %d sessions to be closed", sessions)
+	}
+	if err := recordDrainingProgress(ctx, progress); err != nil {
+		return errors.Wrap(err, "error recording draining progress")
+	}
+	return recon.ErrReSync(progress.Message, interval)
 }
 
 func (c *withCNSet) completeDraining(ctx *recon.Context[*corev1.Pod]) error {
 	if err := ctx.Patch(ctx.Obj, func() error {
 		controllerutil.RemoveFinalizer(ctx.Obj, common.CNDrainingFinalizer)
 		delete(ctx.Obj.Annotations, storeDrainingStartAnno)
+		delete(ctx.Obj.Annotations, common.CNDrainingProgressAnno)
 		return nil
 	}); err != nil {
 		return errors.Wrap(err, "error removing CN draining finalizer")
@@ -190,12 +214,13 @@ func (c *withCNSet) OnNormal(ctx *recon.Context[*corev1.Pod]) error {
 		}
 	}
 
-	// 2. remove draining start time in case we regret formal deletion decision
+	// 2. remove draining start time and progress in case we regret formal deletion decision
 	if pod.Annotations == nil {
 		pod.Annotations = map[string]string{}
 	}
 	if err := ctx.Patch(pod, func() error {
 		delete(pod.Annotations, storeDrainingStartAnno)
+		delete(pod.Annotations, common.CNDrainingProgressAnno)
 		return nil
 	}); err != nil {
 		return errors.Wrap(err, "removing CN draining start time")
@@ -213,7 +238,9 @@ func (c *withCNSet) OnNormal(ctx *recon.Context[*corev1.Pod]) error {
 	uid := v1alpha1.GetCNPodUUID(pod)
 
 	var err error
-	if c.cn.Spec.ScalingConfig.GetStoreDrainEnabled() {
+	// an uncordoned store must be transited back to working state as well
+	uncordoned := pod.Annotations[common.CNStateAnno] == v1alpha1.CNStoreStateCordoned
+	if c.cn.Spec.ScalingConfig.GetStoreDrainEnabled() || uncordoned {
 		err = c.withHAKeeperClient(ctx, func(timeout context.Context, hc logservice.ProxyHAKeeperClient) error {
 			return hc.PatchCNStore(timeout, logpb.CNStateLabel{
 				UUID:   uid,
@@ -250,12 +277,17 @@ func (c *withCNSet) OnNormal(ctx *recon.Context[*corev1.Pod]) error {
 	}); err != nil {
 		return errors.Wrap(err, "patch pod readiness")
 	}
+	if uncordoned {
+		ctx.Event.EmitEventGeneric(reasonCNStoreUncordoned, fmt.Sprintf("CN store %s is uncordoned", uid), nil)
+	}
 	return nil
 }
 
+// OnCordon marks the CN store as draining in HAKeeper so that no new session is routed to it
 func (c *withCNSet) OnCordon(ctx *recon.Context[*corev1.Pod]) error {
 	pod := ctx.Obj
 	uid := v1alpha1.GetCNPodUUID(pod)
+	wasCordoned := pod.Annotations[common.CNStateAnno] == v1alpha1.CNStoreStateCordoned
 	ctx.Log.Info("call HAKeeper to cordon CN store", "uuid", uid)
 	err := c.withHAKeeperClient(ctx, func(timeout context.Context, hc logservice.ProxyHAKeeperClient) error {
 		return hc.PatchCNStore(timeout, logpb.CNStateLabel{
@@ -278,10 +310,14 @@ func (c *withCNSet) OnCordon(ctx *recon.Context[*corev1.Pod]) error {
 			}
 			cond.Message = messageCNCordon
 		}
+		c.setCNState(pod, v1alpha1.CNStoreStateCordoned)
 		return nil
 	}); err != nil {
 		return errors.Wrap(err, "patch pod readiness")
 	}
+	if !wasCordoned {
+		ctx.Event.EmitEventGeneric(reasonCNStoreCordoned, fmt.Sprintf("CN store %s is cordoned", uid), nil)
+	}
 	return nil
 }
 
@@ -309,15 +345,16 @@ func (c *Controller) observe(ctx *recon.Context[*corev1.Pod]) error {
 		cn:         cn,
 	}
 
-	// store is asked to be cordoned
-	if _, ok := pod.Annotations[storeCordonAnno]; ok {
-		return wc.OnCordon(ctx)
-	}
-
+	// stopping takes precedence over cordon, otherwise a cordoned store would block the scale-in and rolling-update
 	lifecycleState := pod.Labels[pub.LifecycleStateKey]
 	if lifecycleState == string(pub.LifecycleStatePreparingUpdate) || lifecycleState == string(pub.LifecycleStatePreparingDelete) {
 		return wc.OnPreparingStop(ctx)
 	}
+
+	// store is asked to be cordoned
+	if wc.cordoned(pod) {
+		return wc.OnCordon(ctx)
+	}
 	return wc.OnNormal(ctx)
 }
 
@@ -384,7 +421,14 @@ func (c *Controller) Reconcile(mgr manager.Manager) error {
 				predicate.GenerationChangedPredicate{},
 				deletedPredicate{})),
 		recon.WithBuildFn(func(b *builder.Builder) {
+			// reconcile the pods when the cordoned stores of the CNSet change
+			b.Watches(&v1alpha1.CNSet{},
+				handler.EnqueueRequestsFromMapFunc(cnSetPods(mgr.GetClient(), mgr.GetLogger())),
+				builder.WithPredicates(predicate.GenerationChangedPredicate{}))
 			b.WithEventFilter(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				if _, ok := obj.(*v1alpha1.CNSet); ok {
+					return true
+				}
 				pod, ok := obj.(*corev1.Pod)
 				if !ok {
 					return false
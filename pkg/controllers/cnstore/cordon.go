@@ -0,0 +1,63 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnstore
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	"golang.org/x/exp/slices"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	reasonCNStoreCordoned   = "CNStoreCordoned"
+	reasonCNStoreUncordoned = "CNStoreUncordoned"
+)
+
+// cordoned returns whether the store is asked to be cordoned, either by the CNSet spec
+// or by the legacy store-cordon annotation
+func (c *withCNSet) cordoned(pod *corev1.Pod) bool {
+	if _, ok := pod.Annotations[storeCordonAnno]; ok {
+		return true
+	}
+	uid := v1alpha1.GetCNPodUUID(pod)
+	return slices.ContainsFunc(c.cn.Spec.CordonedStores, func(s string) bool {
+		return s == pod.Name || (uid != "" && s == uid)
+	})
+}
+
+// cnSetPods maps a CNSet to its pods so that the change of cordoned stores is reconciled
+func cnSetPods(cli client.Client, log logr.Logger) func(context.Context, client.Object) []reconcile.Request {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		podList := &corev1.PodList{}
+		if err := cli.List(ctx, podList, client.InNamespace(obj.GetNamespace()), client.MatchingLabels{
+			common.ComponentLabelKey: "CNSet",
+			common.InstanceLabelKey:  obj.GetName(),
+		}); err != nil {
+			log.Error(err, "list pods of CNSet", "cnset", client.ObjectKeyFromObject(obj))
+			return nil
+		}
+		var reqs []reconcile.Request
+		for i := range podList.Items {
+			reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&podList.Items[i])})
+		}
+		return reqs
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnstore
+
+import (
+	"context"
+	"encoding/json"
+
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/cnset"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	"github.com/matrixorigin/matrixone-operator/pkg/hacli"
+	"github.com/matrixorigin/matrixone/pkg/logservice"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// queryServiceAddress returns the query service address of the CN store, see cnset.QueryAddress
+func (c *withCNSet) queryServiceAddress(ctx *recon.Context[*corev1.Pod]) string {
+	var addrs map[string]string
+	err := c.withHAKeeperClient(ctx, func(timeout context.Context, hc logservice.ProxyHAKeeperClient) error {
+		var err error
+		addrs, err = hacli.CNQueryAddresses(timeout, hc)
+		return err
+	})
+	if err != nil {
+		ctx.Log.Info("failed to get query service address from HAKeeper, fallback to the configured port", "error", err.Error())
+	}
+	return cnset.QueryAddress(ctx.Obj, addrs)
+}
+
+// frontingProxy returns the ProxySet that routes connections to the CNSet, nil if there is none
+func (c *withCNSet) frontingProxy(ctx *recon.Context[*corev1.Pod]) (*v1alpha1.ProxySet, error) {
+	if c.cn.Deps.LogSet == nil {
+		return nil, nil
+	}
+	proxyList := &v1alpha1.ProxySetList{}
+	if err := ctx.List(proxyList, client.InNamespace(c.cn.Namespace)); err != nil {
+		return nil, errors.Wrap(err, "list proxysets")
+	}
+	for i := range proxyList.Items {
+		p := &proxyList.Items[i]
+		if p.Deps.LogSet != nil && p.Deps.LogSet.Name == c.cn.Deps.LogSet.Name {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+// proxyMigratesSessions returns whether the proxy migrates sessions off draining CN stores. The proxy
+// transfers the idle connections of CN stores in draining state to other CN stores unless its
+// rebalancer is disabled.
+func proxyMigratesSessions(p *v1alpha1.ProxySet) bool {
+	if p == nil {
+		return false
+	}
+	if v := p.Spec.Config.Get("proxy", "rebalance-disabled"); v != nil {
+		if disabled, ok := v.Interface().(bool); ok && disabled {
+			return false
+		}
+	}
+	return true
+}
+
+// recordDrainingProgress reports the draining progress on the pod, which is then collected to the CNSet status
+func recordDrainingProgress(ctx *recon.Context[*corev1.Pod], progress *v1alpha1.CNStoreDrainingProgress) error {
+	pod := ctx.Obj
+	b, err := json.Marshal(progress)
+	if err != nil {
+		return errors.Wrap(err, "marshal draining progress")
+	}
+	if pod.Annotations[common.CNDrainingProgressAnno] == string(b) {
+		return nil
+	}
+	return ctx.Patch(pod, func() error {
+		pod.Annotations[common.CNDrainingProgressAnno] = string(b)
+		return nil
+	})
+}
@@ -22,6 +22,9 @@ import (
 const (
 	CNStateAnno = "matrixorigin.io/cn-state"
 
+	// CNDrainingProgressAnno records the draining progress of a CN store in JSON
+	CNDrainingProgressAnno = "matrixorigin.io/cn-draining-progress"
+
 	CNDrainingFinalizer = "matrixorigin.io/cn-draining"
 
 	CNStoreReadiness corev1.PodConditionType = "matrixorigin.io/cn-store"
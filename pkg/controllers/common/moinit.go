@@ -0,0 +1,79 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"github.com/matrixorigin/matrixone-operator/pkg/moinit"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	moInitContainer = "mo-init"
+	moInitVolume    = "mo-init"
+	moInitPath      = "/opt/mo-init"
+	moInitBinary    = moInitPath + "/mo-init"
+
+	moServiceBinary = "/mo-service"
+)
+
+// MOInitImage is the image that ships the mo-init binary, MO services are started by the start scripts
+// in configmaps if it is empty. It is set by the operator on startup and should be read only.
+var MOInitImage string
+
+// MOInitConfig configures mo-init of the operator
+type MOInitConfig struct {
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+}
+
+// SyncMOInitContainer starts the MO service in the container by mo-init with the given options instead of the start script
+func SyncMOInitContainer(c *corev1.Container, opts *moinit.Options) {
+	if MOInitImage == "" {
+		return
+	}
+	c.Command = append(append([]string{moInitBinary}, opts.Args()...), "--", moServiceBinary)
+	c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{Name: moInitVolume, ReadOnly: true, MountPath: moInitPath})
+}
+
+// SyncMOInitPod installs mo-init to the pod by an init container if mo-init is enabled, or removes it otherwise
+func SyncMOInitPod(podSpec *corev1.PodSpec) {
+	// keep nil slices nil so that the pod spec is not changed when mo-init is disabled
+	var initContainers []corev1.Container
+	for _, c := range podSpec.InitContainers {
+		if c.Name != moInitContainer {
+			initContainers = append(initContainers, c)
+		}
+	}
+	podSpec.InitContainers = initContainers
+	var volumes []corev1.Volume
+	for _, v := range podSpec.Volumes {
+		if v.Name != moInitVolume {
+			volumes = append(volumes, v)
+		}
+	}
+	podSpec.Volumes = volumes
+	if MOInitImage == "" {
+		return
+	}
+	podSpec.InitContainers = append(podSpec.InitContainers, corev1.Container{
+		Name:         moInitContainer,
+		Image:        MOInitImage,
+		Command:      []string{"/mo-init", "install", moInitPath},
+		VolumeMounts: []corev1.VolumeMount{{Name: moInitVolume, MountPath: moInitPath}},
+	})
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name:         moInitVolume,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+}
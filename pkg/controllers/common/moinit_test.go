@@ -0,0 +1,56 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixone-operator/pkg/moinit"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSyncMOInit(t *testing.T) {
+	g := NewGomegaWithT(t)
+	opts := &moinit.Options{ConfigFiles: []string{"/etc/config.toml"}, UUID: moinit.UUIDAddress}
+	script := []string{"/bin/sh", "/etc/start.sh"}
+
+	// disabled
+	c := &corev1.Container{Command: script}
+	spec := &corev1.PodSpec{}
+	SyncMOInitContainer(c, opts)
+	SyncMOInitPod(spec)
+	g.Expect(c.Command).To(Equal(script))
+	g.Expect(spec.InitContainers).To(BeEmpty())
+	g.Expect(spec.Volumes).To(BeEmpty())
+
+	// enabled
+	MOInitImage = "matrixorigin/matrixone-operator:test"
+	defer func() { MOInitImage = "" }()
+	SyncMOInitContainer(c, opts)
+	SyncMOInitPod(spec)
+	SyncMOInitPod(spec)
+	g.Expect(c.Command).To(Equal([]string{moInitBinary, "-config", "/etc/config.toml", "-uuid", "address", "--", moServiceBinary}))
+	g.Expect(c.VolumeMounts).To(ConsistOf(corev1.VolumeMount{Name: moInitVolume, ReadOnly: true, MountPath: moInitPath}))
+	g.Expect(spec.InitContainers).To(HaveLen(1))
+	g.Expect(spec.InitContainers[0].Image).To(Equal(MOInitImage))
+	g.Expect(spec.Volumes).To(HaveLen(1))
+
+	// disabled again
+	MOInitImage = ""
+	SyncMOInitPod(spec)
+	g.Expect(spec.InitContainers).To(BeEmpty())
+	g.Expect(spec.Volumes).To(BeEmpty())
+}
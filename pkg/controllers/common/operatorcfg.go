@@ -27,10 +27,23 @@ type OperatorConfig struct {
 	DefaultArgs  *v1alpha1.DefaultArgs `json:"defaultArgs,omitempty" yaml:"defaultArgs,omitempty"`
 	FeatureGates map[string]bool       `json:"featureGates,omitempty" yaml:"featureGates,omitempty"`
 	BRConfig     BrConfig              `json:"brConfig,omitempty" yaml:"brConfig,omitempty"`
+	MOInit       MOInitConfig          `json:"moInit,omitempty" yaml:"moInit,omitempty"`
+	ProxyPlugin  ProxyPluginConfig     `json:"proxyPlugin,omitempty" yaml:"proxyPlugin,omitempty"`
+}
+
+// ProxyPluginConfig configures the proxy plugin that the operator serves to wake up hibernated CNSets
+type ProxyPluginConfig struct {
+	// ListenAddress is the address the plugin listens on, the plugin is disabled if empty
+	ListenAddress string `json:"listenAddress,omitempty" yaml:"listenAddress,omitempty"`
+	// Backend is the address of the plugin that proxies connect to
+	Backend string `json:"backend,omitempty" yaml:"backend,omitempty"`
 }
 
 type BrConfig struct {
 	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+	// CleanupImage runs the jobs that clean the partial data of cancelled backups, which must provide a shell
+	// and the aws cli
+	CleanupImage string `json:"cleanupImage,omitempty" yaml:"cleanupImage,omitempty"`
 }
 
 // LoadOperatorConfig read all operator configurations from configmap mount path, and load it into OperatorConfig struct
@@ -19,6 +19,7 @@ import (
 	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
 	"github.com/matrixorigin/controller-runtime/pkg/util"
 	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/moinit"
 	"github.com/openkruise/kruise-api/apps/pub"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -37,6 +38,8 @@ type SyncMOPodTask struct {
 	// optional
 	MutateContainer func(c *corev1.Container)
 	MutatePod       func(p *corev1.PodTemplateSpec)
+	// MOInit builds the per-pod config if mo-init is enabled
+	MOInit *moinit.Options
 }
 
 // SyncMOPod execute the given SyncMOPodTask which keeps the pod spec update to date
@@ -58,7 +61,7 @@ func syncPodTemplate(t *SyncMOPodTask) {
 	if mainRef == nil {
 		mainRef = &corev1.Container{Name: v1alpha1.ContainerMain}
 	}
-	syncMainContainer(t.PodSet, mainRef, t.MutateContainer)
+	syncMainContainer(t.PodSet, mainRef, t.MutateContainer, t.MOInit)
 
 	specRef.Containers = []corev1.Container{*mainRef}
 	specRef.ReadinessGates = []corev1.PodReadinessGate{{
@@ -73,11 +76,14 @@ func syncPodTemplate(t *SyncMOPodTask) {
 	if t.StorageProvider != nil {
 		SetStorageProviderConfig(*t.StorageProvider, specRef)
 	}
+	if t.MOInit != nil {
+		SyncMOInitPod(specRef)
+	}
 	p.Overlay.OverlayPodMeta(&t.TargetTemplate.ObjectMeta)
 	p.Overlay.OverlayPodSpec(specRef)
 }
 
-func syncMainContainer(p *v1alpha1.PodSet, c *corev1.Container, mutateFn func(c *corev1.Container)) {
+func syncMainContainer(p *v1alpha1.PodSet, c *corev1.Container, mutateFn func(c *corev1.Container), moInit *moinit.Options) {
 	c.Image = p.Image
 	c.Resources = p.Resources
 	c.Args = p.ServiceArgs
@@ -100,6 +106,9 @@ func syncMainContainer(p *v1alpha1.PodSet, c *corev1.Container, mutateFn func(c
 			MountPath: ConfigPath,
 		},
 	}
+	if moInit != nil {
+		SyncMOInitContainer(c, moInit)
+	}
 	if mutateFn != nil {
 		mutateFn(c)
 	}
@@ -0,0 +1,49 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"math"
+	"time"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+)
+
+// DNSWait is the template model of the DNS wait loop in start scripts
+type DNSWait struct {
+	WaitDNS bool
+	// DNSWaitTimeout is the timeout in seconds
+	DNSWaitTimeout int
+	// DNSWaitPeriod is the period in seconds
+	DNSWaitPeriod int
+}
+
+// NewDNSWait builds the DNS wait model of start scripts from the startup policy, durations are
+// rounded up to whole seconds since the script sleeps in seconds
+func NewDNSWait(wait bool, p *v1alpha1.StartupPolicy) DNSWait {
+	return DNSWait{
+		WaitDNS:        wait,
+		DNSWaitTimeout: ceilSeconds(p.GetDNSWaitTimeout()),
+		DNSWaitPeriod:  ceilSeconds(p.GetDNSWaitPeriod()),
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	s := int(math.Ceil(d.Seconds()))
+	if s < 1 {
+		return 1
+	}
+	return s
+}
@@ -23,6 +23,9 @@ const (
 
 	// ReasonNoEnoughUpdatedStores means the resource fall into current condition due to there is no enough updated stores
 	ReasonNoEnoughUpdatedStores = "NoEnoughUpdatedStores"
+
+	// ReasonHibernated means the resource fall into current condition due to it is scaled to zero by the idle policy
+	ReasonHibernated = "Hibernated"
 )
 
 const (
@@ -26,6 +26,7 @@ import (
 	"github.com/matrixorigin/controller-runtime/pkg/util"
 	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
 	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	"github.com/matrixorigin/matrixone-operator/pkg/moinit"
 	"github.com/openkruise/kruise-api/apps/pub"
 	kruise "github.com/openkruise/kruise-api/apps/v1beta1"
 	corev1 "k8s.io/api/core/v1"
@@ -74,12 +75,12 @@ cat <<EOF > ${ltc}
 service-address = "${ADDR}:{{ .LogtailPort }}"
 EOF
 sed -i "/\[dn.LogtailServer\]/r ${ltc}" ${conf}
-
+{{ if .WaitDNS }}
 # there is a chance that the dns is not yet added to kubedns and the
 # server will crash, wait before myself to be resolvable
 elapseTime=0
-period=1
-threshold=30
+period={{ .DNSWaitPeriod }}
+threshold={{ .DNSWaitTimeout }}
 while true; do
     sleep ${period}
     elapseTime=$(( elapseTime+period ))
@@ -92,17 +93,38 @@ while true; do
         echo "waiting pod dns name ${ADDR} resolvable" >&2
     fi
 done
-
+{{ end }}
 echo "/mo-service -cfg ${conf} $@"
 exec /mo-service -cfg ${conf} $@
 `))
 
+// moInitOptions builds the per-pod config by mo-init the same as startScriptTpl
+func moInitOptions(dn *v1alpha1.DNSet) *moinit.Options {
+	policy := dn.Spec.StartupPolicy
+	return &moinit.Options{
+		ConfigFiles: []string{fmt.Sprintf("%s/%s", common.ConfigPath, common.ConfigFile)},
+		UUID:        moinit.UUIDDNStoreOrdinal,
+		Sets: []moinit.Set{
+			{Key: "dn.uuid", Value: moinit.VarUUID},
+			{Key: "dn.service-address", Value: fmt.Sprintf("%s:%d", moinit.VarAddr, dnServicePort)},
+			{Key: "dn.service-host", Value: moinit.VarAddr},
+			{Key: "dn.lockservice.service-address", Value: fmt.Sprintf("%s:%d", moinit.VarAddr, common.LockServicePort)},
+			{Key: "dn.LogtailServer.service-address", Value: fmt.Sprintf("%s:%d", moinit.VarAddr, common.LogtailPort)},
+		},
+		WaitDNS:    policy.WaitDNS(),
+		DNSTimeout: policy.GetDNSWaitTimeout(),
+		DNSPeriod:  policy.GetDNSWaitPeriod(),
+	}
+}
+
 type model struct {
 	DNServicePort  int
 	ConfigFilePath string
 
 	LockServicePort int
 	LogtailPort     int
+
+	common.DNSWait
 }
 
 func syncReplicas(dn *v1alpha1.DNSet, cs *kruise.StatefulSet) {
@@ -157,6 +179,7 @@ func syncPodSpec(dn *v1alpha1.DNSet, sts *kruise.StatefulSet, sp v1alpha1.Shared
 	if dn.GetDNSBasedIdentity() {
 		mainRef.Env = append(mainRef.Env, corev1.EnvVar{Name: "HOSTNAME_UUID", Value: "y"})
 	}
+	common.SyncMOInitContainer(mainRef, moInitOptions(dn))
 	dn.Spec.Overlay.OverlayMainContainer(mainRef)
 	specRef := &sts.Spec.Template.Spec
 	specRef.Containers = []corev1.Container{*mainRef}
@@ -167,6 +190,7 @@ func syncPodSpec(dn *v1alpha1.DNSet, sts *kruise.StatefulSet, sp v1alpha1.Shared
 
 	common.SetStorageProviderConfig(sp, specRef)
 	common.SyncTopology(dn.Spec.TopologyEvenSpread, specRef, sts.Spec.Selector)
+	common.SyncMOInitPod(specRef)
 
 	dn.Spec.Overlay.OverlayPodSpec(specRef)
 }
@@ -198,6 +222,7 @@ func buildDNSetConfigMap(dn *v1alpha1.DNSet, ls *v1alpha1.LogSet) (*corev1.Confi
 		DNServicePort:   dnServicePort,
 		LockServicePort: common.LockServicePort,
 		LogtailPort:     common.LogtailPort,
+		DNSWait:         common.NewDNSWait(dn.Spec.StartupPolicy.WaitDNS(), dn.Spec.StartupPolicy),
 		ConfigFilePath:  fmt.Sprintf("%s/%s", common.ConfigPath, common.ConfigFile),
 	})
 	if err != nil {
@@ -15,13 +15,19 @@ package logset
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+
 	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/controller-runtime/pkg/util"
 	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
 	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	pb "github.com/matrixorigin/matrixone/pkg/pb/logservice"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/json"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -38,12 +44,15 @@ type bootstrapReplica struct {
 }
 
 // buildBootstrapConfig build the configmap that contains bootstrap information for log service
-func buildBootstrapConfig(ctx *recon.Context[*v1alpha1.LogSet]) (*corev1.ConfigMap, error) {
-	ls := ctx.Obj
-	brs, err := bootstrap(ctx)
+func (r *Actor) buildBootstrapConfig(ctx *recon.Context[*v1alpha1.LogSet]) (*corev1.ConfigMap, error) {
+	brs, err := r.bootstrap(ctx)
 	if err != nil {
 		return nil, err
 	}
+	return bootstrapConfigMap(ctx.Obj, brs)
+}
+
+func bootstrapConfigMap(ls *v1alpha1.LogSet, brs []bootstrapReplica) (*corev1.ConfigMap, error) {
 	m := map[string]interface{}{
 		"bootstrap-cluster":         true,
 		"num-of-log-shards":         ls.Spec.InitialConfig.LogShards,
@@ -74,21 +83,133 @@ func buildBootstrapConfig(ctx *recon.Context[*v1alpha1.LogSet]) (*corev1.ConfigM
 	}, nil
 }
 
-func bootstrap(ctx *recon.Context[*v1alpha1.LogSet]) ([]bootstrapReplica, error) {
-	var replicas []bootstrapReplica
-	previousDecision, hasBootstrapped := ctx.Obj.GetAnnotations()[bootstrapAnnoKey]
+// bootstrap returns the bootstrap decision of the logset. A lost decision is restored from the bootstrap config,
+// and a new decision is refused if the logset has data volumes or a bootstrapped HAKeeper, since bootstrapping
+// such a logset again would silently fork the cluster.
+func (r *Actor) bootstrap(ctx *recon.Context[*v1alpha1.LogSet]) ([]bootstrapReplica, error) {
+	ls := ctx.Obj
+	previousDecision, hasBootstrapped := ls.GetAnnotations()[bootstrapAnnoKey]
 	if hasBootstrapped {
-		if err := json.Unmarshal([]byte(previousDecision), &replicas); err != nil {
-			return nil, errors.Wrap(err, "error deserialize boostrap replicas")
+		return decodeBootstrapReplicas(previousDecision)
+	}
+
+	seeds, found, err := bootstrapConfigSeeds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		replicas, err := decodeSeeds(seeds)
+		if err != nil {
+			return nil, errors.Wrapf(err, "restore bootstrap decision from configmap %s", bootstrapConfigMapName(ls))
 		}
-		return replicas, nil
+		ctx.Event.EmitEventGeneric("BootstrapDecisionRestored", fmt.Sprintf("bootstrap decision %v restored from configmap %s", seeds, bootstrapConfigMapName(ls)), nil)
+		return replicas, recordBootstrapDecision(ctx, replicas)
+	}
+	if err := r.ensureNotBootstrapped(ctx); err != nil {
+		return nil, err
 	}
 
-	// if the bootstrap decision has not yet been made,pick a bootstrap decision
-	n := *ctx.Obj.Spec.InitialConfig.LogShardReplicas
-	// pick first N pods as initial HAKeeperReplicas
+	// if the bootstrap decision has not yet been made, pick the first N pods as initial HAKeeperReplicas
+	replicas, err := newBootstrapReplicas(nil, *ls.Spec.InitialConfig.LogShardReplicas)
+	if err != nil {
+		return nil, err
+	}
+	return replicas, recordBootstrapDecision(ctx, replicas)
+}
+
+// ensureNotBootstrapped returns an error if the logset has ever been bootstrapped
+func (r *Actor) ensureNotBootstrapped(ctx *recon.Context[*v1alpha1.LogSet]) error {
+	ls := ctx.Obj
+	volumes, err := dataVolumes(ctx)
+	if err != nil {
+		return err
+	}
+	if len(volumes) > 0 {
+		return errors.Errorf("refuse to bootstrap logset with existing data volumes %v, restore annotation %s or set annotation %s to re-bootstrap",
+			volumes, bootstrapAnnoKey, v1alpha1.LogSetReBootstrapAnnoKey)
+	}
+	if r.HAKeeper != nil && ls.Status.Discovery != nil {
+		state, err := r.HAKeeper.GetClusterState(ls)
+		if err != nil {
+			// HAKeeper is not reachable before the log stores are started
+			ctx.Log.Info("cannot get cluster state from HAKeeper before bootstrap", "error", err.Error())
+		} else if state.State != pb.HAKeeperCreated {
+			return errors.Errorf("refuse to bootstrap logset since HAKeeper is %s, restore annotation %s or set annotation %s to re-bootstrap",
+				state.State, bootstrapAnnoKey, v1alpha1.LogSetReBootstrapAnnoKey)
+		}
+	}
+	return nil
+}
+
+// dataVolumes lists the names of the data volume claims of the logset
+func dataVolumes(ctx *recon.Context[*v1alpha1.LogSet]) ([]string, error) {
+	ls := ctx.Obj
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := ctx.List(pvcList, client.InNamespace(ls.Namespace), client.MatchingLabels(common.SubResourceLabels(ls))); err != nil {
+		return nil, errors.Wrap(err, "list data volumes")
+	}
+	var names []string
+	for _, pvc := range pvcList.Items {
+		if strings.HasPrefix(pvc.Name, dataPVCName(stsName(ls)+"-")) {
+			names = append(names, pvc.Name)
+		}
+	}
+	return names, nil
+}
+
+// bootstrapConfigSeeds reads the HAKeeper members from the bootstrap configmap that the log stores start with
+func bootstrapConfigSeeds(ctx *recon.Context[*v1alpha1.LogSet]) ([]string, bool, error) {
+	ls := ctx.Obj
+	cm := &corev1.ConfigMap{}
+	err, found := util.IsFound(ctx.Get(client.ObjectKey{Namespace: ls.Namespace, Name: bootstrapConfigMapName(ls)}, cm))
+	if err != nil || !found {
+		return nil, false, errors.Wrap(err, "get bootstrap configmap")
+	}
+	t := v1alpha1.NewTomlConfig(map[string]interface{}{})
+	if err := t.UnmarshalTOML([]byte(cm.Data[bootstrapFile])); err != nil {
+		return nil, false, errors.Wrap(err, "parse bootstrap config")
+	}
+	v := t.Get("logservice", "BootstrapConfig", "init-hakeeper-members")
+	if v == nil {
+		return nil, false, errors.Errorf("no HAKeeper members in configmap %s", cm.Name)
+	}
+	seeds, err := v.AsStringSlice()
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "parse HAKeeper members in configmap %s", cm.Name)
+	}
+	return seeds, true, nil
+}
+
+func recordBootstrapDecision(ctx *recon.Context[*v1alpha1.LogSet], replicas []bootstrapReplica) error {
+	serialized, err := json.Marshal(replicas)
+	if err != nil {
+		return errors.Wrap(err, "error serialize bootstrap replicas")
+	}
+	if ctx.Obj.Annotations == nil {
+		ctx.Obj.Annotations = map[string]string{}
+	}
+	ctx.Obj.Annotations[bootstrapAnnoKey] = string(serialized)
+	return ctx.Update(ctx.Obj)
+}
+
+func decodeBootstrapReplicas(decision string) ([]bootstrapReplica, error) {
+	var replicas []bootstrapReplica
+	if err := json.Unmarshal([]byte(decision), &replicas); err != nil {
+		return nil, errors.Wrap(err, "error deserialize boostrap replicas")
+	}
+	if len(replicas) == 0 {
+		return nil, errors.New("bootstrap decision has no replica")
+	}
+	return replicas, nil
+}
+
+// newBootstrapReplicas picks the first n pods as HAKeeper members, replica IDs are allocated after the previous decision
+// so that they never conflict with the replicas that are still known by the existing data
+func newBootstrapReplicas(previous []bootstrapReplica, n int) ([]bootstrapReplica, error) {
+	next := nextReplicaID(previous)
+	var replicas []bootstrapReplica
 	for i := 0; i < n; i++ {
-		rid := idRangeStart + i
+		rid := next + i
 		if rid > idRangeEnd {
 			return nil, errors.Errorf("ReplicaID %d exceed range, max allowed: %d", rid, idRangeEnd)
 		}
@@ -97,15 +218,18 @@ func bootstrap(ctx *recon.Context[*v1alpha1.LogSet]) ([]bootstrapReplica, error)
 			ReplicaID: rid,
 		})
 	}
-	serialized, err := json.Marshal(replicas)
-	if err != nil {
-		return nil, errors.Wrap(err, "error serialize bootstrap replicas")
-	}
-	if ctx.Obj.Annotations == nil {
-		ctx.Obj.Annotations = map[string]string{}
+	return replicas, nil
+}
+
+// nextReplicaID returns the first replica ID that is not used by the given bootstrap decision
+func nextReplicaID(replicas []bootstrapReplica) int {
+	next := idRangeStart
+	for _, r := range replicas {
+		if r.ReplicaID >= next {
+			next = r.ReplicaID + 1
+		}
 	}
-	ctx.Obj.Annotations[bootstrapAnnoKey] = string(serialized)
-	return replicas, ctx.Update(ctx.Obj)
+	return next
 }
 
 // encodeSeeds encode the bootstrap replicas decision to the configuration format
@@ -118,9 +242,33 @@ func encodeSeeds(brs []bootstrapReplica) []string {
 	return seeds
 }
 
+// decodeSeeds decode the HAKeeper members in the configuration format accepted by logservice
+func decodeSeeds(seeds []string) ([]bootstrapReplica, error) {
+	var replicas []bootstrapReplica
+	for _, seed := range seeds {
+		id, uuid, ok := strings.Cut(seed, ":")
+		if !ok || !strings.HasPrefix(uuid, ordinalUUIDPrefix) {
+			return nil, errors.Errorf("invalid HAKeeper member %s", seed)
+		}
+		rid, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid replica ID of HAKeeper member %s", seed)
+		}
+		ordinal, err := strconv.ParseInt(strings.TrimPrefix(uuid, ordinalUUIDPrefix), 16, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid store UUID of HAKeeper member %s", seed)
+		}
+		replicas = append(replicas, bootstrapReplica{Ordinal: int(ordinal), ReplicaID: rid})
+	}
+	if len(replicas) == 0 {
+		return nil, errors.New("no HAKeeper member")
+	}
+	return replicas, nil
+}
+
 // encodeOrdinal encode the pod ordinal to UUID
 func encodeOrdinal(ordinal int) string {
-	return fmt.Sprintf("00000000-0000-0000-0000-%012x", ordinal)
+	return v1alpha1.LogStoreUUID(ordinal)
 }
 
 func bootstrapConfigMapName(ls *v1alpha1.LogSet) string {
@@ -24,6 +24,7 @@ import (
 	"github.com/cespare/xxhash"
 	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
 	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	"github.com/matrixorigin/matrixone-operator/pkg/moinit"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -76,12 +77,12 @@ sed -i "/\[logservice\]/r ${gossipTmp}" ${conf}
 
 # append bootstrap config
 sed "/\[logservice\]/d" {{ .BootstrapFilePath }} >> ${conf}
-
+{{ if .WaitDNS }}
 # there is a chance that the dns is not yet added to kubedns and the
 # server will crash, wait before myself to be resolvable
 elapseTime=0
-period=1
-threshold=30
+period={{ .DNSWaitPeriod }}
+threshold={{ .DNSWaitTimeout }}
 while true; do
     sleep ${period}
     elapseTime=$(( elapseTime+period ))
@@ -94,11 +95,34 @@ while true; do
         echo "waiting pod dns name ${ADDR} resolvable" >&2
     fi
 done
-
+{{ end }}
 echo "/mo-service -cfg ${conf} $@"
 exec /mo-service -cfg ${conf} $@
 `))
 
+// moInitOptions builds the per-pod config by mo-init the same as startScriptTpl
+func moInitOptions(ls *v1alpha1.LogSet) *moinit.Options {
+	policy := ls.Spec.StartupPolicy
+	return &moinit.Options{
+		ConfigFiles: []string{
+			fmt.Sprintf("%s/%s", configPath, configFile),
+			fmt.Sprintf("%s/%s", gossipPath, gossipFile),
+			fmt.Sprintf("%s/%s", bootstrapPath, bootstrapFile),
+		},
+		UUID: moinit.UUIDLogStoreOrdinal,
+		Sets: []moinit.Set{
+			{Key: "logservice.uuid", Value: moinit.VarUUID},
+			{Key: "logservice.raft-address", Value: fmt.Sprintf("%s:%d", moinit.VarAddr, raftPort)},
+			{Key: "logservice.logservice-address", Value: fmt.Sprintf("%s:%d", moinit.VarAddr, logServicePort)},
+			{Key: "logservice.gossip-address", Value: fmt.Sprintf("%s:%d", moinit.VarPodIP, gossipPort)},
+			{Key: "logservice.gossip-address-v2", Value: fmt.Sprintf("%s:%d", moinit.VarAddr, gossipPort)},
+		},
+		WaitDNS:    policy.WaitDNS(),
+		DNSTimeout: policy.GetDNSWaitTimeout(),
+		DNSPeriod:  policy.GetDNSWaitPeriod(),
+	}
+}
+
 type model struct {
 	RaftPort          int
 	LogServicePort    int
@@ -106,6 +130,8 @@ type model struct {
 	ConfigFilePath    string
 	BootstrapFilePath string
 	GossipFilePath    string
+
+	common.DNSWait
 }
 
 // buildGossipSeedsConfigMap build the gossip seeds configmap for log service, which will not trigger rolling-update
@@ -162,6 +188,7 @@ func buildConfigMap(ls *v1alpha1.LogSet) (*corev1.ConfigMap, error) {
 		ConfigFilePath:    fmt.Sprintf("%s/%s", configPath, configFile),
 		BootstrapFilePath: fmt.Sprintf("%s/%s", bootstrapPath, bootstrapFile),
 		GossipFilePath:    fmt.Sprintf("%s/%s", gossipPath, gossipFile),
+		DNSWait:           common.NewDNSWait(ls.Spec.StartupPolicy.WaitDNS(), ls.Spec.StartupPolicy),
 	})
 	if err != nil {
 		return nil, err
@@ -15,10 +15,15 @@ package logset
 
 import (
 	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/moinit"
+	. "github.com/onsi/gomega"
 	kruisev1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func Test_gossipSeeds(t *testing.T) {
@@ -79,3 +84,99 @@ func Test_gossipSeeds(t *testing.T) {
 		})
 	}
 }
+
+func Test_moInitOptions(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ls := &v1alpha1.LogSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "test",
+		},
+		Spec: v1alpha1.LogSetSpec{
+			PodSet: v1alpha1.PodSet{Replicas: 3},
+			InitialConfig: v1alpha1.InitialConfig{
+				LogShards:        pointer.Int(1),
+				DNShards:         pointer.Int(1),
+				LogShardReplicas: pointer.Int(3),
+			},
+		},
+	}
+	sts := &kruisev1.StatefulSet{Spec: kruisev1.StatefulSetSpec{Replicas: pointer.Int32(3)}}
+	cm, err := buildConfigMap(ls)
+	g.Expect(err).To(Succeed())
+	gossip, err := buildGossipSeedsConfigMap(ls, sts)
+	g.Expect(err).To(Succeed())
+	bc, err := bootstrapConfigMap(ls, []bootstrapReplica{{Ordinal: 0, ReplicaID: 131072}})
+	g.Expect(err).To(Succeed())
+	files := map[string]string{
+		configPath + "/" + configFile:       cm.Data[configFile],
+		gossipPath + "/" + gossipFile:       gossip.Data[gossipFile],
+		bootstrapPath + "/" + bootstrapFile: bc.Data[bootstrapFile],
+	}
+
+	env := moinit.Env{PodName: "test-log-1", Namespace: "default", HeadlessService: headlessSvcName(ls), PodIP: "10.0.0.1"}
+	conf, err := moinit.Render(moInitOptions(ls), env, func(f string) ([]byte, error) {
+		s, ok := files[f]
+		if !ok {
+			return nil, errors.Errorf("%s not mounted", f)
+		}
+		return []byte(s), nil
+	})
+	g.Expect(err).To(Succeed())
+	addr := "test-log-1." + headlessSvcName(ls) + ".default.svc"
+	g.Expect(conf.Get("service-type").MustString()).To(Equal(serviceTypeLog))
+	g.Expect(conf.Get("logservice", "uuid").MustString()).To(Equal(encodeOrdinal(1)))
+	g.Expect(conf.Get("logservice", "raft-address").MustString()).To(Equal(addr + ":32000"))
+	g.Expect(conf.Get("logservice", "logservice-address").MustString()).To(Equal(addr + ":32001"))
+	g.Expect(conf.Get("logservice", "gossip-address").MustString()).To(Equal("10.0.0.1:32002"))
+	g.Expect(conf.Get("logservice", "gossip-address-v2").MustString()).To(Equal(addr + ":32002"))
+	g.Expect(conf.Get("logservice", "gossip-seed-addresses").MustStringSlice()).To(HaveLen(3))
+	g.Expect(conf.Get("logservice", "BootstrapConfig", "init-hakeeper-members").MustStringSlice()).To(Equal([]string{"131072:" + encodeOrdinal(0)}))
+}
+
+func Test_startScriptDNSWait(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   *v1alpha1.StartupPolicy
+		wait     bool
+		contains []string
+	}{{
+		name:     "default",
+		wait:     true,
+		contains: []string{"period=1\n", "threshold=30\n"},
+	}, {
+		name: "configured",
+		policy: &v1alpha1.StartupPolicy{
+			DNSWaitTimeout: &metav1.Duration{Duration: 2 * time.Minute},
+			DNSWaitPeriod:  &metav1.Duration{Duration: 2500 * time.Millisecond},
+		},
+		wait:     true,
+		contains: []string{"period=3\n", "threshold=120\n"},
+	}, {
+		name:   "skip",
+		policy: &v1alpha1.StartupPolicy{SkipDNSWait: true},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			ls := &v1alpha1.LogSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"},
+				Spec: v1alpha1.LogSetSpec{
+					PodSet:        v1alpha1.PodSet{Replicas: 3},
+					StartupPolicy: tt.policy,
+				},
+			}
+			cm, err := buildConfigMap(ls)
+			g.Expect(err).To(Succeed())
+			script := cm.Data[entrypoint]
+			if !tt.wait {
+				g.Expect(script).NotTo(ContainSubstring("nslookup"))
+				return
+			}
+			g.Expect(script).To(ContainSubstring("nslookup ${ADDR}"))
+			for _, s := range tt.contains {
+				g.Expect(script).To(ContainSubstring(s))
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@
 package logset
 
 import (
+	"fmt"
 	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -48,6 +49,9 @@ var _ recon.Actor[*v1alpha1.LogSet] = &Actor{}
 
 type Actor struct {
 	FailoverEnabled bool
+	// HAKeeper is used to check log shard replicas before removing log stores,
+	// scale-in is not guarded by HAKeeper if it is nil
+	HAKeeper HAKeeperClient
 }
 
 type WithResources struct {
@@ -74,9 +78,15 @@ func (r *Actor) Observe(ctx *recon.Context[*v1alpha1.LogSet]) (recon.Action[*v1a
 	if err != nil {
 		return nil, errors.Wrap(err, "get logservice statefulset")
 	}
+	if _, ok := ls.Annotations[v1alpha1.LogSetReBootstrapAnnoKey]; ok {
+		return r.ReBootstrap, nil
+	}
 	if !foundDiscovery || !foundSts {
 		return r.Create, nil
 	}
+	if err := validateBootstrapDecision(ctx); err != nil {
+		return nil, err
+	}
 
 	// calculate status
 	podList := &corev1.PodList{}
@@ -86,7 +96,19 @@ func (r *Actor) Observe(ctx *recon.Context[*v1alpha1.LogSet]) (recon.Action[*v1a
 		return nil, errors.Wrap(err, "list logservice pods")
 	}
 
-	common.CollectStoreStatus(&ls.Status.FailoverStatus, podList.Items)
+	var storeFns []common.StoreFn
+	if r.HAKeeper != nil && ls.Status.Discovery != nil {
+		state, err := r.HAKeeper.GetClusterState(ls)
+		if err != nil {
+			// fallback to collect store status from pods only
+			ctx.Log.Info("cannot get cluster state from HAKeeper", "error", err.Error())
+		} else {
+			storeFns = append(storeFns, storeFn(state))
+			ls.Status.Stores = storeStatuses(state, podList.Items)
+			syncShardTopology(ls, state)
+		}
+	}
+	common.CollectStoreStatus(&ls.Status.FailoverStatus, podList.Items, storeFns...)
 	if len(ls.Status.AvailableStores) >= int(ls.Spec.Replicas) {
 		ls.Status.SetCondition(metav1.Condition{
 			Type:   recon.ConditionTypeReady,
@@ -104,9 +126,11 @@ func (r *Actor) Observe(ctx *recon.Context[*v1alpha1.LogSet]) (recon.Action[*v1a
 		Address: discoverySvcAddress(ls),
 	}
 	switch {
+	case recoveryApproved(ls):
+		return r.with(sts).Recover, nil
 	case len(ls.Status.StoresFailedFor(ls.Spec.GetStoreFailureTimeout().Duration)) > 0:
 		return r.with(sts).Repair, nil
-	case ls.Spec.Replicas != *sts.Spec.Replicas:
+	case ls.Spec.Replicas != *sts.Spec.Replicas, ls.Status.ScaleIn != nil:
 		return r.with(sts).Scale, nil
 	}
 	origin := sts.DeepCopy()
@@ -119,9 +143,15 @@ func (r *Actor) Observe(ctx *recon.Context[*v1alpha1.LogSet]) (recon.Action[*v1a
 	if err = ctx.Update(sts, client.DryRunAll); err != nil {
 		return nil, errors.Wrap(err, "dry run update logset statefulset")
 	}
+	if r.HAKeeper != nil && !equality.Semantic.DeepEqual(origin.Spec.Template, sts.Spec.Template) {
+		holdRollingUpdate(sts)
+	}
 	if !equality.Semantic.DeepEqual(origin, sts) {
 		return r.with(sts).Update, nil
 	}
+	if r.HAKeeper != nil && rollingUpdateInProgress(sts) {
+		return r.with(sts).RollingUpdate, nil
+	}
 
 	if err = r.syncBucketClaim(ctx, sts); err != nil {
 		return nil, errors.Wrap(err, "sync bucket claim")
@@ -133,6 +163,9 @@ func (r *Actor) Observe(ctx *recon.Context[*v1alpha1.LogSet]) (recon.Action[*v1a
 	}
 
 	if recon.IsReady(&ls.Status.ConditionalStatus) && len(ls.Status.FailedStores) == 0 {
+		if r.HAKeeper != nil && len(sts.Spec.ReserveOrdinals) > 0 {
+			return r.with(sts).ReclaimOrdinals, nil
+		}
 		ctx.Log.Info("logset synced")
 		return nil, nil
 	}
@@ -144,7 +177,7 @@ func (r *Actor) Create(ctx *recon.Context[*v1alpha1.LogSet]) error {
 	ls := ctx.Obj
 
 	// build resources required by a logset
-	bc, err := buildBootstrapConfig(ctx)
+	bc, err := r.buildBootstrapConfig(ctx)
 	if err != nil {
 		return err
 	}
@@ -187,21 +220,6 @@ func (r *Actor) Create(ctx *recon.Context[*v1alpha1.LogSet]) error {
 	return nil
 }
 
-// Scale scale-out/in the log set pods to match the desired state
-// TODO(aylei): special treatment for scale-in
-func (r *WithResources) Scale(ctx *recon.Context[*v1alpha1.LogSet]) error {
-	ctx.Log.Info("scale logset")
-	err := ctx.Patch(r.sts, func() error {
-		syncReplicas(ctx.Obj, r.sts)
-		return nil
-	})
-	if err != nil {
-		return err
-	}
-	// also update gossip config after scale
-	return updateGossipConfig(ctx, r.sts)
-}
-
 // Repair repairs failed log set pods to match the desired state
 func (r *WithResources) Repair(ctx *recon.Context[*v1alpha1.LogSet]) error {
 	if !r.FailoverEnabled {
@@ -211,6 +229,8 @@ func (r *WithResources) Repair(ctx *recon.Context[*v1alpha1.LogSet]) error {
 	minorityLimit := (*ctx.Obj.Spec.InitialConfig.LogShardReplicas) / 2
 	if len(ctx.Obj.Status.FailedStores) > minorityLimit {
 		ctx.Log.Info("majority failure might happen, wait for human intervention")
+		ctx.Event.EmitEventGeneric("MajorityFailure", fmt.Sprintf("majority of log stores failed, set annotation %s=%s to clone the data of the surviving stores before recovering HAKeeper manually",
+			v1alpha1.LogSetRecoveryAnnoKey, v1alpha1.LogSetRecoveryApproved), nil)
 		return nil
 	}
 	if len(r.sts.Spec.ReserveOrdinals) >= minorityLimit {
@@ -255,8 +275,8 @@ func (r *WithResources) Repair(ctx *recon.Context[*v1alpha1.LogSet]) error {
 	return updateGossipConfig(ctx, r.sts)
 }
 
-// Update rolling-update the log set pods to match the desired state
-// TODO(aylei): should logset controller take care of graceful rolling?
+// Update updates the log set statefulset to match the desired state, the rolling-update of pods is
+// driven by RollingUpdate if HAKeeper is available
 func (r *WithResources) Update(ctx *recon.Context[*v1alpha1.LogSet]) error {
 	return ctx.Update(r.sts)
 }
@@ -0,0 +1,147 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logset
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/matrixorigin/controller-runtime/pkg/util"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	"github.com/matrixorigin/matrixone/pkg/hakeeper"
+	pb "github.com/matrixorigin/matrixone/pkg/pb/logservice"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// haKeeperConfig is the default HAKeeper config that is used to judge the heartbeat of log stores
+var haKeeperConfig = func() hakeeper.Config {
+	c := hakeeper.Config{}
+	c.Fill()
+	return c
+}()
+
+// HAKeeperClient gets the cluster state of a logset from its HAKeeper
+type HAKeeperClient interface {
+	GetClusterState(ls *v1alpha1.LogSet) (pb.CheckerState, error)
+	// CheckLogStoreRemoval returns the log shard replicas on the log store, or an error if the log store
+	// cannot leave the logset without losing log shard replicas
+	CheckLogStoreRemoval(ls *v1alpha1.LogSet, storeUUID string) ([]string, error)
+}
+
+// storeReplicas returns the log shard replicas that HAKeeper reports on the given log store,
+// in the form of <shardID>:<replicaID>
+func storeReplicas(state pb.LogState, storeUUID string) []string {
+	var replicas []string
+	for _, shard := range state.Shards {
+		for replicaID, uuid := range shard.Replicas {
+			if uuid == storeUUID {
+				replicas = append(replicas, fmt.Sprintf("%d:%d", shard.ShardID, replicaID))
+			}
+		}
+	}
+	sort.Strings(replicas)
+	return replicas
+}
+
+// unhealthyShards returns the IDs of log shards that have no known leader or have fewer replicas
+// on known log stores than the number of replicas recorded in the cluster info
+func unhealthyShards(state pb.CheckerState) []uint64 {
+	var unhealthy []uint64
+	for _, record := range state.ClusterInfo.LogShards {
+		shard, ok := state.LogState.Shards[record.ShardID]
+		if !ok || shard.LeaderID == 0 {
+			unhealthy = append(unhealthy, record.ShardID)
+			continue
+		}
+		var replicas uint64
+		for _, uuid := range shard.Replicas {
+			if _, ok := state.LogState.Stores[uuid]; ok {
+				replicas++
+			}
+		}
+		if replicas < record.NumberOfReplicas {
+			unhealthy = append(unhealthy, record.ShardID)
+		}
+	}
+	sort.Slice(unhealthy, func(i, j int) bool { return unhealthy[i] < unhealthy[j] })
+	return unhealthy
+}
+
+// maxShardReplicas returns the largest number of replicas among all log shards in the cluster info
+func maxShardReplicas(info pb.ClusterInfo) int {
+	var max uint64
+	for _, record := range info.LogShards {
+		if record.NumberOfReplicas > max {
+			max = record.NumberOfReplicas
+		}
+	}
+	return int(max)
+}
+
+// storeUUID returns the UUID of the log store that runs in the given pod
+func storeUUID(podName string) (string, bool) {
+	ordinal, err := util.PodOrdinal(podName)
+	if err != nil {
+		return "", false
+	}
+	return encodeOrdinal(ordinal), true
+}
+
+// storeFn marks a log store down if HAKeeper has not received its heartbeat within the log store timeout
+// or the log store is missing from the cluster view after HAKeeper is running, even if the pod is ready
+func storeFn(state pb.CheckerState) common.StoreFn {
+	return func(store *v1alpha1.Store) {
+		if state.State != pb.HAKeeperRunning {
+			return
+		}
+		uuid, ok := storeUUID(store.PodName)
+		if !ok {
+			return
+		}
+		info, ok := state.LogState.Stores[uuid]
+		if !ok || haKeeperConfig.LogStoreExpired(info.Tick, state.Tick) {
+			store.Phase = v1alpha1.StorePhaseDown
+		}
+	}
+}
+
+// storeStatuses collects the HAKeeper view of the log stores that run in the given pods
+func storeStatuses(state pb.CheckerState, pods []corev1.Pod) []v1alpha1.LogStoreStatus {
+	var stores []v1alpha1.LogStoreStatus
+	for _, pod := range pods {
+		uuid, ok := storeUUID(pod.Name)
+		if !ok {
+			continue
+		}
+		info, registered := state.LogState.Stores[uuid]
+		store := v1alpha1.LogStoreStatus{
+			PodName:          pod.Name,
+			UUID:             uuid,
+			Tick:             int64(info.Tick),
+			HeartbeatExpired: !registered || haKeeperConfig.LogStoreExpired(info.Tick, state.Tick),
+			Replicas:         storeReplicas(state.LogState, uuid),
+		}
+		for _, shard := range state.LogState.Shards {
+			if shard.LeaderID != 0 && shard.Replicas[shard.LeaderID] == uuid {
+				store.LeaderShards = append(store.LeaderShards, int64(shard.ShardID))
+			}
+		}
+		sort.Slice(store.LeaderShards, func(i, j int) bool { return store.LeaderShards[i] < store.LeaderShards[j] })
+		stores = append(stores, store)
+	}
+	sort.Slice(stores, func(i, j int) bool { return stores[i].PodName < stores[j].PodName })
+	return stores
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logset
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	pb "github.com/matrixorigin/matrixone/pkg/pb/logservice"
+	"github.com/matrixorigin/matrixone/pkg/pb/metadata"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testCheckerState() pb.CheckerState {
+	return pb.CheckerState{
+		ClusterInfo: pb.ClusterInfo{LogShards: []metadata.LogShardRecord{
+			{ShardID: 0, NumberOfReplicas: 3},
+			{ShardID: 1, NumberOfReplicas: 3},
+		}},
+		LogState: pb.LogState{
+			Shards: map[uint64]pb.LogShardInfo{
+				0: {ShardID: 0, LeaderID: 1, Replicas: map[uint64]string{1: "a", 2: "b", 3: "c"}},
+				1: {ShardID: 1, LeaderID: 5, Replicas: map[uint64]string{4: "b", 5: "c", 6: "d"}},
+			},
+			Stores: map[string]pb.LogStoreInfo{"a": {}, "b": {}, "c": {}, "d": {}},
+		},
+	}
+}
+
+func Test_storeReplicas(t *testing.T) {
+	g := NewGomegaWithT(t)
+	state := testCheckerState()
+	g.Expect(storeReplicas(state.LogState, "b")).To(Equal([]string{"0:2", "1:4"}))
+	g.Expect(storeReplicas(state.LogState, "d")).To(Equal([]string{"1:6"}))
+	g.Expect(storeReplicas(state.LogState, "e")).To(BeEmpty())
+}
+
+func Test_unhealthyShards(t *testing.T) {
+	g := NewGomegaWithT(t)
+	state := testCheckerState()
+	g.Expect(unhealthyShards(state)).To(BeEmpty())
+	g.Expect(maxShardReplicas(state.ClusterInfo)).To(Equal(3))
+
+	// store d is gone
+	delete(state.LogState.Stores, "d")
+	g.Expect(unhealthyShards(state)).To(Equal([]uint64{1}))
+
+	// shard 0 has no leader
+	shard := state.LogState.Shards[0]
+	shard.LeaderID = 0
+	state.LogState.Shards[0] = shard
+	g.Expect(unhealthyShards(state)).To(Equal([]uint64{0, 1}))
+}
+
+func Test_storeFn(t *testing.T) {
+	// ticks of 10 minutes, log stores expire after 5 minutes by default
+	now := uint64(10 * 60 * haKeeperConfig.TickPerSecond)
+	state := pb.CheckerState{
+		State: pb.HAKeeperRunning,
+		Tick:  now,
+		LogState: pb.LogState{Stores: map[string]pb.LogStoreInfo{
+			encodeOrdinal(0): {Tick: now - 10},
+			encodeOrdinal(1): {Tick: 0},
+		}},
+	}
+	tests := []struct {
+		name  string
+		state pb.CheckerState
+		pod   string
+		want  string
+	}{
+		{name: "heartbeat in time", state: state, pod: "mo-log-0", want: v1alpha1.StorePhaseUp},
+		{name: "heartbeat expired", state: state, pod: "mo-log-1", want: v1alpha1.StorePhaseDown},
+		{name: "missing in HAKeeper", state: state, pod: "mo-log-2", want: v1alpha1.StorePhaseDown},
+		{name: "HAKeeper not running", state: pb.CheckerState{State: pb.HAKeeperBootstrapping}, pod: "mo-log-2", want: v1alpha1.StorePhaseUp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			store := &v1alpha1.Store{PodName: tt.pod, Phase: v1alpha1.StorePhaseUp}
+			storeFn(tt.state)(store)
+			g.Expect(store.Phase).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_storeStatuses(t *testing.T) {
+	g := NewGomegaWithT(t)
+	state := pb.CheckerState{
+		Tick: 100,
+		LogState: pb.LogState{
+			Shards: map[uint64]pb.LogShardInfo{
+				0: {ShardID: 0, LeaderID: 1, Replicas: map[uint64]string{1: encodeOrdinal(0), 2: encodeOrdinal(1)}},
+				1: {ShardID: 1, LeaderID: 4, Replicas: map[uint64]string{3: encodeOrdinal(0), 4: encodeOrdinal(1)}},
+			},
+			Stores: map[string]pb.LogStoreInfo{encodeOrdinal(0): {Tick: 90}, encodeOrdinal(1): {Tick: 95}},
+		},
+	}
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "mo-log-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "mo-log-0"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "mo-log-2"}},
+	}
+	g.Expect(storeStatuses(state, pods)).To(Equal([]v1alpha1.LogStoreStatus{{
+		PodName:      "mo-log-0",
+		UUID:         encodeOrdinal(0),
+		Tick:         90,
+		Replicas:     []string{"0:1", "1:3"},
+		LeaderShards: []int64{0},
+	}, {
+		PodName:      "mo-log-1",
+		UUID:         encodeOrdinal(1),
+		Tick:         95,
+		Replicas:     []string{"0:2", "1:4"},
+		LeaderShards: []int64{1},
+	}, {
+		PodName:          "mo-log-2",
+		UUID:             encodeOrdinal(2),
+		HeartbeatExpired: true,
+	}}))
+}
@@ -0,0 +1,166 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logset
+
+import (
+	"fmt"
+
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/json"
+)
+
+// ReBootstrap discards the bootstrap decision of the logset and makes a new one as requested by the
+// LogSetReBootstrapAnnoKey annotation. The request is recorded in the status before the new decision takes
+// effect, and the bootstrap config is rewritten so that the log stores bootstrap HAKeeper again once they
+// start without data.
+func (r *Actor) ReBootstrap(ctx *recon.Context[*v1alpha1.LogSet]) error {
+	ls := ctx.Obj
+	if recoveryApproved(ls) {
+		return recon.ErrReSync("wait the majority-failure recovery to complete before re-bootstrap", reSyncAfter)
+	}
+	reason := ls.Annotations[v1alpha1.LogSetReBootstrapAnnoKey]
+	var previous []bootstrapReplica
+	if decision, ok := ls.Annotations[bootstrapAnnoKey]; ok {
+		old, err := decodeBootstrapReplicas(decision)
+		if err != nil {
+			// a corrupted decision is discarded as well
+			ctx.Log.Info("discard invalid bootstrap decision", "decision", decision, "error", err.Error())
+		}
+		previous = old
+	}
+	replicas, err := newBootstrapReplicas(previous, *ls.Spec.InitialConfig.LogShardReplicas)
+	if err != nil {
+		return err
+	}
+	record := v1alpha1.LogSetReBootstrapRecord{
+		Reason:   reason,
+		Previous: encodeSeeds(previous),
+		Members:  encodeSeeds(replicas),
+		Time:     metav1.Now(),
+	}
+	if !reBootstrapRecorded(ls.Status.ReBootstraps, record) {
+		// audit the re-bootstrap before it takes effect
+		ls.Status.ReBootstraps = append(ls.Status.ReBootstraps, record)
+		if err := ctx.UpdateStatus(ls); err != nil {
+			return errors.Wrap(err, "record re-bootstrap")
+		}
+	}
+
+	bc, err := bootstrapConfigMap(ls, replicas)
+	if err != nil {
+		return err
+	}
+	desired := bc.DeepCopy()
+	if err := recon.CreateOwnedOrUpdate(ctx, bc, func() error {
+		bc.Data = desired.Data
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "update bootstrap config")
+	}
+	serialized, err := json.Marshal(replicas)
+	if err != nil {
+		return errors.Wrap(err, "error serialize bootstrap replicas")
+	}
+	// commit the decision and consume the request at once
+	ls.Annotations[bootstrapAnnoKey] = string(serialized)
+	delete(ls.Annotations, v1alpha1.LogSetReBootstrapAnnoKey)
+	if err := ctx.Update(ls); err != nil {
+		return errors.Wrap(err, "record bootstrap replicas")
+	}
+	ctx.Event.EmitEventGeneric("ReBootstrap", fmt.Sprintf("HAKeeper members re-bootstrapped from %v to %v, reason: %s",
+		record.Previous, record.Members, reason), nil)
+	return nil
+}
+
+// reBootstrapRecorded returns whether the re-bootstrap has been recorded by a previous attempt
+func reBootstrapRecorded(records []v1alpha1.LogSetReBootstrapRecord, record v1alpha1.LogSetReBootstrapRecord) bool {
+	if len(records) == 0 {
+		return false
+	}
+	last := records[len(records)-1]
+	return last.Reason == record.Reason && slices.Equal(last.Previous, record.Previous) && slices.Equal(last.Members, record.Members)
+}
+
+// validateBootstrapDecision cross-checks the bootstrap decision of the logset with the bootstrap config that
+// the log stores start with. A lost decision is restored from the bootstrap config, and a decision that cannot
+// be trusted is reported by the BootstrapDecisionValid condition instead of being regenerated.
+func validateBootstrapDecision(ctx *recon.Context[*v1alpha1.LogSet]) error {
+	ls := ctx.Obj
+	seeds, found, err := bootstrapConfigSeeds(ctx)
+	if err != nil {
+		return err
+	}
+	decision, recorded := ls.Annotations[bootstrapAnnoKey]
+	if !recorded && found {
+		replicas, err := decodeSeeds(seeds)
+		if err == nil {
+			ctx.Event.EmitEventGeneric("BootstrapDecisionRestored", fmt.Sprintf("bootstrap decision %v restored from configmap %s", seeds, bootstrapConfigMapName(ls)), nil)
+			if err := recordBootstrapDecision(ctx, replicas); err != nil {
+				return errors.Wrap(err, "restore bootstrap decision")
+			}
+			decision = ls.Annotations[bootstrapAnnoKey]
+			recorded = true
+		}
+	}
+	var msg string
+	if recorded {
+		msg = bootstrapDecisionConflict(decision, seeds)
+	} else {
+		msg = fmt.Sprintf("bootstrap decision is lost and cannot be restored, set annotation %s to re-bootstrap", v1alpha1.LogSetReBootstrapAnnoKey)
+	}
+	if msg != "" {
+		ls.Status.SetCondition(metav1.Condition{
+			Type:    v1alpha1.LogSetConditionTypeBootstrapDecisionValid,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Invalid",
+			Message: msg,
+		})
+		return nil
+	}
+	ls.Status.SetCondition(metav1.Condition{
+		Type:   v1alpha1.LogSetConditionTypeBootstrapDecisionValid,
+		Status: metav1.ConditionTrue,
+		Reason: "Valid",
+	})
+	return nil
+}
+
+// bootstrapDecisionConflict returns why the bootstrap decision cannot be trusted, or an empty string if it is valid
+func bootstrapDecisionConflict(decision string, seeds []string) string {
+	replicas, err := decodeBootstrapReplicas(decision)
+	if err != nil {
+		return fmt.Sprintf("invalid bootstrap decision: %v", err)
+	}
+	ordinals := map[int]bool{}
+	ids := map[int]bool{}
+	for _, r := range replicas {
+		if r.ReplicaID < idRangeStart || r.ReplicaID > idRangeEnd {
+			return fmt.Sprintf("replica ID %d of bootstrap decision is out of range [%d, %d]", r.ReplicaID, idRangeStart, idRangeEnd)
+		}
+		if ordinals[r.Ordinal] || ids[r.ReplicaID] {
+			return fmt.Sprintf("bootstrap decision %s has duplicated members", decision)
+		}
+		ordinals[r.Ordinal] = true
+		ids[r.ReplicaID] = true
+	}
+	if seeds != nil && !slices.Equal(encodeSeeds(replicas), seeds) {
+		return fmt.Sprintf("bootstrap decision %v conflicts with the bootstrap config %v", encodeSeeds(replicas), seeds)
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logset
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	. "github.com/onsi/gomega"
+)
+
+func Test_decodeSeeds(t *testing.T) {
+	g := NewGomegaWithT(t)
+	replicas := []bootstrapReplica{{Ordinal: 0, ReplicaID: 131072}, {Ordinal: 11, ReplicaID: 131073}}
+	got, err := decodeSeeds(encodeSeeds(replicas))
+	g.Expect(err).To(Succeed())
+	g.Expect(got).To(Equal(replicas))
+
+	_, err = decodeSeeds([]string{"131072:not-an-ordinal-uuid"})
+	g.Expect(err).To(HaveOccurred())
+	_, err = decodeSeeds(nil)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func Test_newBootstrapReplicas(t *testing.T) {
+	g := NewGomegaWithT(t)
+	got, err := newBootstrapReplicas(nil, 3)
+	g.Expect(err).To(Succeed())
+	g.Expect(got).To(Equal([]bootstrapReplica{{0, 131072}, {1, 131073}, {2, 131074}}))
+
+	// new replica IDs never conflict with the previous decision
+	got, err = newBootstrapReplicas([]bootstrapReplica{{Ordinal: 1, ReplicaID: 131080}}, 2)
+	g.Expect(err).To(Succeed())
+	g.Expect(got).To(Equal([]bootstrapReplica{{0, 131081}, {1, 131082}}))
+
+	_, err = newBootstrapReplicas([]bootstrapReplica{{Ordinal: 0, ReplicaID: idRangeEnd}}, 1)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func Test_bootstrapDecisionConflict(t *testing.T) {
+	seeds := encodeSeeds([]bootstrapReplica{{0, 131072}, {1, 131073}, {2, 131074}})
+	tests := []struct {
+		name     string
+		decision string
+		seeds    []string
+		valid    bool
+	}{{
+		name:     "match bootstrap config",
+		decision: `[{"ordinal":0,"replicaId":131072},{"ordinal":1,"replicaId":131073},{"ordinal":2,"replicaId":131074}]`,
+		seeds:    seeds,
+		valid:    true,
+	}, {
+		name:     "no bootstrap config",
+		decision: `[{"ordinal":0,"replicaId":131072}]`,
+		valid:    true,
+	}, {
+		name:     "conflict with bootstrap config",
+		decision: `[{"ordinal":0,"replicaId":131072}]`,
+		seeds:    seeds,
+	}, {
+		name:     "malformed",
+		decision: `{`,
+	}, {
+		name:     "empty",
+		decision: `[]`,
+	}, {
+		name:     "out of range",
+		decision: `[{"ordinal":0,"replicaId":1}]`,
+	}, {
+		name:     "duplicated",
+		decision: `[{"ordinal":0,"replicaId":131072},{"ordinal":0,"replicaId":131073}]`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(bootstrapDecisionConflict(tt.decision, tt.seeds) == "").To(Equal(tt.valid))
+		})
+	}
+}
+
+func Test_reBootstrapRecorded(t *testing.T) {
+	g := NewGomegaWithT(t)
+	record := v1alpha1.LogSetReBootstrapRecord{Reason: "disk lost", Members: []string{"131073:a"}}
+	g.Expect(reBootstrapRecorded(nil, record)).To(BeFalse())
+	g.Expect(reBootstrapRecorded([]v1alpha1.LogSetReBootstrapRecord{record}, record)).To(BeTrue())
+	g.Expect(reBootstrapRecorded([]v1alpha1.LogSetReBootstrapRecord{record, {Reason: "other"}}, record)).To(BeFalse())
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logset
+
+import (
+	"fmt"
+	"sort"
+
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/controller-runtime/pkg/util"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	pb "github.com/matrixorigin/matrixone/pkg/pb/logservice"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ReclaimOrdinals drops an ordinal reserved by failover from the statefulset once HAKeeper has re-replicated
+// all replicas of the replaced store, so that the failover capacity is restored. The orphaned pod and the data
+// volume of the replaced store are garbage collected first, otherwise a new store with the same identity would
+// be started on the stale data. Dropping a reserved ordinal alone would make the statefulset move the healthy
+// store of the highest ordinal to the dropped ordinal, so the statefulset is scaled out by one at the same time
+// to re-create the store at the dropped ordinal, and the surplus store of the highest ordinal is then removed
+// by the scale-in, which is gated by HAKeeper. Ordinals are reclaimed one at a time when all log shards are healthy.
+func (r *WithResources) ReclaimOrdinals(ctx *recon.Context[*v1alpha1.LogSet]) error {
+	ls := ctx.Obj
+	state, err := r.HAKeeper.GetClusterState(ls)
+	if err != nil {
+		return err
+	}
+	if unhealthy := unhealthyShards(state); len(unhealthy) > 0 {
+		return recon.ErrReSync(fmt.Sprintf("wait log shards %v to be re-replicated before reclaiming reserved ordinals", unhealthy), reSyncAfter)
+	}
+	ordinal := reclaimableOrdinal(r.sts.Spec.ReserveOrdinals, state)
+	if ordinal < 0 {
+		return recon.ErrReSync("wait HAKeeper to remove replicas from the replaced stores", reSyncAfter)
+	}
+	podName := fmt.Sprintf("%s-%d", r.sts.Name, ordinal)
+
+	pod := &corev1.Pod{}
+	err, found := util.IsFound(ctx.Get(client.ObjectKey{Namespace: ls.Namespace, Name: podName}, pod))
+	if err != nil {
+		return errors.Wrapf(err, "get replaced pod %s", podName)
+	}
+	if found {
+		if controllerutil.RemoveFinalizer(pod, failoverDeletionFinalizer) {
+			if err := ctx.Update(pod); err != nil {
+				return errors.Wrapf(err, "remove finalizer of replaced pod %s", podName)
+			}
+		}
+		if err := util.Ignore(apierrors.IsNotFound, ctx.Delete(pod)); err != nil {
+			return errors.Wrapf(err, "delete replaced pod %s", podName)
+		}
+		return recon.ErrReSync(fmt.Sprintf("wait replaced pod %s to be deleted", podName), reSyncAfter)
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	err, found = util.IsFound(ctx.Get(client.ObjectKey{Namespace: ls.Namespace, Name: dataPVCName(podName)}, pvc))
+	if err != nil {
+		return errors.Wrapf(err, "get data volume of replaced pod %s", podName)
+	}
+	if found {
+		if ls.Spec.GetPVCRetentionPolicy() != v1alpha1.PVCRetentionPolicyDelete {
+			return recon.ErrReSync(fmt.Sprintf("data volume %s of replaced store is retained, delete it to reclaim ordinal %d", pvc.Name, ordinal), reSyncAfter)
+		}
+		if err := util.Ignore(apierrors.IsNotFound, ctx.Delete(pvc)); err != nil {
+			return errors.Wrapf(err, "delete data volume %s", pvc.Name)
+		}
+		return recon.ErrReSync(fmt.Sprintf("wait data volume %s to be deleted", pvc.Name), reSyncAfter)
+	}
+
+	replicas := *r.sts.Spec.Replicas + 1
+	r.sts.Spec.ReserveOrdinals = lo.Without(r.sts.Spec.ReserveOrdinals, ordinal)
+	r.sts.Spec.Replicas = &replicas
+	if err := ctx.Update(r.sts); err != nil {
+		return errors.Wrapf(err, "reclaim ordinal %d", ordinal)
+	}
+	ctx.Event.EmitEventGeneric("OrdinalReclaimed", fmt.Sprintf("reserved ordinal %d of replaced store is reclaimed, the store of the highest ordinal will be removed by scale-in", ordinal), nil)
+	return updateGossipConfig(ctx, r.sts)
+}
+
+// reclaimableOrdinal returns the lowest reserved ordinal whose store has no replica in HAKeeper, or -1 if there is none
+func reclaimableOrdinal(reserved []int, state pb.CheckerState) int {
+	ordinals := append([]int{}, reserved...)
+	sort.Ints(ordinals)
+	for _, o := range ordinals {
+		if len(storeReplicas(state.LogState, encodeOrdinal(o))) == 0 {
+			return o
+		}
+	}
+	return -1
+}
@@ -0,0 +1,32 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logset
+
+import (
+	"testing"
+
+	pb "github.com/matrixorigin/matrixone/pkg/pb/logservice"
+	. "github.com/onsi/gomega"
+)
+
+func Test_reclaimableOrdinal(t *testing.T) {
+	g := NewGomegaWithT(t)
+	state := pb.CheckerState{LogState: pb.LogState{Shards: map[uint64]pb.LogShardInfo{
+		0: {ShardID: 0, Replicas: map[uint64]string{1: encodeOrdinal(0), 2: encodeOrdinal(2), 3: encodeOrdinal(3)}},
+	}}}
+	g.Expect(reclaimableOrdinal(nil, state)).To(Equal(-1))
+	g.Expect(reclaimableOrdinal([]int{2}, state)).To(Equal(-1))
+	g.Expect(reclaimableOrdinal([]int{4, 2, 1}, state)).To(Equal(1))
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logset
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/controller-runtime/pkg/util"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func recoveryApproved(ls *v1alpha1.LogSet) bool {
+	return ls.Annotations[v1alpha1.LogSetRecoveryAnnoKey] == v1alpha1.LogSetRecoveryApproved
+}
+
+// Recover prepares the majority-failure recovery approved by the LogSetRecoveryAnnoKey annotation by cloning
+// the data volumes of the surviving log stores, the progress is recorded in the status so that it can be resumed
+// after the operator restarts. The HAKeeper quorum is not restored here: MO only applies the bootstrap members
+// to log stores without data and provides no way to force new HAKeeper membership on the existing data of the
+// survivors, so rewriting the bootstrap config and restarting the survivors would reload the old membership.
+func (r *WithResources) Recover(ctx *recon.Context[*v1alpha1.LogSet]) error {
+	ls := ctx.Obj
+	rs := ls.Status.Recovery
+	if rs == nil || rs.Phase == v1alpha1.LogSetRecoverySnapshotted {
+		survivors := storePodNames(ls.Status.AvailableStores)
+		if len(survivors) == 0 {
+			return recon.ErrReSync("no surviving log store to recover from", reSyncAfter)
+		}
+		ls.Status.Recovery = &v1alpha1.LogSetRecoveryStatus{
+			Survivors: survivors,
+			StartTime: metav1.Now(),
+		}
+		return r.recoveryStep(ctx, v1alpha1.LogSetRecoverySnapshotting, fmt.Sprintf("clone data of %v", survivors))
+	}
+	return r.snapshotSurvivors(ctx)
+}
+
+// storePodNames returns the sorted pod names of the given stores
+func storePodNames(stores []v1alpha1.Store) []string {
+	var names []string
+	for _, s := range stores {
+		names = append(names, s.PodName)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *WithResources) recoveryStep(ctx *recon.Context[*v1alpha1.LogSet], phase v1alpha1.LogSetRecoveryPhase, msg string) error {
+	rs := ctx.Obj.Status.Recovery
+	rs.Phase = phase
+	rs.Message = msg
+	ctx.Event.EmitEventGeneric("LogSetRecovery", fmt.Sprintf("%s: %s", phase, msg), nil)
+	return ctx.UpdateStatus(ctx.Obj)
+}
+
+// snapshotSurvivors clones the data volume of each survivor before the cluster membership is touched
+func (r *WithResources) snapshotSurvivors(ctx *recon.Context[*v1alpha1.LogSet]) error {
+	ls := ctx.Obj
+	rs := ls.Status.Recovery
+	var snapshots []string
+	var pending []string
+	for _, pod := range rs.Survivors {
+		src := &corev1.PersistentVolumeClaim{}
+		if err := ctx.Get(client.ObjectKey{Namespace: ls.Namespace, Name: dataPVCName(pod)}, src); err != nil {
+			return errors.Wrapf(err, "get data volume of %s", pod)
+		}
+		snapshot := snapshotPVC(ls, src, rs.StartTime.Time)
+		if err := util.Ignore(apierrors.IsAlreadyExists, ctx.Create(snapshot)); err != nil {
+			return errors.Wrapf(err, "clone data volume of %s", pod)
+		}
+		if err := ctx.Get(client.ObjectKeyFromObject(snapshot), snapshot); err != nil {
+			return errors.Wrapf(err, "get clone of %s", pod)
+		}
+		if snapshot.Status.Phase != corev1.ClaimBound {
+			pending = append(pending, snapshot.Name)
+		}
+		snapshots = append(snapshots, snapshot.Name)
+	}
+	rs.Snapshots = snapshots
+	if len(pending) > 0 {
+		rs.Message = fmt.Sprintf("waiting for volume clones %v to be bound", pending)
+		return recon.ErrReSync(rs.Message, reSyncAfter)
+	}
+	delete(ls.Annotations, v1alpha1.LogSetRecoveryAnnoKey)
+	if err := ctx.Update(ls); err != nil {
+		return errors.Wrap(err, "remove recovery approval")
+	}
+	// the status is refreshed by the update
+	rs.CompletionTime = &metav1.Time{Time: time.Now()}
+	ls.Status.Recovery = rs
+	return r.recoveryStep(ctx, v1alpha1.LogSetRecoverySnapshotted,
+		fmt.Sprintf("data of survivors cloned to %v, HAKeeper quorum must be restored manually, e.g. by re-bootstrapping the logset with annotation %s on wiped volumes",
+			snapshots, v1alpha1.LogSetReBootstrapAnnoKey))
+}
+
+func snapshotPVC(ls *v1alpha1.LogSet, src *corev1.PersistentVolumeClaim, start time.Time) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: ls.Namespace,
+			// the clones are not owned by the logset so that they outlive the logset
+			Name:   fmt.Sprintf("%s-recovery-%d", src.Name, start.Unix()),
+			Labels: map[string]string{common.LogSetOwnerKey: ls.Name},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      src.Spec.AccessModes,
+			Resources:        src.Spec.Resources,
+			StorageClassName: src.Spec.StorageClassName,
+			VolumeMode:       src.Spec.VolumeMode,
+			DataSource: &corev1.TypedLocalObjectReference{
+				Kind: "PersistentVolumeClaim",
+				Name: src.Name,
+			},
+		},
+	}
+}
+
+// dataPVCName returns the name of the data volume claim of the given logset pod
+func dataPVCName(podName string) string {
+	return fmt.Sprintf("%s-%s", common.DataVolume, podName)
+}
@@ -0,0 +1,39 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logset
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_recoveryApproved(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ls := &v1alpha1.LogSet{}
+	g.Expect(recoveryApproved(ls)).To(BeFalse())
+	ls.Annotations = map[string]string{v1alpha1.LogSetRecoveryAnnoKey: "yes"}
+	g.Expect(recoveryApproved(ls)).To(BeFalse())
+	ls.Annotations[v1alpha1.LogSetRecoveryAnnoKey] = v1alpha1.LogSetRecoveryApproved
+	g.Expect(recoveryApproved(ls)).To(BeTrue())
+}
+
+func Test_storePodNames(t *testing.T) {
+	g := NewGomegaWithT(t)
+	stores := []v1alpha1.Store{{PodName: "mo-log-2"}, {PodName: "mo-log-0", LastTransitionTime: metav1.Now()}}
+	g.Expect(storePodNames(stores)).To(Equal([]string{"mo-log-0", "mo-log-2"}))
+}
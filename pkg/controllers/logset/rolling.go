@@ -0,0 +1,216 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logset
+
+import (
+	"fmt"
+	"time"
+
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/controller-runtime/pkg/util"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	pb "github.com/matrixorigin/matrixone/pkg/pb/logservice"
+	"github.com/openkruise/kruise-api/apps/pub"
+	kruisev1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// rollingUpdateNextLabel marks the logset pod that is released to the update revision next, the statefulset
+	// updates pods in the order of this label instead of the pod ordinal
+	rollingUpdateNextLabel = "matrixorigin.io/rolling-update-next"
+)
+
+// holdRollingUpdate partitions the statefulset so that no pod is updated to the new revision until it is
+// released by RollingUpdate. Pods are updated in the priority of rollingUpdateNextLabel, and the partition is
+// the number of pods that are kept at the current revision.
+func holdRollingUpdate(sts *kruisev1.StatefulSet) {
+	if sts.Spec.UpdateStrategy.RollingUpdate == nil {
+		sts.Spec.UpdateStrategy.RollingUpdate = &kruisev1.RollingUpdateStatefulSetStrategy{}
+	}
+	ru := sts.Spec.UpdateStrategy.RollingUpdate
+	ru.UnorderedUpdate = &kruisev1.UnorderedUpdateStrategy{
+		PriorityStrategy: &pub.UpdatePriorityStrategy{
+			WeightPriority: []pub.UpdatePriorityWeightTerm{{
+				Weight: 100,
+				MatchSelector: metav1.LabelSelector{
+					MatchLabels: map[string]string{rollingUpdateNextLabel: "true"},
+				},
+			}},
+		},
+	}
+	partition := *sts.Spec.Replicas
+	ru.Partition = &partition
+}
+
+func rollingUpdateInProgress(sts *kruisev1.StatefulSet) bool {
+	ru := sts.Spec.UpdateStrategy.RollingUpdate
+	return ru != nil && ru.Partition != nil && *ru.Partition > 0
+}
+
+// RollingUpdate releases the log set pods to the update revision one at a time. Pods whose log store hosts
+// no log shard leader are released first, so that leaders are re-elected as few times as possible.
+// A pod is released only if every log shard keeps its quorum without the store of the pod, and the next
+// pod will not be released until the updated pods are ready and every log shard is fully replicated and
+// has a leader again.
+// HAKeeper does not expose leadership transfer, so leaders are not moved off a pod before it restarts, the
+// leaders on the restarting store are re-elected by raft and the health check waits for every log shard to
+// have a leader before moving on.
+func (r *WithResources) RollingUpdate(ctx *recon.Context[*v1alpha1.LogSet]) error {
+	ls := ctx.Obj
+	if r.sts.Status.ObservedGeneration < r.sts.Generation {
+		return recon.ErrReSync("wait statefulset to be observed", reSyncAfter)
+	}
+
+	podList := &corev1.PodList{}
+	if err := ctx.List(podList, client.InNamespace(ls.Namespace), client.MatchingLabels(common.SubResourceLabels(ls))); err != nil {
+		return errors.Wrap(err, "list logservice pods")
+	}
+	var updated int32
+	var outdated []*corev1.Pod
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Labels[appsv1.StatefulSetRevisionLabel] != r.sts.Status.UpdateRevision {
+			outdated = append(outdated, pod)
+			continue
+		}
+		updated++
+		if !util.IsPodAvailable(pod, 0, metav1.Time{Time: time.Now()}) {
+			return recon.ErrReSync(fmt.Sprintf("wait pod %s to be ready", pod.Name), reSyncAfter)
+		}
+		if err := setRollingUpdateNext(ctx, pod, false); err != nil {
+			return err
+		}
+	}
+	// the statefulset still has released pods to update
+	if *r.sts.Spec.Replicas-*r.sts.Spec.UpdateStrategy.RollingUpdate.Partition > updated {
+		return recon.ErrReSync("wait released pod to be updated", reSyncAfter)
+	}
+
+	state, err := r.HAKeeper.GetClusterState(ls)
+	if err != nil {
+		return err
+	}
+	if unhealthy := unhealthyShards(state); len(unhealthy) > 0 {
+		msg := fmt.Sprintf("log shards %v are unhealthy", unhealthy)
+		if updated > 0 {
+			ls.Status.SetCondition(metav1.Condition{
+				Type:    v1alpha1.LogSetConditionTypeRollingUpdatePaused,
+				Status:  metav1.ConditionTrue,
+				Reason:  "HealthCheckFailed",
+				Message: msg,
+			})
+		}
+		return recon.ErrReSync(msg, reSyncAfter)
+	}
+	ls.Status.SetCondition(metav1.Condition{
+		Type:   v1alpha1.LogSetConditionTypeRollingUpdatePaused,
+		Status: metav1.ConditionFalse,
+		Reason: "HealthCheckPassed",
+	})
+
+	partition := *r.sts.Spec.Replicas - updated - 1
+	if next := nextPodToUpdate(state, outdated); next != nil {
+		uuid, _ := storeUUID(next.Name)
+		if lost := shardsLosingQuorum(state, uuid); len(lost) > 0 {
+			return recon.ErrReSync(fmt.Sprintf("log shards %v would lose quorum if pod %s restarts", lost, next.Name), reSyncAfter)
+		}
+		for _, pod := range outdated {
+			if err := setRollingUpdateNext(ctx, pod, pod == next); err != nil {
+				return err
+			}
+		}
+		ctx.Log.Info("release logset pod to the update revision", "pod", next.Name)
+	}
+	if partition < 0 {
+		// all pods are released
+		partition = 0
+	}
+	err = ctx.Patch(r.sts, func() error {
+		r.sts.Spec.UpdateStrategy.RollingUpdate.Partition = &partition
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "update statefulset partition")
+	}
+	return recon.ErrReSync(fmt.Sprintf("rolling update logset, %d pods left", partition), reSyncAfter)
+}
+
+// setRollingUpdateNext labels or unlabels the pod as the next one to be released to the update revision
+func setRollingUpdateNext(ctx *recon.Context[*v1alpha1.LogSet], pod *corev1.Pod, next bool) error {
+	if (pod.Labels[rollingUpdateNextLabel] == "true") == next {
+		return nil
+	}
+	err := ctx.Patch(pod, func() error {
+		if next {
+			if pod.Labels == nil {
+				pod.Labels = map[string]string{}
+			}
+			pod.Labels[rollingUpdateNextLabel] = "true"
+		} else {
+			delete(pod.Labels, rollingUpdateNextLabel)
+		}
+		return nil
+	})
+	return errors.Wrapf(err, "label pod %s", pod.Name)
+}
+
+// nextPodToUpdate picks the pod to be released to the update revision next from the outdated pods. Pods
+// whose log store hosts fewer log shard leaders go first and ties are broken by the highest ordinal.
+func nextPodToUpdate(state pb.CheckerState, pods []*corev1.Pod) *corev1.Pod {
+	leaders := map[string]int{}
+	for _, shard := range state.LogState.Shards {
+		if uuid, ok := shard.Replicas[shard.LeaderID]; ok {
+			leaders[uuid]++
+		}
+	}
+	var next *corev1.Pod
+	nextLeaders, nextOrdinal := 0, -1
+	for _, pod := range pods {
+		ordinal, err := util.PodOrdinal(pod.Name)
+		if err != nil {
+			continue
+		}
+		n := leaders[encodeOrdinal(ordinal)]
+		if next == nil || n < nextLeaders || (n == nextLeaders && ordinal > nextOrdinal) {
+			next, nextLeaders, nextOrdinal = pod, n, ordinal
+		}
+	}
+	return next
+}
+
+// shardsLosingQuorum returns the IDs of log shards that will not have a quorum of replicas on known
+// log stores if the given log store goes down
+func shardsLosingQuorum(state pb.CheckerState, storeUUID string) []uint64 {
+	var lost []uint64
+	for _, record := range state.ClusterInfo.LogShards {
+		shard := state.LogState.Shards[record.ShardID]
+		var remaining uint64
+		for _, uuid := range shard.Replicas {
+			if _, ok := state.LogState.Stores[uuid]; ok && uuid != storeUUID {
+				remaining++
+			}
+		}
+		if remaining < record.NumberOfReplicas/2+1 {
+			lost = append(lost, record.ShardID)
+		}
+	}
+	return lost
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logset
+
+import (
+	"testing"
+
+	pb "github.com/matrixorigin/matrixone/pkg/pb/logservice"
+	. "github.com/onsi/gomega"
+	kruisev1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func Test_holdRollingUpdate(t *testing.T) {
+	g := NewGomegaWithT(t)
+	sts := &kruisev1.StatefulSet{Spec: kruisev1.StatefulSetSpec{
+		Replicas:        pointer.Int32(3),
+		ReserveOrdinals: []int{1},
+	}}
+	g.Expect(rollingUpdateInProgress(sts)).To(BeFalse())
+	holdRollingUpdate(sts)
+	g.Expect(*sts.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(3)))
+	g.Expect(sts.Spec.UpdateStrategy.RollingUpdate.UnorderedUpdate).ToNot(BeNil())
+	g.Expect(rollingUpdateInProgress(sts)).To(BeTrue())
+}
+
+func Test_nextPodToUpdate(t *testing.T) {
+	g := NewGomegaWithT(t)
+	// store a leads shard 0 and store c leads shard 1
+	state := testCheckerState()
+	state.LogState.Shards[0] = pb.LogShardInfo{ShardID: 0, LeaderID: 1, Replicas: map[uint64]string{
+		1: encodeOrdinal(0), 2: encodeOrdinal(1), 3: encodeOrdinal(2),
+	}}
+	state.LogState.Shards[1] = pb.LogShardInfo{ShardID: 1, LeaderID: 5, Replicas: map[uint64]string{
+		4: encodeOrdinal(1), 5: encodeOrdinal(2), 6: encodeOrdinal(3),
+	}}
+	pod := func(name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	g.Expect(nextPodToUpdate(state, nil)).To(BeNil())
+	// non-leaders go first, the highest ordinal wins a tie
+	g.Expect(nextPodToUpdate(state, []*corev1.Pod{pod("log-0"), pod("log-1"), pod("log-2"), pod("log-3")}).Name).To(Equal("log-3"))
+	g.Expect(nextPodToUpdate(state, []*corev1.Pod{pod("log-0"), pod("log-1"), pod("log-2")}).Name).To(Equal("log-1"))
+	g.Expect(nextPodToUpdate(state, []*corev1.Pod{pod("log-0"), pod("log-2")}).Name).To(Equal("log-2"))
+}
+
+func Test_shardsLosingQuorum(t *testing.T) {
+	g := NewGomegaWithT(t)
+	state := testCheckerState()
+	g.Expect(shardsLosingQuorum(state, "a")).To(BeEmpty())
+
+	// store d is gone, shard 1 only has replicas on b and c
+	delete(state.LogState.Stores, "d")
+	g.Expect(shardsLosingQuorum(state, "a")).To(BeEmpty())
+	g.Expect(shardsLosingQuorum(state, "b")).To(Equal([]uint64{1}))
+}
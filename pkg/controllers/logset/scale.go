@@ -0,0 +1,189 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logset
+
+import (
+	"fmt"
+
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/controller-runtime/pkg/util"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	kruisev1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Scale scale-out/in the log set pods to match the desired state
+func (r *WithResources) Scale(ctx *recon.Context[*v1alpha1.LogSet]) error {
+	ls := ctx.Obj
+	if r.HAKeeper != nil && ls.Spec.Replicas <= *r.sts.Spec.Replicas {
+		return r.scaleIn(ctx)
+	}
+	if r.HAKeeper != nil {
+		if err := checkStaleDataVolumes(ctx, r.sts, ls.Spec.Replicas); err != nil {
+			return err
+		}
+	}
+	ctx.Log.Info("scale logset")
+	err := ctx.Patch(r.sts, func() error {
+		syncReplicas(ls, r.sts)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if ls.Status.ScaleIn != nil {
+		// the scale-in is overridden by the latest spec
+		ls.Status.ScaleIn = nil
+		if err := ctx.UpdateStatus(ls); err != nil {
+			return errors.Wrap(err, "clear scale-in status")
+		}
+	}
+	// also update gossip config after scale
+	return updateGossipConfig(ctx, r.sts)
+}
+
+// scaleIn removes log stores one at a time from the highest ordinal. HAKeeper only re-homes the
+// replicas of a log store after the store is gone and its heartbeat expires, so a store is removed only
+// if all log shards are fully replicated and HAKeeper confirms that its replicas can be re-homed without
+// losing quorum, and the next one will not be removed until HAKeeper reports that no replica is left on
+// the removed store and all log shards are fully replicated again.
+// Replicas cannot be removed from a store before the statefulset is scaled in since HAKeeper exposes no command
+// to remove or move a log shard replica, so the log shards hosted by the removed store run one replica short
+// until HAKeeper re-homes their replicas, which is why only one store is removed at a time.
+func (r *WithResources) scaleIn(ctx *recon.Context[*v1alpha1.LogSet]) error {
+	ls := ctx.Obj
+	state, err := r.HAKeeper.GetClusterState(ls)
+	if err != nil {
+		return err
+	}
+
+	if ls.Status.ScaleIn == nil {
+		ls.Status.ScaleIn = &v1alpha1.LogSetScaleInStatus{StartTime: metav1.Now()}
+	}
+	status := ls.Status.ScaleIn
+	status.TargetReplicas = ls.Spec.Replicas
+	unhealthy := unhealthyShards(state)
+
+	if status.RemovingStoreUUID != "" {
+		status.PendingReplicas = storeReplicas(state.LogState, status.RemovingStoreUUID)
+		if len(status.PendingReplicas) > 0 || len(unhealthy) > 0 {
+			status.Message = fmt.Sprintf("waiting for HAKeeper to move replicas off store %s, %d replicas left, unhealthy log shards: %v",
+				status.RemovingStore, len(status.PendingReplicas), unhealthy)
+			return recon.ErrReSync(status.Message, reSyncAfter)
+		}
+		ctx.Log.Info("log store removed", "store", status.RemovingStore)
+		status.RemovingStore = ""
+		status.RemovingStoreUUID = ""
+		status.PendingReplicas = nil
+	}
+
+	if *r.sts.Spec.Replicas <= ls.Spec.Replicas {
+		ctx.Log.Info("logset scale-in completed", "replicas", ls.Spec.Replicas)
+		ls.Status.ScaleIn = nil
+		if err := ctx.UpdateStatus(ls); err != nil {
+			return errors.Wrap(err, "clear scale-in status")
+		}
+		return nil
+	}
+	if len(unhealthy) > 0 {
+		status.Message = fmt.Sprintf("log shards %v are not fully replicated, wait before removing log store", unhealthy)
+		return recon.ErrReSync(status.Message, reSyncAfter)
+	}
+	if len(ls.Status.AvailableStores) < int(*r.sts.Spec.Replicas) {
+		// e.g. the store re-created at a reclaimed ordinal, which must join before another store leaves
+		status.Message = "wait for all log stores to be available before removing log store"
+		return recon.ErrReSync(status.Message, reSyncAfter)
+	}
+	replicas := *r.sts.Spec.Replicas - 1
+	if required := maxShardReplicas(state.ClusterInfo); int(replicas) < required {
+		status.Message = fmt.Sprintf("cannot scale in to %d replicas since log shards require %d replicas", replicas, required)
+		return recon.ErrReSync(status.Message, reSyncAfter)
+	}
+
+	ordinal := highestOrdinal(*r.sts.Spec.Replicas, r.sts.Spec.ReserveOrdinals)
+	store := fmt.Sprintf("%s-%d", r.sts.Name, ordinal)
+	// the statefulset is scaled in only after HAKeeper confirms that the replicas on the store can be re-homed
+	pending, err := r.HAKeeper.CheckLogStoreRemoval(ls, encodeOrdinal(ordinal))
+	if err != nil {
+		status.Message = fmt.Sprintf("cannot remove log store %s: %v", store, err)
+		return recon.ErrReSync(status.Message, reSyncAfter)
+	}
+	status.RemovingStore = store
+	status.RemovingStoreUUID = encodeOrdinal(ordinal)
+	status.PendingReplicas = pending
+	status.Message = fmt.Sprintf("removing log store %s", status.RemovingStore)
+	// persist the removing store before scaling the statefulset so that we always wait for it
+	if err := ctx.UpdateStatus(ls); err != nil {
+		return errors.Wrap(err, "record scale-in status")
+	}
+	ctx.Log.Info("remove log store", "store", status.RemovingStore, "replicas", status.PendingReplicas)
+	err = ctx.Patch(r.sts, func() error {
+		r.sts.Spec.Replicas = &replicas
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "scale in logset statefulset")
+	}
+	if err := updateGossipConfig(ctx, r.sts); err != nil {
+		return err
+	}
+	return recon.ErrReSync(status.Message, reSyncAfter)
+}
+
+// highestOrdinal returns the highest pod ordinal of a statefulset that has the given replicas and reserved ordinals
+func highestOrdinal(replicas int32, reserved []int) int {
+	isReserved := map[int]bool{}
+	for _, o := range reserved {
+		isReserved[o] = true
+	}
+	ordinal := -1
+	for count := int32(0); count < replicas; {
+		ordinal++
+		if !isReserved[ordinal] {
+			count++
+		}
+	}
+	return ordinal
+}
+
+// checkStaleDataVolumes refuses to scale out the statefulset to the given replicas if a data volume of the new
+// ordinals is retained from a removed log store. The replicas of a removed log store have been re-homed by
+// HAKeeper, so a store started on the retained data would rejoin with replicas that HAKeeper no longer tracks.
+func checkStaleDataVolumes(ctx *recon.Context[*v1alpha1.LogSet], sts *kruisev1.StatefulSet, replicas int32) error {
+	for _, ordinal := range newOrdinals(*sts.Spec.Replicas, replicas, sts.Spec.ReserveOrdinals) {
+		name := dataPVCName(fmt.Sprintf("%s-%d", sts.Name, ordinal))
+		err, found := util.IsFound(ctx.Get(client.ObjectKey{Namespace: sts.Namespace, Name: name}, &corev1.PersistentVolumeClaim{}))
+		if err != nil {
+			return errors.Wrapf(err, "get data volume %s", name)
+		}
+		if found {
+			return recon.ErrReSync(fmt.Sprintf("data volume %s of a removed log store is stale, delete it to scale out", name), reSyncAfter)
+		}
+	}
+	return nil
+}
+
+// newOrdinals returns the pod ordinals that are added when a statefulset with the reserved ordinals is scaled
+// from the current replicas to the given replicas
+func newOrdinals(current, replicas int32, reserved []int) []int {
+	var ordinals []int
+	for r := current + 1; r <= replicas; r++ {
+		ordinals = append(ordinals, highestOrdinal(r, reserved))
+	}
+	return ordinals
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logset
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_highestOrdinal(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas int32
+		reserved []int
+		want     int
+	}{
+		{name: "no reserved", replicas: 3, want: 2},
+		{name: "reserved in range", replicas: 3, reserved: []int{1}, want: 3},
+		{name: "reserved out of range", replicas: 3, reserved: []int{5}, want: 2},
+		{name: "multiple reserved", replicas: 2, reserved: []int{0, 2}, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(highestOrdinal(tt.replicas, tt.reserved)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_newOrdinals(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  int32
+		replicas int32
+		reserved []int
+		want     []int
+	}{
+		{name: "scale in", current: 3, replicas: 2},
+		{name: "no reserved", current: 3, replicas: 5, want: []int{3, 4}},
+		{name: "reserved skipped", current: 2, replicas: 4, reserved: []int{3}, want: []int{2, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			g.Expect(newOrdinals(tt.current, tt.replicas, tt.reserved)).To(Equal(tt.want))
+		})
+	}
+}
@@ -88,6 +88,7 @@ func syncPodSpec(ls *v1alpha1.LogSet, specRef *corev1.PodSpec) {
 	//if ls.Spec.DNSBasedIdentity {
 	//	mainRef.Env = append(mainRef.Env, corev1.EnvVar{Name: "HOSTNAME_UUID", Value: "y"})
 	//}
+	common.SyncMOInitContainer(mainRef, moInitOptions(ls))
 	ls.Spec.Overlay.OverlayMainContainer(mainRef)
 
 	specRef.Containers = []corev1.Container{*mainRef}
@@ -102,6 +103,7 @@ func syncPodSpec(ls *v1alpha1.LogSet, specRef *corev1.PodSpec) {
 		Name:         gossipVolume,
 		VolumeSource: util.ConfigMapVolume(gossipConfigMapName(ls)),
 	}}
+	common.SyncMOInitPod(specRef)
 	specRef.ReadinessGates = []corev1.PodReadinessGate{{
 		ConditionType: pub.InPlaceUpdateReady,
 	}}
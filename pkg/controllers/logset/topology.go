@@ -0,0 +1,94 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logset
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	pb "github.com/matrixorigin/matrixone/pkg/pb/logservice"
+)
+
+const ordinalUUIDPrefix = "00000000-0000-0000-0000-"
+
+// syncShardTopology refreshes the log shard and DN shard topology in the status from the HAKeeper cluster state
+func syncShardTopology(ls *v1alpha1.LogSet, state pb.CheckerState) {
+	ls.Status.HAKeeperState = state.State.String()
+
+	expected := map[uint64]uint64{}
+	for _, record := range state.ClusterInfo.LogShards {
+		expected[record.ShardID] = record.NumberOfReplicas
+	}
+	var logShards []v1alpha1.LogShardStatus
+	for _, shard := range state.LogState.Shards {
+		s := v1alpha1.LogShardStatus{
+			ShardID:          int64(shard.ShardID),
+			NumberOfReplicas: int64(expected[shard.ShardID]),
+			LeaderReplicaID:  int64(shard.LeaderID),
+			Term:             int64(shard.Term),
+			Epoch:            int64(shard.Epoch),
+		}
+		for replicaID, uuid := range shard.Replicas {
+			r := v1alpha1.LogShardReplicaStatus{
+				ReplicaID: int64(replicaID),
+				StoreUUID: uuid,
+				PodName:   storePodName(ls, uuid),
+			}
+			if replicaID == shard.LeaderID {
+				s.LeaderStore = r.PodName
+			}
+			s.Replicas = append(s.Replicas, r)
+		}
+		sort.Slice(s.Replicas, func(i, j int) bool { return s.Replicas[i].ReplicaID < s.Replicas[j].ReplicaID })
+		logShards = append(logShards, s)
+	}
+	sort.Slice(logShards, func(i, j int) bool { return logShards[i].ShardID < logShards[j].ShardID })
+	ls.Status.LogShards = logShards
+
+	var dnShards []v1alpha1.DNShardStatus
+	for uuid, store := range state.DNState.Stores {
+		for _, shard := range store.Shards {
+			dnShards = append(dnShards, v1alpha1.DNShardStatus{
+				ShardID:        int64(shard.ShardID),
+				ReplicaID:      int64(shard.ReplicaID),
+				StoreUUID:      uuid,
+				ServiceAddress: store.ServiceAddress,
+			})
+		}
+	}
+	sort.Slice(dnShards, func(i, j int) bool {
+		if dnShards[i].ShardID != dnShards[j].ShardID {
+			return dnShards[i].ShardID < dnShards[j].ShardID
+		}
+		return dnShards[i].ReplicaID < dnShards[j].ReplicaID
+	})
+	ls.Status.DNShards = dnShards
+}
+
+// storePodName returns the name of the pod that runs the log store of the given UUID, empty if the UUID
+// is not encoded from a pod ordinal of the logset
+func storePodName(ls *v1alpha1.LogSet, uuid string) string {
+	if !strings.HasPrefix(uuid, ordinalUUIDPrefix) {
+		return ""
+	}
+	ordinal, err := strconv.ParseInt(strings.TrimPrefix(uuid, ordinalUUIDPrefix), 16, 64)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s-%d", stsName(ls), ordinal)
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logset
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	pb "github.com/matrixorigin/matrixone/pkg/pb/logservice"
+	"github.com/matrixorigin/matrixone/pkg/pb/metadata"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_syncShardTopology(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ls := &v1alpha1.LogSet{ObjectMeta: metav1.ObjectMeta{Name: "mo"}}
+	state := pb.CheckerState{
+		State:       pb.HAKeeperRunning,
+		ClusterInfo: pb.ClusterInfo{LogShards: []metadata.LogShardRecord{{ShardID: 1, NumberOfReplicas: 2}}},
+		LogState: pb.LogState{Shards: map[uint64]pb.LogShardInfo{
+			1: {ShardID: 1, LeaderID: 12, Term: 3, Epoch: 5, Replicas: map[uint64]string{12: encodeOrdinal(10), 11: "external"}},
+		}},
+		DNState: pb.DNState{Stores: map[string]pb.DNStoreInfo{
+			"dn-0": {ServiceAddress: "mo-dn-0:41010", Shards: []pb.DNShardInfo{{ShardID: 2, ReplicaID: 7}}},
+		}},
+	}
+	syncShardTopology(ls, state)
+	g.Expect(ls.Status.HAKeeperState).To(Equal("HAKeeperRunning"))
+	g.Expect(ls.Status.LogShards).To(Equal([]v1alpha1.LogShardStatus{{
+		ShardID:          1,
+		NumberOfReplicas: 2,
+		Replicas: []v1alpha1.LogShardReplicaStatus{
+			{ReplicaID: 11, StoreUUID: "external"},
+			{ReplicaID: 12, StoreUUID: encodeOrdinal(10), PodName: "mo-log-10"},
+		},
+		LeaderReplicaID: 12,
+		LeaderStore:     "mo-log-10",
+		Term:            3,
+		Epoch:           5,
+	}}))
+	g.Expect(ls.Status.DNShards).To(Equal([]v1alpha1.DNShardStatus{{
+		ShardID:        2,
+		ReplicaID:      7,
+		StoreUUID:      "dn-0",
+		ServiceAddress: "mo-dn-0:41010",
+	}}))
+}
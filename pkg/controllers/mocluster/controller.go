@@ -67,6 +67,15 @@ func (r *MatrixOneClusterActor) Observe(ctx *recon.Context[*v1alpha1.MatrixOneCl
 		if err != nil {
 			return nil, errors.Wrap(err, "error get backup")
 		}
+		if !backup.RestorableTo(mo.Namespace) {
+			mo.Status.SetCondition(metav1.Condition{
+				Type:    recon.ConditionTypeReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  "RestoreForbidden",
+				Message: fmt.Sprintf("Backup %s cannot be restored to namespace %s", backup.Name, mo.Namespace),
+			})
+			return nil, nil
+		}
 		restore := &v1alpha1.RestoreJob{
 			ObjectMeta: metav1.ObjectMeta{
 				Namespace: mo.Namespace,
@@ -91,6 +100,14 @@ func (r *MatrixOneClusterActor) Observe(ctx *recon.Context[*v1alpha1.MatrixOneCl
 				Message: "Restore job failed, recreate the cluster to retry",
 			})
 			return nil, nil
+		case v1alpha1.JobPhaseCancelled:
+			mo.Status.SetCondition(metav1.Condition{
+				Type:    recon.ConditionTypeReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  "RestoreCancelled",
+				Message: "Restore job is cancelled, recreate the cluster to retry",
+			})
+			return nil, nil
 		case v1alpha1.JobPhaseCompleted:
 			if mo.Annotations == nil {
 				mo.Annotations = map[string]string{}
@@ -172,7 +189,12 @@ func (r *MatrixOneClusterActor) Up(ctx *recon.Context[*v1alpha1.MatrixOneCluster
 				tpl.Labels = map[string]string{}
 			}
 			tpl.Labels[common.MatrixoneClusterLabelKey] = mo.Name
+			replicas := tpl.Spec.Replicas
 			tpl.Spec = g.CNSetSpec
+			if tpl.Spec.Autoscaling != nil && !tpl.CreationTimestamp.IsZero() {
+				// replicas is managed by the autoscaler of the CNSet once created
+				tpl.Spec.Replicas = replicas
+			}
 			if mo.Spec.Proxy != nil {
 				if tpl.Spec.Config == nil {
 					tpl.Spec.Config = v1alpha1.NewTomlConfig(map[string]interface{}{})
@@ -235,6 +257,8 @@ func (r *MatrixOneClusterActor) Up(ctx *recon.Context[*v1alpha1.MatrixOneCluster
 			Host:   fmt.Sprintf("%s.%s", cnSet.Name+"-cn", mo.Namespace),
 			Ready:  recon.IsReady(&cnSet),
 			Synced: recon.IsSynced(&cnSet),
+
+			Hibernated: cnSet.Status.IsHibernated(),
 		}
 		if cngs.Ready {
 			groupStatus.ReadyGroups++
@@ -290,6 +314,8 @@ func (r *MatrixOneClusterActor) Up(ctx *recon.Context[*v1alpha1.MatrixOneCluster
 	mo.Status.ConditionalStatus.SetCondition(subResourcesReady)
 	if subResourcesReady.Status == metav1.ConditionTrue {
 		mo.Status.Phase = "Ready"
+	} else if subResourcesReady.Reason == reasonCNSetsHibernated {
+		mo.Status.Phase = "Hibernated"
 	}
 	if !mo.Status.ClusterMetrics.Initialized && firstCN != nil {
 		if err := r.initializeMetricUser(ctx, firstCN.Status.Host); err != nil {
@@ -422,6 +448,9 @@ func (r *MatrixOneClusterActor) InitMetricCredential(ctx *recon.Context[*v1alpha
 	return metricSec, ctx.UpdateStatus(ctx.Obj)
 }
 
+// reasonCNSetsHibernated means the cluster is not ready only because some CNSets are scaled to zero by their idle policy
+const reasonCNSetsHibernated = "CNSetsHibernated"
+
 func readyCondition(mo *v1alpha1.MatrixOneCluster) metav1.Condition {
 	c := metav1.Condition{Type: recon.ConditionTypeReady}
 	switch {
@@ -431,12 +460,15 @@ func readyCondition(mo *v1alpha1.MatrixOneCluster) metav1.Condition {
 	case !recon.IsReady(mo.Status.DN):
 		c.Status = metav1.ConditionFalse
 		c.Reason = "DNSetNotReady"
-	case !mo.Status.CNGroupStatus.Ready():
+	case !mo.Status.CNGroupStatus.Ready() && !mo.Status.CNGroupStatus.Hibernated():
 		c.Status = metav1.ConditionFalse
 		c.Reason = "SomeCNSetsAreNotReady"
 	case mo.Spec.Proxy != nil && !recon.IsReady(mo.Status.Proxy):
 		c.Status = metav1.ConditionFalse
 		c.Reason = "ProxySetNotReady"
+	case mo.Status.CNGroupStatus.Hibernated():
+		c.Status = metav1.ConditionFalse
+		c.Reason = reasonCNSetsHibernated
 	default:
 		c.Status = metav1.ConditionTrue
 		c.Reason = "AllSetsReady"
@@ -331,3 +331,44 @@ func newScheme() *runtime.Scheme {
 	utilruntime.Must(kruisepolicy.AddToScheme(scheme))
 	return scheme
 }
+
+func Test_readyConditionHibernated(t *testing.T) {
+	ready := &v1alpha1.LogSetStatus{}
+	ready.SetCondition(metav1.Condition{Type: recon.ConditionTypeReady, Status: metav1.ConditionTrue})
+	dn := &v1alpha1.DNSetStatus{}
+	dn.SetCondition(metav1.Condition{Type: recon.ConditionTypeReady, Status: metav1.ConditionTrue})
+	tests := []struct {
+		name   string
+		groups v1alpha1.CNGroupsStatus
+		want   string
+	}{{
+		name: "all ready",
+		groups: v1alpha1.CNGroupsStatus{DesiredGroups: 2, ReadyGroups: 2, Groups: []v1alpha1.CNGroupStatus{
+			{Name: "a", Ready: true}, {Name: "b", Ready: true},
+		}},
+		want: "AllSetsReady",
+	}, {
+		name: "some hibernated",
+		groups: v1alpha1.CNGroupsStatus{DesiredGroups: 2, ReadyGroups: 1, Groups: []v1alpha1.CNGroupStatus{
+			{Name: "a", Ready: true}, {Name: "b", Hibernated: true},
+		}},
+		want: reasonCNSetsHibernated,
+	}, {
+		name: "hibernated and not ready",
+		groups: v1alpha1.CNGroupsStatus{DesiredGroups: 2, ReadyGroups: 0, Groups: []v1alpha1.CNGroupStatus{
+			{Name: "a"}, {Name: "b", Hibernated: true},
+		}},
+		want: "SomeCNSetsAreNotReady",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			mo := &v1alpha1.MatrixOneCluster{Status: v1alpha1.MatrixOneClusterStatus{
+				LogService:    ready,
+				DN:            dn,
+				CNGroupStatus: tt.groups,
+			}}
+			g.Expect(readyCondition(mo).Reason).To(Equal(tt.want))
+		})
+	}
+}
@@ -33,15 +33,22 @@ const (
 	ProxyPort = 6001
 )
 
-type Actor struct{}
+type Actor struct {
+	// PluginBackend is the address of the proxy plugin served by the operator, ProxySets cannot wake up
+	// CNSets if it is empty
+	PluginBackend string
+}
 
 var _ recon.Actor[*v1alpha1.ProxySet] = &Actor{}
 
 func (r *Actor) Observe(ctx *recon.Context[*v1alpha1.ProxySet]) (recon.Action[*v1alpha1.ProxySet], error) {
 	p := ctx.Obj
+	if p.Spec.WakeUpCNSets && r.PluginBackend == "" {
+		ctx.Log.Info("proxy plugin is not enabled in the operator, hibernated cnsets will not be waken up on connection")
+	}
 	cloneset := buildCloneSet(p)
 	err := recon.CreateOwnedOrUpdate(ctx, cloneset, func() error {
-		return syncCloneSet(ctx, p, cloneset)
+		return syncCloneSet(ctx, p, cloneset, r.PluginBackend)
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "sync cloneset")
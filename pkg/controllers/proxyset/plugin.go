@@ -0,0 +1,225 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxyset
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/go-logr/logr"
+	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	"github.com/matrixorigin/matrixone/pkg/common/morpc"
+	"github.com/matrixorigin/matrixone/pkg/pb/plugin"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slices"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	// wakeUpHoldTimeout is how long a connection is held to wait for the hibernated CNSets to be ready,
+	// the connection is routed by the proxy as usual after that
+	wakeUpHoldTimeout  = 30 * time.Second
+	wakeUpPollInterval = time.Second
+	// pluginTimeout is the timeout of proxy plugin requests configured in proxies, which must cover the hold
+	pluginTimeout = wakeUpHoldTimeout + 5*time.Second
+
+	podIPField = "status.podIP"
+)
+
+// SetupPodIPIndexer indexes pods by IP so that the proxy plugin can find the ProxySet of a proxy
+func SetupPodIPIndexer(ctx context.Context, mgr manager.Manager) error {
+	return mgr.GetFieldIndexer().IndexField(ctx, &corev1.Pod{}, podIPField, func(obj client.Object) []string {
+		pod := obj.(*corev1.Pod)
+		if pod.Status.PodIP == "" {
+			return nil
+		}
+		return []string{pod.Status.PodIP}
+	})
+}
+
+// WakeUpPlugin is the proxy plugin that wakes up the hibernated CNSets behind the ProxySets that have
+// wakeUpCNSets enabled. The connection is held until the CNSets are ready, and then it is always routed
+// by the proxy itself.
+type WakeUpPlugin struct {
+	ListenAddress string
+	Client        client.Client
+	Logger        logr.Logger
+}
+
+var _ manager.LeaderElectionRunnable = &WakeUpPlugin{}
+
+// NeedLeaderElection returns false since proxies may connect to any replica of the operator
+func (p *WakeUpPlugin) NeedLeaderElection() bool {
+	return false
+}
+
+func (p *WakeUpPlugin) Start(ctx context.Context) error {
+	codec := morpc.NewMessageCodec(func() morpc.Message { return &plugin.Request{} })
+	s, err := morpc.NewRPCServer("proxy-plugin", p.ListenAddress, codec)
+	if err != nil {
+		return errors.Wrap(err, "build proxy plugin server")
+	}
+	s.RegisterRequestHandler(func(_ context.Context, msg morpc.RPCMessage, _ uint64, cs morpc.ClientSession) error {
+		req, ok := msg.Message.(*plugin.Request)
+		if !ok {
+			return errors.Errorf("unexpected proxy plugin request: %s", msg.Message.DebugString())
+		}
+		// requests are handled in the read loop of the proxy connection, hold them in another goroutine
+		go func() {
+			resp := &plugin.Response{
+				RequestID:      req.RequestID,
+				Recommendation: p.recommend(ctx, cs.RemoteAddress(), req.ClientInfo),
+			}
+			writeCtx, cancel := context.WithTimeout(ctx, wakeUpPollInterval)
+			defer cancel()
+			if err := cs.Write(writeCtx, resp); err != nil {
+				p.Logger.Error(err, "failed to respond to proxy", "proxy", cs.RemoteAddress())
+			}
+		}()
+		return nil
+	})
+	if err := s.Start(); err != nil {
+		return errors.Wrap(err, "start proxy plugin server")
+	}
+	<-ctx.Done()
+	return s.Close()
+}
+
+// recommend wakes up the hibernated CNSets that may serve the connection and waits for them to be ready
+func (p *WakeUpPlugin) recommend(ctx context.Context, remoteAddress string, info *plugin.ClientInfo) *plugin.Recommendation {
+	bypass := &plugin.Recommendation{Action: plugin.Bypass}
+	proxy, err := p.proxyOf(ctx, remoteAddress)
+	if err != nil {
+		p.Logger.Error(err, "failed to find the ProxySet of proxy", "proxy", remoteAddress)
+		return bypass
+	}
+	if proxy == nil || !proxy.Spec.WakeUpCNSets || proxy.Deps.LogSet == nil {
+		return bypass
+	}
+	cnList := &v1alpha1.CNSetList{}
+	if err := p.Client.List(ctx, cnList, client.InNamespace(proxy.Namespace)); err != nil {
+		p.Logger.Error(err, "failed to list CNSets", "proxyset", client.ObjectKeyFromObject(proxy))
+		return bypass
+	}
+	var waking []types.NamespacedName
+	for i := range cnList.Items {
+		cn := &cnList.Items[i]
+		if cn.Deps.LogSet == nil || client.ObjectKeyFromObject(cn.Deps.LogSet) != client.ObjectKeyFromObject(proxy.Deps.LogSet) {
+			continue
+		}
+		if !cn.Status.IsHibernated() || !cnSetServes(cn, info.GetLabelSelector()) {
+			continue
+		}
+		if err := p.wakeUp(ctx, cn); err != nil {
+			p.Logger.Error(err, "failed to wake up CNSet", "cnset", client.ObjectKeyFromObject(cn))
+			continue
+		}
+		waking = append(waking, client.ObjectKeyFromObject(cn))
+	}
+	if len(waking) == 0 {
+		return bypass
+	}
+	p.Logger.Info("hold connection to wake up CNSets", "proxyset", client.ObjectKeyFromObject(proxy), "cnsets", waking)
+	holdCtx, cancel := context.WithTimeout(ctx, wakeUpHoldTimeout)
+	defer cancel()
+	ticker := time.NewTicker(wakeUpPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-holdCtx.Done():
+			return bypass
+		case <-ticker.C:
+			if p.anyReady(holdCtx, waking) {
+				return bypass
+			}
+		}
+	}
+}
+
+// proxyOf returns the ProxySet of the proxy with the given address, nil if the address is not a proxy
+func (p *WakeUpPlugin) proxyOf(ctx context.Context, remoteAddress string) (*v1alpha1.ProxySet, error) {
+	ip, _, err := net.SplitHostPort(remoteAddress)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse proxy address %s", remoteAddress)
+	}
+	podList := &corev1.PodList{}
+	if err := p.Client.List(ctx, podList, client.MatchingFields{podIPField: ip}); err != nil {
+		return nil, errors.Wrap(err, "list pods by IP")
+	}
+	for _, pod := range podList.Items {
+		name, ok := pod.Labels[common.InstanceLabelKey]
+		if !ok {
+			continue
+		}
+		proxy := &v1alpha1.ProxySet{}
+		err := p.Client.Get(ctx, types.NamespacedName{Namespace: pod.Namespace, Name: name}, proxy)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "get proxyset")
+		}
+		return proxy, nil
+	}
+	return nil, nil
+}
+
+func (p *WakeUpPlugin) wakeUp(ctx context.Context, cn *v1alpha1.CNSet) error {
+	if _, ok := cn.Annotations[v1alpha1.CNSetWakeUpAnnoKey]; ok {
+		return nil
+	}
+	origin := cn.DeepCopy()
+	if cn.Annotations == nil {
+		cn.Annotations = map[string]string{}
+	}
+	cn.Annotations[v1alpha1.CNSetWakeUpAnnoKey] = "proxy"
+	return p.Client.Patch(ctx, cn, client.MergeFrom(origin))
+}
+
+// anyReady returns whether any of the CNSets is waken up and ready to serve the connection
+func (p *WakeUpPlugin) anyReady(ctx context.Context, keys []types.NamespacedName) bool {
+	for _, key := range keys {
+		cn := &v1alpha1.CNSet{}
+		if err := p.Client.Get(ctx, key, cn); err != nil {
+			continue
+		}
+		if !cn.Status.IsHibernated() && recon.IsReady(cn) {
+			return true
+		}
+	}
+	return false
+}
+
+// cnSetServes returns whether the proxy may route a connection with the label selector to the CNSet. A CNSet
+// without labels serves any connection, otherwise every label in the selector must match the CNSet labels.
+func cnSetServes(cn *v1alpha1.CNSet, selector map[string]string) bool {
+	if len(cn.Spec.Labels) == 0 {
+		return true
+	}
+	for k, v := range selector {
+		i := slices.IndexFunc(cn.Spec.Labels, func(l v1alpha1.CNLabel) bool { return l.Key == k })
+		if i < 0 || !slices.Contains(cn.Spec.Labels[i].Values, v) {
+			return false
+		}
+	}
+	return true
+}
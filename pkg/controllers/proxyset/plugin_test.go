@@ -0,0 +1,78 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxyset
+
+import (
+	"testing"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_cnSetServes(t *testing.T) {
+	tests := []struct {
+		name     string
+		labels   []v1alpha1.CNLabel
+		selector map[string]string
+		want     bool
+	}{
+		{name: "no labels", selector: map[string]string{"account": "t1"}, want: true},
+		{name: "no selector", labels: []v1alpha1.CNLabel{{Key: "account", Values: []string{"t1"}}}, want: true},
+		{
+			name:     "matched",
+			labels:   []v1alpha1.CNLabel{{Key: "account", Values: []string{"t1", "t2"}}, {Key: "role", Values: []string{"ap"}}},
+			selector: map[string]string{"account": "t2"},
+			want:     true,
+		},
+		{
+			name:     "value mismatched",
+			labels:   []v1alpha1.CNLabel{{Key: "account", Values: []string{"t1"}}},
+			selector: map[string]string{"account": "t2"},
+		},
+		{
+			name:     "key missing",
+			labels:   []v1alpha1.CNLabel{{Key: "account", Values: []string{"t1"}}},
+			selector: map[string]string{"account": "t1", "role": "ap"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			cn := &v1alpha1.CNSet{Spec: v1alpha1.CNSetSpec{Labels: tt.labels}}
+			g.Expect(cnSetServes(cn, tt.selector)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_buildProxyConfigMap(t *testing.T) {
+	g := NewGomegaWithT(t)
+	ls := &v1alpha1.LogSet{Status: v1alpha1.LogSetStatus{Discovery: &v1alpha1.LogSetDiscovery{Address: "log", Port: 32001}}}
+	proxy := &v1alpha1.ProxySet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "proxy"}}
+	cm, err := buildProxyConfigMap(proxy, ls, "proxy-plugin.mo-system.svc:6065")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cm.Data[common.ConfigFile]).ToNot(ContainSubstring("plugin"))
+
+	proxy.Spec.WakeUpCNSets = true
+	cm, err = buildProxyConfigMap(proxy, ls, "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cm.Data[common.ConfigFile]).ToNot(ContainSubstring("plugin"))
+
+	cm, err = buildProxyConfigMap(proxy, ls, "proxy-plugin.mo-system.svc:6065")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(cm.Data[common.ConfigFile]).To(ContainSubstring(`backend = "proxy-plugin.mo-system.svc:6065"`))
+	g.Expect(cm.Data[common.ConfigFile]).To(ContainSubstring(`timeout = "35s"`))
+}
@@ -20,6 +20,7 @@ import (
 	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
 	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
 	"github.com/matrixorigin/matrixone-operator/pkg/controllers/common"
+	"github.com/matrixorigin/matrixone-operator/pkg/moinit"
 	kruisev1alpha1 "github.com/openkruise/kruise-api/apps/v1alpha1"
 	"github.com/pkg/errors"
 	"golang.org/x/exp/slices"
@@ -62,12 +63,21 @@ echo "/mo-service -cfg ${conf} $@"
 exec /mo-service -cfg ${conf} $@
 `))
 
+// moInitOptions builds the per-pod config by mo-init the same as startScriptTpl
+func moInitOptions() *moinit.Options {
+	return &moinit.Options{
+		ConfigFiles: []string{fmt.Sprintf("%s/%s", common.ConfigPath, common.ConfigFile)},
+		UUID:        moinit.UUIDAddress,
+		Sets:        []moinit.Set{{Key: "proxy.uuid", Value: moinit.VarUUID}},
+	}
+}
+
 func buildCloneSet(proxy *v1alpha1.ProxySet) *kruisev1alpha1.CloneSet {
 	return common.CloneSetTemplate(proxy, resourceName(proxy))
 }
 
-func syncCloneSet(ctx *recon.Context[*v1alpha1.ProxySet], proxy *v1alpha1.ProxySet, cs *kruisev1alpha1.CloneSet) error {
-	cm, err := buildProxyConfigMap(proxy, ctx.Dep.Deps.LogSet)
+func syncCloneSet(ctx *recon.Context[*v1alpha1.ProxySet], proxy *v1alpha1.ProxySet, cs *kruisev1alpha1.CloneSet, pluginBackend string) error {
+	cm, err := buildProxyConfigMap(proxy, ctx.Dep.Deps.LogSet, pluginBackend)
 	if err != nil {
 		return errors.Wrap(err, "build configmap")
 	}
@@ -79,6 +89,7 @@ func syncCloneSet(ctx *recon.Context[*v1alpha1.ProxySet], proxy *v1alpha1.ProxyS
 		KubeCli:         ctx,
 		StorageProvider: &ctx.Dep.Deps.LogSet.Spec.SharedStorage,
 		MutateContainer: syncMainContainer,
+		MOInit:          moInitOptions(),
 	})
 }
 
@@ -128,7 +139,9 @@ func syncSvc(proxy *v1alpha1.ProxySet, svc *corev1.Service) {
 	}
 }
 
-func buildProxyConfigMap(proxy *v1alpha1.ProxySet, ls *v1alpha1.LogSet) (*corev1.ConfigMap, error) {
+// buildProxyConfigMap builds the config of the proxy, the proxy plugin of the operator is configured if the ProxySet
+// wakes up CNSets and the plugin backend is given
+func buildProxyConfigMap(proxy *v1alpha1.ProxySet, ls *v1alpha1.LogSet, pluginBackend string) (*corev1.ConfigMap, error) {
 	if ls.Status.Discovery == nil {
 		return nil, errors.New("HAKeeper discovery address not ready")
 	}
@@ -140,6 +153,10 @@ func buildProxyConfigMap(proxy *v1alpha1.ProxySet, ls *v1alpha1.LogSet) (*corev1
 	conf.Merge(common.FileServiceConfig(fmt.Sprintf("%s/%s", common.DataPath, common.DataDir), ls.Spec.SharedStorage, nil))
 	conf.Set([]string{"service-type"}, "PROXY")
 	conf.Set([]string{"proxy", "listen-address"}, fmt.Sprintf("0.0.0.0:%d", port))
+	if proxy.Spec.WakeUpCNSets && pluginBackend != "" {
+		conf.Set([]string{"proxy", "plugin", "backend"}, pluginBackend)
+		conf.Set([]string{"proxy", "plugin", "timeout"}, pluginTimeout.String())
+	}
 	s, err := conf.ToString()
 	if err != nil {
 		return nil, err
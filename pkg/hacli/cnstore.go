@@ -0,0 +1,49 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hacli
+
+import (
+	"context"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/matrixorigin/matrixone/pkg/logservice"
+	"github.com/pkg/errors"
+)
+
+// CNQueryAddresses returns the query service addresses that the CN stores registered in HAKeeper, keyed by store UUID
+func CNQueryAddresses(ctx context.Context, cli logservice.ProxyHAKeeperClient) (map[string]string, error) {
+	details, err := cli.GetClusterDetails(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get cluster details from HAKeeper")
+	}
+	addrs := map[string]string{}
+	for _, store := range details.CNStores {
+		if store.QueryAddress != "" {
+			addrs[store.UUID] = store.QueryAddress
+		}
+	}
+	return addrs, nil
+}
+
+// CNQueryAddresses returns the query service addresses that the CN stores of the logset registered in HAKeeper
+func (m *HAKeeperClientManager) CNQueryAddresses(ls *v1alpha1.LogSet) (map[string]string, error) {
+	cli, err := m.GetClient(ls)
+	if err != nil {
+		return nil, errors.Wrap(err, "get HAKeeper client")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), HAKeeperTimeout)
+	defer cancel()
+	return CNQueryAddresses(ctx, cli)
+}
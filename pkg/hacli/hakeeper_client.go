@@ -20,6 +20,7 @@ import (
 	recon "github.com/matrixorigin/controller-runtime/pkg/reconciler"
 	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
 	"github.com/matrixorigin/matrixone/pkg/logservice"
+	pb "github.com/matrixorigin/matrixone/pkg/pb/logservice"
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -126,3 +127,18 @@ func (m *HAKeeperClientManager) newHAKeeperClient(ls *v1alpha1.LogSet) (logservi
 	}
 	return cli, nil
 }
+
+// GetClusterState returns the cluster state that the HAKeeper of the logset checks against
+func (m *HAKeeperClientManager) GetClusterState(ls *v1alpha1.LogSet) (pb.CheckerState, error) {
+	cli, err := m.GetClient(ls)
+	if err != nil {
+		return pb.CheckerState{}, errors.Wrap(err, "get HAKeeper client")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), HAKeeperTimeout)
+	defer cancel()
+	state, err := cli.GetClusterState(ctx)
+	if err != nil {
+		return pb.CheckerState{}, errors.Wrap(err, "get cluster state from HAKeeper")
+	}
+	return state, nil
+}
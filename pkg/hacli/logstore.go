@@ -0,0 +1,70 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hacli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	pb "github.com/matrixorigin/matrixone/pkg/pb/logservice"
+	"github.com/pkg/errors"
+)
+
+// CheckLogStoreRemoval checks through HAKeeper whether a log store can leave the logset and returns the log shard
+// replicas ("shardID:replicaID") that HAKeeper still places on the log store.
+// The HAKeeper of current MO version moves the replicas of a log store to other log stores only after the heartbeat
+// of the store expires, so a log store can leave only if every log shard it hosts keeps a quorum of replicas on
+// other log stores and there is another log store for HAKeeper to re-home the replica.
+func (m *HAKeeperClientManager) CheckLogStoreRemoval(ls *v1alpha1.LogSet, storeUUID string) ([]string, error) {
+	state, err := m.GetClusterState(ls)
+	if err != nil {
+		return nil, err
+	}
+	return checkLogStoreRemoval(state, storeUUID)
+}
+
+func checkLogStoreRemoval(state pb.CheckerState, storeUUID string) ([]string, error) {
+	var replicas []string
+	for _, record := range state.ClusterInfo.LogShards {
+		shard := state.LogState.Shards[record.ShardID]
+		hosted := false
+		var remaining uint64
+		for replicaID, uuid := range shard.Replicas {
+			if uuid == storeUUID {
+				hosted = true
+				replicas = append(replicas, fmt.Sprintf("%d:%d", record.ShardID, replicaID))
+			} else if _, ok := state.LogState.Stores[uuid]; ok {
+				remaining++
+			}
+		}
+		if !hosted {
+			continue
+		}
+		if remaining < record.NumberOfReplicas/2+1 {
+			return nil, errors.Errorf("log shard %d would lose quorum without log store %s", record.ShardID, storeUUID)
+		}
+		// stores that host neither the leaving replica nor the other replicas of the shard
+		spare := len(state.LogState.Stores) - int(remaining)
+		if _, ok := state.LogState.Stores[storeUUID]; ok {
+			spare--
+		}
+		if remaining < record.NumberOfReplicas && spare < 1 {
+			return nil, errors.Errorf("no log store is available to re-home the replica of log shard %d on log store %s", record.ShardID, storeUUID)
+		}
+	}
+	sort.Strings(replicas)
+	return replicas, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hacli
+
+import (
+	"testing"
+
+	pb "github.com/matrixorigin/matrixone/pkg/pb/logservice"
+	"github.com/matrixorigin/matrixone/pkg/pb/metadata"
+	. "github.com/onsi/gomega"
+)
+
+func Test_checkLogStoreRemoval(t *testing.T) {
+	g := NewGomegaWithT(t)
+	state := pb.CheckerState{
+		ClusterInfo: pb.ClusterInfo{LogShards: []metadata.LogShardRecord{
+			{ShardID: 0, NumberOfReplicas: 3},
+			{ShardID: 1, NumberOfReplicas: 1},
+		}},
+		LogState: pb.LogState{
+			Shards: map[uint64]pb.LogShardInfo{
+				0: {ShardID: 0, LeaderID: 1, Replicas: map[uint64]string{1: "a", 2: "b", 3: "c"}},
+				1: {ShardID: 1, LeaderID: 4, Replicas: map[uint64]string{4: "a"}},
+			},
+			Stores: map[string]pb.LogStoreInfo{"a": {}, "b": {}, "c": {}, "d": {}},
+		},
+	}
+	replicas, err := checkLogStoreRemoval(state, "d")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(replicas).To(BeEmpty())
+
+	replicas, err = checkLogStoreRemoval(state, "b")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(replicas).To(Equal([]string{"0:2"}))
+
+	// the only replica of shard 1 is on store a
+	_, err = checkLogStoreRemoval(state, "a")
+	g.Expect(err).To(HaveOccurred())
+
+	// no store is left to re-home the replica of shard 0
+	delete(state.LogState.Stores, "d")
+	_, err = checkLogStoreRemoval(state, "b")
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,42 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package moinit
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WaitDNS waits for the host to be resolvable. There is a chance that the DNS record of a pod is not yet
+// added when the pod starts, and the MO service crashes if its own address cannot be resolved.
+func WaitDNS(ctx context.Context, host string, timeout, period time.Duration, lookup func(ctx context.Context, host string) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		err := lookup(ctx, host)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(err, "wait for DNS name %s resolvable timeout after %s", host, timeout)
+		case <-ticker.C:
+		}
+	}
+}
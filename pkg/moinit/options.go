@@ -0,0 +1,127 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package moinit renders the per-pod config of MO services from the config files mounted from ConfigMaps
+// and the pod meta injected by the downward API, which is the job of mo-init.
+package moinit
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// UUIDScheme decides how the UUID of a MO service is derived
+type UUIDScheme string
+
+const (
+	// UUIDLogStoreOrdinal encodes the pod ordinal to the UUID of a log store
+	UUIDLogStoreOrdinal UUIDScheme = "log-ordinal"
+	// UUIDDNStoreOrdinal encodes the pod ordinal to the UUID of a DN store
+	UUIDDNStoreOrdinal UUIDScheme = "dn-ordinal"
+	// UUIDAddress derives the UUID from the DNS address of the pod
+	UUIDAddress UUIDScheme = "address"
+)
+
+const (
+	// VarAddr is substituted by the DNS address of the pod
+	VarAddr = "${ADDR}"
+	// VarUUID is substituted by the UUID of the MO service
+	VarUUID = "${UUID}"
+	// VarPodIP is substituted by the IP of the pod
+	VarPodIP = "${POD_IP}"
+
+	defaultDNSTimeout = 30 * time.Second
+	defaultDNSPeriod  = time.Second
+)
+
+// Options is the pod independent input of mo-init, which is built by the operator
+type Options struct {
+	// ConfigFiles are deep merged in order to build the base config
+	ConfigFiles []string
+	// Sets are the per-pod config items, applied after the config files are merged
+	Sets []Set
+	// UUID is the scheme to derive the UUID of the MO service
+	UUID UUIDScheme
+
+	// WaitDNS makes mo-init wait for the DNS address of the pod to be resolvable before starting the MO service
+	WaitDNS    bool
+	DNSTimeout time.Duration
+	DNSPeriod  time.Duration
+}
+
+// Set is a config item, the value can refer to VarAddr, VarUUID and VarPodIP
+type Set struct {
+	// Key is the dot separated path of the config item
+	Key   string
+	Value string
+}
+
+func (s Set) String() string {
+	return s.Key + "=" + s.Value
+}
+
+// Args encodes the options to the command line arguments of mo-init
+func (o *Options) Args() []string {
+	var args []string
+	for _, f := range o.ConfigFiles {
+		args = append(args, "-config", f)
+	}
+	for _, s := range o.Sets {
+		args = append(args, "-set", s.String())
+	}
+	if o.UUID != "" {
+		args = append(args, "-uuid", string(o.UUID))
+	}
+	if o.WaitDNS {
+		args = append(args, "-wait-dns")
+		if o.DNSTimeout != 0 {
+			args = append(args, "-dns-timeout", o.DNSTimeout.String())
+		}
+		if o.DNSPeriod != 0 {
+			args = append(args, "-dns-period", o.DNSPeriod.String())
+		}
+	}
+	return args
+}
+
+// BindFlags binds the options to the given flag set, which is the reverse of Args
+func (o *Options) BindFlags(fs *flag.FlagSet) {
+	fs.Func("config", "config file to merge, can be repeated", func(s string) error {
+		o.ConfigFiles = append(o.ConfigFiles, s)
+		return nil
+	})
+	fs.Func("set", "per-pod config item in the form of key.path=value, can be repeated", func(s string) error {
+		key, value, ok := strings.Cut(s, "=")
+		if !ok || key == "" {
+			return errors.Errorf("invalid config item %s", s)
+		}
+		o.Sets = append(o.Sets, Set{Key: key, Value: value})
+		return nil
+	})
+	fs.Func("uuid", fmt.Sprintf("UUID scheme of the service, one of %s, %s and %s", UUIDLogStoreOrdinal, UUIDDNStoreOrdinal, UUIDAddress), func(s string) error {
+		switch scheme := UUIDScheme(s); scheme {
+		case UUIDLogStoreOrdinal, UUIDDNStoreOrdinal, UUIDAddress:
+			o.UUID = scheme
+			return nil
+		}
+		return errors.Errorf("unknown UUID scheme %s", s)
+	})
+	fs.BoolVar(&o.WaitDNS, "wait-dns", false, "wait for the DNS address of the pod to be resolvable")
+	fs.DurationVar(&o.DNSTimeout, "dns-timeout", defaultDNSTimeout, "timeout of waiting for DNS")
+	fs.DurationVar(&o.DNSPeriod, "dns-period", defaultDNSPeriod, "period of resolving DNS")
+}
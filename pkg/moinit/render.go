@@ -0,0 +1,128 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package moinit
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/matrixorigin/matrixone-operator/api/core/v1alpha1"
+	"github.com/pkg/errors"
+)
+
+const (
+	envPodName      = "POD_NAME"
+	envHostname     = "HOSTNAME"
+	envNamespace    = "NAMESPACE"
+	envHeadlessSvc  = "HEADLESS_SERVICE_NAME"
+	envPodIP        = "POD_IP"
+	envHostnameUUID = "HOSTNAME_UUID"
+)
+
+// Env is the pod meta injected by the downward API
+type Env struct {
+	PodName         string
+	Namespace       string
+	HeadlessService string
+	PodIP           string
+	// HostnameUUID forces the UUID to be derived from the pod address
+	HostnameUUID bool
+}
+
+// EnvFromOS reads the pod meta from the environment variables that the start scripts rely on
+func EnvFromOS() Env {
+	podName := os.Getenv(envPodName)
+	if podName == "" {
+		podName = os.Getenv(envHostname)
+	}
+	_, hostnameUUID := os.LookupEnv(envHostnameUUID)
+	return Env{
+		PodName:         podName,
+		Namespace:       os.Getenv(envNamespace),
+		HeadlessService: os.Getenv(envHeadlessSvc),
+		PodIP:           os.Getenv(envPodIP),
+		HostnameUUID:    hostnameUUID,
+	}
+}
+
+// Addr returns the DNS address of the pod
+func (e Env) Addr() string {
+	if e.HeadlessService == "" {
+		return fmt.Sprintf("%s.%s.svc", e.PodName, e.Namespace)
+	}
+	return fmt.Sprintf("%s.%s.%s.svc", e.PodName, e.HeadlessService, e.Namespace)
+}
+
+// UUID derives the UUID of the MO service in the pod
+func (e Env) UUID(scheme UUIDScheme) (string, error) {
+	if e.HostnameUUID || scheme == UUIDAddress {
+		return v1alpha1.AddressUUID(e.Addr()), nil
+	}
+	idx := strings.LastIndex(e.PodName, "-")
+	ordinal, err := strconv.Atoi(e.PodName[idx+1:])
+	if err != nil {
+		return "", errors.Wrapf(err, "parse ordinal of pod %s", e.PodName)
+	}
+	switch scheme {
+	case UUIDLogStoreOrdinal:
+		return v1alpha1.LogStoreUUID(ordinal), nil
+	case UUIDDNStoreOrdinal:
+		return v1alpha1.DNStoreUUID(ordinal), nil
+	}
+	return "", errors.Errorf("unknown UUID scheme %s", scheme)
+}
+
+// Render builds the final config of the MO service in the pod
+func Render(o *Options, env Env, readFile func(string) ([]byte, error)) (*v1alpha1.TomlConfig, error) {
+	conf := v1alpha1.NewTomlConfig(map[string]interface{}{})
+	for _, f := range o.ConfigFiles {
+		data, err := readFile(f)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read config file %s", f)
+		}
+		c := v1alpha1.NewTomlConfig(map[string]interface{}{})
+		if err := c.UnmarshalTOML(data); err != nil {
+			return nil, errors.Wrapf(err, "parse config file %s", f)
+		}
+		mergeInto(conf, nil, c.MP)
+	}
+	vars := []string{VarAddr, env.Addr(), VarPodIP, env.PodIP}
+	if o.UUID != "" {
+		uuid, err := env.UUID(o.UUID)
+		if err != nil {
+			return nil, err
+		}
+		vars = append(vars, VarUUID, uuid)
+	}
+	r := strings.NewReplacer(vars...)
+	for _, s := range o.Sets {
+		conf.Set(strings.Split(s.Key, "."), r.Replace(s.Value))
+	}
+	return conf, nil
+}
+
+// mergeInto deep merges the nested map to the config
+func mergeInto(conf *v1alpha1.TomlConfig, path []string, m map[string]interface{}) {
+	for k, v := range m {
+		p := append(append([]string{}, path...), k)
+		if nested, ok := v.(map[string]interface{}); ok {
+			mergeInto(conf, p, nested)
+			continue
+		}
+		conf.Set(p, v)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2023 Matrix Origin
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package moinit
+
+import (
+	"context"
+	"flag"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+func TestOptions_Args(t *testing.T) {
+	g := NewGomegaWithT(t)
+	opts := &Options{
+		ConfigFiles: []string{"/etc/a.toml", "/etc/b.toml"},
+		Sets:        []Set{{Key: "cn.uuid", Value: VarUUID}, {Key: "cn.sql-address", Value: VarPodIP + ":6001"}},
+		UUID:        UUIDAddress,
+		WaitDNS:     true,
+		DNSTimeout:  time.Minute,
+		DNSPeriod:   2 * time.Second,
+	}
+	parsed := &Options{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	parsed.BindFlags(fs)
+	g.Expect(fs.Parse(append(opts.Args(), "--", "/mo-service"))).To(Succeed())
+	g.Expect(parsed).To(Equal(opts))
+	g.Expect(fs.Args()).To(Equal([]string{"/mo-service"}))
+}
+
+func TestEnv_UUID(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    Env
+		scheme UUIDScheme
+		want   string
+	}{{
+		name:   "log store",
+		env:    Env{PodName: "mo-log-2", Namespace: "default", HeadlessService: "mo-log-headless"},
+		scheme: UUIDLogStoreOrdinal,
+		want:   "00000000-0000-0000-0000-000000000002",
+	}, {
+		name:   "dn store",
+		env:    Env{PodName: "mo-dn-10", Namespace: "default", HeadlessService: "mo-dn-headless"},
+		scheme: UUIDDNStoreOrdinal,
+		want:   "00000000-0000-0000-0000-10000000000a",
+	}, {
+		name:   "address",
+		env:    Env{PodName: "default-cn-0", Namespace: "test", HeadlessService: "default-cn-headless"},
+		scheme: UUIDAddress,
+		want:   "64396564-3061-3238-3164-363835623561",
+	}, {
+		name:   "hostname UUID",
+		env:    Env{PodName: "default-cn-0", Namespace: "test", HeadlessService: "default-cn-headless", HostnameUUID: true},
+		scheme: UUIDDNStoreOrdinal,
+		want:   "64396564-3061-3238-3164-363835623561",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			got, err := tt.env.UUID(tt.scheme)
+			g.Expect(err).To(Succeed())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	g := NewGomegaWithT(t)
+	files := map[string]string{
+		"/etc/logservice/logservice.toml": `
+service-type = "LOG"
+
+[logservice]
+deployment-id = 1
+`,
+		"/etc/gossip/gossip.toml": `
+[logservice]
+gossip-seed-addresses = ["a:32002", "b:32002"]
+`,
+		"/etc/bootstrap/bootstrap.toml": `
+[logservice.BootstrapConfig]
+bootstrap-cluster = true
+`,
+	}
+	opts := &Options{
+		ConfigFiles: []string{"/etc/logservice/logservice.toml", "/etc/gossip/gossip.toml", "/etc/bootstrap/bootstrap.toml"},
+		UUID:        UUIDLogStoreOrdinal,
+		Sets: []Set{
+			{Key: "logservice.uuid", Value: VarUUID},
+			{Key: "logservice.raft-address", Value: VarAddr + ":32000"},
+			{Key: "logservice.gossip-address", Value: VarPodIP + ":32002"},
+		},
+	}
+	env := Env{PodName: "mo-log-1", Namespace: "default", HeadlessService: "mo-log-headless", PodIP: "10.0.0.1"}
+	conf, err := Render(opts, env, func(f string) ([]byte, error) {
+		s, ok := files[f]
+		if !ok {
+			return nil, errors.Errorf("%s not found", f)
+		}
+		return []byte(s), nil
+	})
+	g.Expect(err).To(Succeed())
+	g.Expect(conf.Get("service-type").MustString()).To(Equal("LOG"))
+	g.Expect(conf.Get("logservice", "deployment-id").MustInt()).To(Equal(int64(1)))
+	g.Expect(conf.Get("logservice", "gossip-seed-addresses").MustStringSlice()).To(Equal([]string{"a:32002", "b:32002"}))
+	g.Expect(conf.Get("logservice", "BootstrapConfig", "bootstrap-cluster").Interface()).To(Equal(true))
+	g.Expect(conf.Get("logservice", "uuid").MustString()).To(Equal("00000000-0000-0000-0000-000000000001"))
+	g.Expect(conf.Get("logservice", "raft-address").MustString()).To(Equal("mo-log-1.mo-log-headless.default.svc:32000"))
+	g.Expect(conf.Get("logservice", "gossip-address").MustString()).To(Equal("10.0.0.1:32002"))
+
+	_, err = Render(&Options{ConfigFiles: []string{"/not/exist"}}, env, func(f string) ([]byte, error) {
+		return nil, errors.New("not found")
+	})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestWaitDNS(t *testing.T) {
+	g := NewGomegaWithT(t)
+	attempts := 0
+	err := WaitDNS(context.Background(), "host", time.Second, time.Millisecond, func(ctx context.Context, host string) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("no such host")
+		}
+		return nil
+	})
+	g.Expect(err).To(Succeed())
+	g.Expect(attempts).To(Equal(3))
+
+	err = WaitDNS(context.Background(), "host", 10*time.Millisecond, time.Millisecond, func(ctx context.Context, host string) error {
+		return errors.New("no such host")
+	})
+	g.Expect(err).To(HaveOccurred())
+}
@@ -18,6 +18,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -32,9 +34,30 @@ const (
 	queryTimeout = 10 * time.Second
 )
 
+// views of MO metrics in system_metrics
+const (
+	MetricServerConnections = "server_connections"
+	MetricProcessCPUPercent = "process_cpu_percent"
+	// MetricSQLStatementTotal records the number of statements executed in each collect interval
+	MetricSQLStatementTotal = "sql_statement_total"
+)
+
+type Aggregation string
+
+const (
+	AggregationAvg Aggregation = "AVG"
+	AggregationSum Aggregation = "SUM"
+)
+
 type Client interface {
 	GetServerConnection(ctx context.Context, uid string) (int, error)
+	// GetNodeMetrics returns the values of a metric collected in the recent window aggregated by node,
+	// the rows of a node in the same collect interval are summed up before aggregated over the window.
+	// Nodes that have not reported the metric in the window are absent in the result
+	GetNodeMetrics(ctx context.Context, metric string, agg Aggregation, nodes []string, window time.Duration) (map[string]float64, error)
 	Query(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	// Close closes the connections held by the client
+	Close() error
 }
 
 type moClient struct {
@@ -67,13 +90,50 @@ LIMIT 1;
 	if err != nil {
 		return 0, err
 	}
+	defer rows.Close()
 	var v int
 	for rows.Next() {
-		if err := rows.Scan(v); err != nil {
+		if err := rows.Scan(&v); err != nil {
 			return 0, err
 		}
 	}
-	return v, nil
+	return v, rows.Err()
+}
+
+func (c *moClient) GetNodeMetrics(ctx context.Context, metric string, agg Aggregation, nodes []string, window time.Duration) (map[string]float64, error) {
+	if len(nodes) == 0 {
+		return map[string]float64{}, nil
+	}
+	args := make([]any, 0, len(nodes))
+	for _, n := range nodes {
+		args = append(args, n)
+	}
+	// a metric may be reported in multiple rows in each collect interval, e.g. one row per account,
+	// so the rows are summed up per interval before aggregated over the window.
+	// the window is bounded by the clock of MO to tolerate the clock skew between the operator and MO
+	rows, err := c.Query(ctx, fmt.Sprintf(`
+SELECT node, %s(value) FROM (
+	SELECT node, collecttime, SUM(value) AS value FROM
+	system_metrics.%s
+	WHERE collecttime >= DATE_SUB(NOW(), INTERVAL %d SECOND) AND node IN (%s)
+	GROUP BY node, collecttime
+) AS intervals
+GROUP BY node;
+`, agg, metric, int64(window.Seconds()), strings.TrimSuffix(strings.Repeat("?,", len(nodes)), ",")), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	values := map[string]float64{}
+	for rows.Next() {
+		var node string
+		var v float64
+		if err := rows.Scan(&node, &v); err != nil {
+			return nil, err
+		}
+		values[node] = v
+	}
+	return values, rows.Err()
 }
 
 func (c *moClient) Query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
@@ -90,6 +150,17 @@ func (c *moClient) Query(ctx context.Context, query string, args ...any) (*sql.R
 	return conn.QueryContext(ctx, query, args...)
 }
 
+func (c *moClient) Close() error {
+	c.Lock()
+	defer c.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
 func (c *moClient) getConnection(ctx context.Context) (*sql.DB, error) {
 	if c.conn != nil {
 		return c.conn, nil
@@ -111,6 +182,9 @@ func (c *moClient) getConnection(ctx context.Context) (*sql.DB, error) {
 	if err != nil {
 		return nil, err
 	}
+	// do not keep idle connections, which are sessions on CN and would prevent the CN from being
+	// drained or considered idle
+	db.SetMaxIdleConns(0)
 	c.conn = db
 	return c.conn, nil
 }
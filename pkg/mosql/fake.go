@@ -17,6 +17,8 @@ package mosql
 import (
 	"context"
 	"database/sql"
+	"time"
+
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -31,6 +33,14 @@ func (c *fakeClient) GetServerConnection(ctx context.Context, uid string) (int,
 	return 0, nil
 }
 
+func (c *fakeClient) GetNodeMetrics(ctx context.Context, metric string, agg Aggregation, nodes []string, window time.Duration) (map[string]float64, error) {
+	return map[string]float64{}, nil
+}
+
 func (c *fakeClient) Query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
 	return nil, nil
 }
+
+func (c *fakeClient) Close() error {
+	return nil
+}